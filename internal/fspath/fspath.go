@@ -0,0 +1,77 @@
+// Package fspath resolves OS-style paths against an fs.FS, so that boa
+// reads files from CmdT.FS the way it reads them from the OS: absolute and
+// relative paths both work, and errors mention the path as given.
+package fspath
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Clean turns name into a path in an fs.FS. The FS root stands in for both
+// "/" and the working directory, so "/etc/app.json", "etc/app.json" and
+// "./etc/app.json" are all "etc/app.json". Paths leaving the root, like
+// "../app.json", are invalid.
+func Clean(name string) (string, error) {
+	p := path.Clean(filepath.ToSlash(strings.TrimPrefix(name, filepath.VolumeName(name))))
+	if p == ".." || strings.HasPrefix(p, "../") {
+		return "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if p = strings.TrimLeft(p, "/"); p == "" {
+		p = "."
+	}
+	return p, nil
+}
+
+// ReadFile is os.ReadFile against fsys, or os.ReadFile itself if fsys is
+// nil.
+func ReadFile(fsys fs.FS, name string) ([]byte, error) {
+	if fsys == nil {
+		return os.ReadFile(name)
+	}
+	p, err := Clean(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := fs.ReadFile(fsys, p)
+	return data, withPath(err, name)
+}
+
+// Stat is os.Stat against fsys, or os.Stat itself if fsys is nil.
+func Stat(fsys fs.FS, name string) (fs.FileInfo, error) {
+	if fsys == nil {
+		return os.Stat(name)
+	}
+	p, err := Clean(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := fs.Stat(fsys, p)
+	return info, withPath(err, name)
+}
+
+// WriteFileFS is boa.WriteFileFS.
+type WriteFileFS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// WriteFile is os.WriteFile against fsys.
+func WriteFile(fsys WriteFileFS, name string, data []byte, perm fs.FileMode) error {
+	p, err := Clean(name)
+	if err != nil {
+		return err
+	}
+	return withPath(fsys.WriteFile(p, data, perm), name)
+}
+
+// withPath makes err mention name instead of the path in the FS.
+func withPath(err error, name string) error {
+	if pe, ok := err.(*fs.PathError); ok {
+		pe.Path = name
+	}
+	return err
+}
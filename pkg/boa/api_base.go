@@ -4,8 +4,11 @@
 package boa
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -13,7 +16,9 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/GiGurra/boa/internal/fspath"
 	"github.com/spf13/cobra"
 )
 
@@ -77,6 +82,10 @@ type Cmd struct {
 	PreValidateFuncCtx func(ctx *HookContext, params any, cmd *cobra.Command, args []string) error
 	// PreExecuteFuncCtx runs after validation but before command execution with access to HookContext
 	PreExecuteFuncCtx func(ctx *HookContext, params any, cmd *cobra.Command, args []string) error
+	// PostExecuteFunc runs after the run function, also when it fails, with its error
+	PostExecuteFunc func(params any, cmd *cobra.Command, args []string, runErr error) error
+	// PostExecuteFuncCtx runs after the run function, also when it fails, with access to HookContext
+	PostExecuteFuncCtx func(ctx *HookContext, params any, cmd *cobra.Command, args []string, runErr error) error
 	// ConfigUnmarshal specifies the unmarshal function for config files loaded via the configfile tag.
 	// If nil, defaults to json.Unmarshal.
 	//
@@ -92,6 +101,55 @@ type Cmd struct {
 	ConfigFormat ConfigFormat
 	// RawArgs allows injecting command line arguments instead of using os.Args
 	RawArgs []string
+	// Prompt asks for missing required parameters interactively. See
+	// CmdT.Prompt.
+	Prompt bool
+	// Prompter overrides how values are asked for. See CmdT.Prompter.
+	Prompter Prompter
+	// Confirm is asked ("<Confirm> [y/N]") before the run function. See
+	// CmdT.Confirm.
+	Confirm string
+	// ConfirmFunc builds the confirmation question from the resolved params.
+	// See CmdT.ConfirmFunc.
+	ConfirmFunc func(params any) string
+	// ConfirmIn is where the confirmation answer is read from. See
+	// CmdT.ConfirmIn.
+	ConfirmIn io.Reader
+	// ArgFiles expands "@path" arguments into the contents of the file.
+	// See CmdT.ArgFiles.
+	ArgFiles bool
+	// Example is shown in the Examples section of help. See CmdT.Example.
+	Example string
+	// HelpJSON adds a hidden --help-json flag printing Manifest as JSON.
+	// See CmdT.HelpJSON.
+	HelpJSON bool
+	// Signals cancel the run context. See CmdT.Signals.
+	Signals []os.Signal
+	// Timeout cancels the run context after this long. See CmdT.Timeout.
+	Timeout time.Duration
+	// Middleware wraps the phases of this command and its subcommands.
+	// See CmdT.Middleware.
+	Middleware []Middleware
+	// ExitCodeMapper picks Run's exit code for an error. See
+	// CmdT.ExitCodeMapper.
+	ExitCodeMapper func(err error) int
+	// CleanRunErrors makes Run print run function errors instead of
+	// panicking. See CmdT.CleanRunErrors.
+	CleanRunErrors bool
+	// ErrorRenderer prints errors for Run and Execute. See
+	// CmdT.ErrorRenderer.
+	ErrorRenderer ErrorRenderer
+	// ErrorFormatFlag adds a persistent --error-format flag. See
+	// CmdT.ErrorFormatFlag.
+	ErrorFormatFlag bool
+	// LookupEnv replaces os.LookupEnv for every env var the command reads.
+	// See CmdT.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+	// Environ lists the environment LookupEnv reads from. See CmdT.Environ.
+	Environ func() []string
+	// FS is the filesystem config files and argfiles are read from. See
+	// CmdT.FS.
+	FS fs.FS
 
 	// reloadFactory, when non-nil, allocates a fresh copy of the params
 	// struct and re-runs the full post-flag-parse pipeline (defaults →
@@ -104,7 +162,12 @@ type Cmd struct {
 	// Unexported so users can't accidentally wire it themselves — reload
 	// only makes sense when it goes through the same pipeline the command
 	// was built with.
-	reloadFactory func() (any, error)
+	//
+	// args, when non-nil, replaces the recorded RawArgs, so a reload sees
+	// the argfile-expanded args of the original run. running is the
+	// context of the command being reloaded, whose settings inherited from
+	// its parents (like LookupEnv and FS) the reload keeps.
+	reloadFactory func(args []string, running *processingContext) (any, error)
 }
 
 // HasValue checks if a parameter has a value from any source.
@@ -114,7 +177,7 @@ func HasValue(f Param) bool {
 	if f.wasSetByEnv() || f.wasSetOnCli() || f.hasDefaultValue() || f.wasSetByInject() {
 		return true
 	}
-	if pm, ok := f.(*paramMeta); ok && pm.setByConfig {
+	if pm, ok := f.(*paramMeta); ok && (pm.setByConfig || pm.setByPrompt) {
 		return true
 	}
 	return false
@@ -165,7 +228,7 @@ var (
 	// Skips setting if the character would be 'h' (reserved for help) or
 	// if another parameter already uses that character.
 	ParamEnricherShort ParamEnricher = func(alreadyProcessed []Param, param Param, paramFieldName string) error {
-		if param.GetShort() == "" && param.GetName() != "" {
+		if param.GetShort() == "" && param.GetName() != "" && !param.IsRestArgs() {
 			wantShort := string(param.GetName()[0])
 			if wantShort == "h" {
 				return nil
@@ -185,9 +248,9 @@ var (
 
 	// ParamEnricherEnv sets an environment variable name for a parameter
 	// based on its flag name. Converts from kebab-case to UPPER_SNAKE_CASE.
-	// Only applies to non-positional parameters.
+	// Only applies to non-positional, non-rest parameters.
 	ParamEnricherEnv ParamEnricher = func(alreadyProcessed []Param, param Param, paramFieldName string) error {
-		if param.GetEnv() == "" && param.GetName() != "" && !param.isPositional() {
+		if param.GetEnv() == "" && param.GetName() != "" && !param.isPositional() && !param.IsRestArgs() {
 			param.SetEnv(kebabCaseToUpperSnakeCase(param.GetName()))
 		}
 		return nil
@@ -253,6 +316,7 @@ func (b Cmd) RunArgs(rawArgs []string) {
 // Validate validates parameter values without executing the command's RunFunc.
 // This is used mostly in tests.
 func (b Cmd) Validate() error {
+	b = b.withoutConfirm()
 	b.RunFunc = func(cmd *cobra.Command, args []string) {}
 	b.UseCobraErrLog = false
 	var err error
@@ -296,6 +360,24 @@ func (b Cmd) RunArgsE(rawArgs []string) error {
 	return b.RunE()
 }
 
+// RunContextE is like RunE, with ctx as the parent of the run context (see
+// HookContext.Context): cancelling ctx cancels the command.
+func (b Cmd) RunContextE(ctx context.Context) error {
+	cmd, err := b.ToCobraE()
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return cmd.ExecuteContext(ctx)
+}
+
+// RunArgsContextE is like RunContextE with the provided arguments.
+func (b Cmd) RunArgsContextE(ctx context.Context, rawArgs []string) error {
+	b.RawArgs = rawArgs
+	return b.RunContextE(ctx)
+}
+
 // Default creates a pointer to a value of a supported type.
 // This is used to define default values for parameters programmatically
 // via HookContext.GetParam().SetDefault().
@@ -375,6 +457,31 @@ func newHookContext(pctx *processingContext) *HookContext {
 	return &HookContext{ctx: pctx}
 }
 
+// Context returns the command's context. While the command runs (from
+// PreValidate until the run function returns) it is cancelled when the
+// process receives one of Cmd.Signals, with context.Cause reporting
+// ErrInterrupted, when Cmd.Timeout expires (cause ErrTimeout), or when the
+// parent passed to ExecuteContext / RunContextE is done. A run function
+// returning the context's error after a signal or timeout fails with an
+// error matching ErrInterrupted or ErrTimeout. Outside a run it is the parent, or
+// context.Background().
+func (c *HookContext) Context() context.Context {
+	if c.ctx == nil || c.ctx.Context == nil {
+		return context.Background()
+	}
+	return c.ctx.Context
+}
+
+// LookupEnv reads an environment variable the way the command does: through
+// Cmd.LookupEnv (its own or an ancestor's), the one set with WithLookupEnv,
+// or os.LookupEnv.
+func (c *HookContext) LookupEnv(key string) (string, bool) {
+	if c.ctx == nil {
+		return lookupEnvDefault(key)
+	}
+	return c.ctx.lookupEnv(key)
+}
+
 // GetParam returns the Param for any field pointer.
 // This provides a unified API for accessing parameter configuration.
 //
@@ -565,7 +672,7 @@ func (c *HookContext) reloadAny() (any, error) {
 	if c.ctx.reloadFactory == nil {
 		return nil, fmt.Errorf("boa: HookContext.Reload: no reload factory registered — this HookContext came from a Cmd that was constructed without CmdT[T].ToCmd (the generic wrapper is what installs the factory)")
 	}
-	return c.ctx.reloadFactory()
+	return c.ctx.reloadFactory(c.ctx.expandedArgs, c.ctx)
 }
 
 // Reload re-runs the full post-flag-parse pipeline on a freshly
@@ -720,7 +827,8 @@ func resolveDumpFieldName(sf reflect.StructField, tagName string) (name string,
 
 // DumpFile is the file-writing counterpart to DumpBytes. The marshaler is
 // resolved from filePath's extension; the file is written with mode 0644
-// and overwrites any existing file.
+// and overwrites any existing file. With CmdT.FS set, the file is written
+// there, which fails unless the FS implements WriteFileFS.
 func (c *HookContext) DumpFile(filePath string, marshalFunc func(v any) ([]byte, error)) error {
 	if filePath == "" {
 		return NewUserInputError(fmt.Errorf("HookContext.DumpFile: filePath must not be empty"))
@@ -729,10 +837,15 @@ func (c *HookContext) DumpFile(filePath string, marshalFunc func(v any) ([]byte,
 	if err != nil {
 		return fmt.Errorf("failed to marshal config for %s: %w", filePath, err)
 	}
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file %s: %w", filePath, err)
+	var fsys WriteFileFS
+	if f := c.ctx.fileSystem(); f != nil {
+		w, ok := f.(WriteFileFS)
+		if !ok {
+			return fmt.Errorf("failed to write config file %s: the command's FS doesn't implement WriteFileFS", filePath)
+		}
+		fsys = w
 	}
-	return nil
+	return writeConfigFile(fsys, filePath, data)
 }
 
 // buildSetValueTree walks the root parameters struct and returns a nested
@@ -780,7 +893,7 @@ func shouldEmitInDump(f Param, v reflect.Value) bool {
 	if f.wasSetOnCli() || f.wasSetByEnv() || f.wasSetByInject() {
 		return true
 	}
-	if pm, ok := f.(*paramMeta); ok && pm.setByConfig {
+	if pm, ok := f.(*paramMeta); ok && (pm.setByConfig || pm.setByPrompt) {
 		return true
 	}
 	if !f.hasDefaultValue() {
@@ -832,7 +945,7 @@ func buildSetValueMapNode(v reflect.Value, ctx *processingContext, pathIdx []int
 				if skip {
 					continue
 				}
-				out[name] = fv.Interface()
+				out[name] = dumpValue(fv)
 			}
 			continue
 		}
@@ -906,11 +1019,20 @@ func SubCmds(cmds ...CmdIfc) []*cobra.Command {
 // RegisterConfigFormatFull), and json.Unmarshal as the final fallback when no
 // registration matches.
 func LoadConfigFile[T any](filePath string, target *T, unmarshalFunc func([]byte, any) error) error {
+	return LoadConfigFileFS(nil, filePath, target, unmarshalFunc)
+}
+
+// LoadConfigFileFS is LoadConfigFile reading from fsys, e.g. an embed.FS,
+// a fstest.MapFS in tests or an overlay FS. filePath is resolved against
+// the root of fsys, which stands in for both "/" and the working
+// directory, so "/etc/app.json" and "etc/app.json" are the same file.
+// Errors mention filePath as given. A nil fsys is the OS filesystem.
+func LoadConfigFileFS[T any](fsys fs.FS, filePath string, target *T, unmarshalFunc func([]byte, any) error) error {
 	override := ConfigFormat{}
 	if unmarshalFunc != nil {
 		override.Unmarshal = unmarshalFunc
 	}
-	_, _, err := loadConfigFileInto(filePath, target, override)
+	_, _, err := loadConfigFileInto(fsys, filePath, target, override)
 	return err
 }
 
@@ -938,11 +1060,17 @@ func LoadConfigFile[T any](filePath string, target *T, unmarshalFunc func([]byte
 // registered .yaml base with a .json overlay) as long as both formats
 // have registered unmarshalers.
 func LoadConfigFiles[T any](paths []string, target *T, unmarshalFunc func([]byte, any) error) error {
+	return LoadConfigFilesFS(nil, paths, target, unmarshalFunc)
+}
+
+// LoadConfigFilesFS is LoadConfigFiles reading from fsys, with paths
+// resolved as by LoadConfigFileFS. A nil fsys is the OS filesystem.
+func LoadConfigFilesFS[T any](fsys fs.FS, paths []string, target *T, unmarshalFunc func([]byte, any) error) error {
 	for _, p := range paths {
 		if p == "" {
 			continue
 		}
-		if err := LoadConfigFile(p, target, unmarshalFunc); err != nil {
+		if err := LoadConfigFileFS(fsys, p, target, unmarshalFunc); err != nil {
 			return err
 		}
 	}
@@ -1024,6 +1152,23 @@ func DumpConfigBytes[T any](v *T, ext string, marshalFunc func(v any) ([]byte, e
 // LoadConfigFile, there is no "empty is a no-op" shortcut, because silently
 // dropping a dump request is more surprising than a missing load.
 func DumpConfigFile[T any](filePath string, v *T, marshalFunc func(v any) ([]byte, error)) error {
+	return DumpConfigFileFS(nil, filePath, v, marshalFunc)
+}
+
+// WriteFileFS is a filesystem that config files can be dumped to, see
+// DumpConfigFileFS. fs.FS itself is read-only.
+type WriteFileFS interface {
+	fs.FS
+	// WriteFile is os.WriteFile for a path in the FS, in the form
+	// fs.ValidPath accepts.
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// DumpConfigFileFS is DumpConfigFile writing to fsys, with filePath
+// resolved as by LoadConfigFileFS, so a dumped file loads back from the
+// same path. Errors mention filePath as given. A nil fsys is the OS
+// filesystem.
+func DumpConfigFileFS[T any](fsys WriteFileFS, filePath string, v *T, marshalFunc func(v any) ([]byte, error)) error {
 	if filePath == "" {
 		return NewUserInputError(fmt.Errorf("DumpConfigFile: filePath must not be empty"))
 	}
@@ -1031,7 +1176,19 @@ func DumpConfigFile[T any](filePath string, v *T, marshalFunc func(v any) ([]byt
 	if err != nil {
 		return fmt.Errorf("failed to marshal config for %s: %w", filePath, err)
 	}
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
+	return writeConfigFile(fsys, filePath, data)
+}
+
+// writeConfigFile writes a dumped config file to fsys, or the OS
+// filesystem if it is nil.
+func writeConfigFile(fsys WriteFileFS, filePath string, data []byte) error {
+	var err error
+	if fsys == nil {
+		err = os.WriteFile(filePath, data, 0644)
+	} else {
+		err = fspath.WriteFile(fsys, filePath, data, 0644)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to write config file %s: %w", filePath, err)
 	}
 	return nil
@@ -1295,12 +1452,13 @@ func ConfigFormatExtensions() []string {
 //  3. JSON fallback (unmarshal + key-tree)
 //
 // Returns the raw bytes and the effective ConfigFormat so callers can reuse
-// its KeyTree for key-presence detection.
-func loadConfigFileInto(filePath string, target any, override ConfigFormat) ([]byte, ConfigFormat, error) {
+// its KeyTree for key-presence detection. The file is read from fsys, or
+// the OS filesystem if it is nil.
+func loadConfigFileInto(fsys fs.FS, filePath string, target any, override ConfigFormat) ([]byte, ConfigFormat, error) {
 	if filePath == "" {
 		return nil, ConfigFormat{}, nil
 	}
-	fileContents, err := os.ReadFile(filePath)
+	fileContents, err := fspath.ReadFile(fsys, filePath)
 	if err != nil {
 		return nil, ConfigFormat{}, fmt.Errorf("failed to read config file %s: %w", filePath, err)
 	}
@@ -1316,7 +1474,7 @@ func loadConfigFileInto(filePath string, target any, override ConfigFormat) ([]b
 // loadConfigFileInto and runs the unmarshaler against the supplied bytes.
 func loadConfigBytesInto(data []byte, ext string, target any, override ConfigFormat) (ConfigFormat, error) {
 	effective := resolveConfigFormatByExt(ext, override)
-	if err := effective.Unmarshal(data, target); err != nil {
+	if err := unmarshalConfig(effective.Unmarshal, data, target); err != nil {
 		return effective, err
 	}
 	return effective, nil
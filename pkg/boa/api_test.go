@@ -654,7 +654,7 @@ func TestErrorHandlingTable(t *testing.T) {
 	type expectedBehavior int
 	const (
 		expectPanic expectedBehavior = iota
-		expectExit1
+		expectExit
 		expectReturn
 	)
 
@@ -673,8 +673,8 @@ func TestErrorHandlingTable(t *testing.T) {
 		errorMatch string // substring to match in error (for Return behavior)
 	}{
 		// Run() behavior
-		{"Run/UserInput", "Run", "UserInput", expectExit1, outputExpectation{hasErrorPrefix: true, hasUsage: true}, ""},
-		{"Run/Hook", "Run", "Hook", expectExit1, outputExpectation{hasErrorPrefix: true, hasUsage: true}, ""},
+		{"Run/UserInput", "Run", "UserInput", expectExit, outputExpectation{hasErrorPrefix: true, hasUsage: true}, ""},
+		{"Run/Hook", "Run", "Hook", expectExit, outputExpectation{hasErrorPrefix: true, hasUsage: true}, ""},
 		{"Run/Runtime", "Run", "Runtime", expectPanic, outputExpectation{}, "runtime error"},
 
 		// RunE() behavior
@@ -683,8 +683,8 @@ func TestErrorHandlingTable(t *testing.T) {
 		{"RunE/Runtime", "RunE", "Runtime", expectReturn, outputExpectation{noOutput: true}, "runtime error"},
 
 		// RunArgs() behavior
-		{"RunArgs/UserInput", "RunArgs", "UserInput", expectExit1, outputExpectation{hasErrorPrefix: true, hasUsage: true}, ""},
-		{"RunArgs/Hook", "RunArgs", "Hook", expectExit1, outputExpectation{hasErrorPrefix: true, hasUsage: true}, ""},
+		{"RunArgs/UserInput", "RunArgs", "UserInput", expectExit, outputExpectation{hasErrorPrefix: true, hasUsage: true}, ""},
+		{"RunArgs/Hook", "RunArgs", "Hook", expectExit, outputExpectation{hasErrorPrefix: true, hasUsage: true}, ""},
 		{"RunArgs/Runtime", "RunArgs", "Runtime", expectPanic, outputExpectation{}, "runtime error"},
 
 		// RunArgsE() behavior
@@ -789,15 +789,18 @@ func TestErrorHandlingTable(t *testing.T) {
 				if panicValue == nil {
 					t.Error("Expected panic but none occurred")
 				}
-			case expectExit1:
+			case expectExit:
 				if panicValue != nil {
 					t.Errorf("Expected clean exit (no panic), but got panic: %v", panicValue)
 				}
 				if !exitCalled {
 					t.Error("Expected osExit to be called")
 				}
-				if exitCode != 1 {
-					t.Errorf("Expected exit code 1, got %d", exitCode)
+				// Missing required params fail validation; the hook's
+				// plain user input error is a usage error
+				wantCode := map[string]int{"UserInput": ExitValidation, "Hook": ExitUsage}[tc.errorType]
+				if exitCode != wantCode {
+					t.Errorf("Expected exit code %d, got %d", wantCode, exitCode)
 				}
 			case expectReturn:
 				if returnedErr == nil {
@@ -1,8 +1,13 @@
 package boa
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
 	"reflect"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -67,6 +72,12 @@ type CmdT[Struct any] struct {
 	PreValidateFuncCtx func(ctx *HookContext, params *Struct, cmd *cobra.Command, args []string) error
 	// PreExecuteFuncCtx runs after validation but before execution with HookContext
 	PreExecuteFuncCtx func(ctx *HookContext, params *Struct, cmd *cobra.Command, args []string) error
+	// PostExecuteFunc runs after the run function, also when it returns an
+	// error or panics, and receives that error (nil on success). Its error
+	// is joined with the run function's.
+	PostExecuteFunc func(params *Struct, cmd *cobra.Command, args []string, runErr error) error
+	// PostExecuteFuncCtx is like PostExecuteFunc with access to HookContext
+	PostExecuteFuncCtx func(ctx *HookContext, params *Struct, cmd *cobra.Command, args []string, runErr error) error
 	// UseCobraErrLog determines whether to use Cobra's error logging
 	UseCobraErrLog bool
 	// SortFlags determines whether to sort command flags alphabetically
@@ -89,6 +100,97 @@ type CmdT[Struct any] struct {
 	ConfigFormat ConfigFormat
 	// RawArgs allows injecting command line arguments instead of using os.Args
 	RawArgs []string
+	// Prompt asks for missing required parameters interactively instead of
+	// failing with "missing required param". Only happens when stdin is a
+	// terminal, unless Prompter is set. Params with a `prompt` tag are asked
+	// for even when Prompt is false.
+	Prompt bool
+	// Prompter overrides how values are asked for. If nil, questions go to
+	// stderr and answers are read from stdin (see NewLinePrompter).
+	Prompter Prompter
+	// Confirm, when set, is asked as "<Confirm> [y/N]" after PreExecute and
+	// before the run function; anything but y/yes aborts with
+	// ErrNotConfirmed. Setting it adds a --yes/-y flag (and YES env var,
+	// subject to env prefixes) that skips the question. Without --yes, a
+	// command that isn't attached to a terminal refuses to run.
+	Confirm string
+	// ConfirmFunc is like Confirm but builds the question from the resolved
+	// params, e.g. "Delete 3 clusters in prod?". Returning "" skips the
+	// confirmation for this run.
+	ConfirmFunc func(params *Struct) string
+	// ConfirmIn is where the confirmation answer is read from. If nil,
+	// stdin is used. Set it in tests to script the answer.
+	ConfirmIn io.Reader
+	// ArgFiles expands every "@path" argument into the arguments in that
+	// file before parsing, e.g. `mytool @ci.args`. Files use shell-like
+	// quoting and # comments, and may include other argfiles (relative to
+	// their own directory). Use "@@x" for a literal "@x". Set it on the
+	// root command; it applies to RawArgs or os.Args.
+	ArgFiles bool
+	// Example is shown in the Examples section of help, followed by an
+	// invocation built from the params' `example` tags. Like cobra's
+	// Example, lines are printed as is, so indent them by two spaces.
+	Example string
+	// HelpJSON adds a hidden, persistent --help-json flag that prints the
+	// command tree (see Manifest) as JSON and exits, for tools and agents
+	// that invoke the CLI. Set it on the root command.
+	HelpJSON bool
+	// Signals cancel the run context (see HookContext.Context) when the
+	// process receives one of them while the command runs; a second signal
+	// exits with code 130 without waiting. nil means os.Interrupt and
+	// SIGTERM for RunFuncCtx / RunFuncCtxE, and no handling for other run
+	// functions, which can't watch the context; an empty, non-nil slice
+	// disables signal handling.
+	Signals []os.Signal
+	// Timeout, when positive, cancels the run context this long after
+	// the command starts running.
+	Timeout time.Duration
+	// Middleware wraps the validate and run phases of this command and of
+	// every boa subcommand below it, the first element outermost. Set it on
+	// the root command for timing, audit logs or tracing across the tree.
+	Middleware []Middleware
+	// ExitCodeMapper picks Run's exit code for an error, e.g. to map a
+	// library's errors. Return 0 to fall back to ExitCode. It applies to
+	// subcommands too; the nearest mapper returning non-zero wins, and an
+	// ExitError's own Code always does.
+	ExitCodeMapper func(err error) int
+	// CleanRunErrors makes Run print errors from RunFuncE / RunFuncCtxE
+	// as "Error: ..." (without usage) and exit with their exit code,
+	// instead of panicking. It applies to subcommands too.
+	CleanRunErrors bool
+	// ErrorRenderer prints the error when Run or Execute fails, instead of
+	// the usage followed by the error. It applies to subcommands too, the
+	// nearest one winning; see HintErrorRenderer, JSONErrorRenderer and
+	// AutoErrorRenderer, and SetDefaultErrorRenderer to change the default.
+	ErrorRenderer ErrorRenderer
+	// ErrorFormatFlag adds a persistent --error-format flag taking "text"
+	// or "json"; json prints errors with JSONErrorRenderer. Set it on the
+	// root command. Like any flag, it only counts if it comes before a
+	// flag that fails to parse.
+	ErrorFormatFlag bool
+	// LookupEnv replaces os.LookupEnv for every env var the command reads:
+	// env tags, the Confirm env var, the expand-home and expand-env
+	// transforms, NO_COLOR and TERM for error colors, and boaviper's HOME.
+	// Subcommands without their own use it too while running. Reload reads
+	// through it as well. nil falls back to WithLookupEnv, then
+	// os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+	// Environ lists the environment LookupEnv reads from, in os.Environ's
+	// "key=value" form. It is needed for the per-entry vars of map-of-struct
+	// params (APP_DBS_<NAME>_HOST), which are found by listing rather than by
+	// name: reading them with a LookupEnv but no Environ is an error.
+	// Inherited and used by Reload like LookupEnv. nil falls back to
+	// WithEnviron, then os.Environ.
+	Environ func() []string
+	// FS, when set, is the filesystem config files and argfiles are read
+	// from instead of the OS's, e.g. an embed.FS or a fstest.MapFS. Paths
+	// are resolved against its root, which stands in for both "/" and the
+	// working directory: "/etc/app.json" and "etc/app.json" are the same
+	// file. Errors and WatchedConfigFiles report paths as given.
+	// Subcommands without their own FS use it too while running, and
+	// boaviper.AutoConfig searches it. HookContext.DumpFile writes to it
+	// if it implements WriteFileFS.
+	FS fs.FS
 }
 
 // ToCmd converts a type-safe CmdT to a non-generic Cmd.
@@ -176,6 +278,20 @@ func (b CmdT[Struct]) ToCmd() Cmd {
 		}
 	}
 
+	var postExecuteFunc func(params any, cmd *cobra.Command, args []string, runErr error) error
+	if b.PostExecuteFunc != nil {
+		postExecuteFunc = func(params any, cmd *cobra.Command, args []string, runErr error) error {
+			return b.PostExecuteFunc(params.(*Struct), cmd, args, runErr)
+		}
+	}
+
+	var postExecuteFuncCtx func(ctx *HookContext, params any, cmd *cobra.Command, args []string, runErr error) error
+	if b.PostExecuteFuncCtx != nil {
+		postExecuteFuncCtx = func(ctx *HookContext, params any, cmd *cobra.Command, args []string, runErr error) error {
+			return b.PostExecuteFuncCtx(ctx, params.(*Struct), cmd, args, runErr)
+		}
+	}
+
 	var runFuncCtx func(ctx *HookContext, cmd *cobra.Command, args []string)
 	if b.RunFuncCtx != nil {
 		runFuncCtx = func(ctx *HookContext, cmd *cobra.Command, args []string) {
@@ -197,6 +313,13 @@ func (b CmdT[Struct]) ToCmd() Cmd {
 		}
 	}
 
+	var confirmFunc func(params any) string
+	if b.ConfirmFunc != nil {
+		confirmFunc = func(params any) string {
+			return b.ConfirmFunc(params.(*Struct))
+		}
+	}
+
 	// Due to golang nil upcast behavior
 	var params any
 	if b.Params != nil {
@@ -212,7 +335,7 @@ func (b CmdT[Struct]) ToCmd() Cmd {
 	// boa's value-sourcing + validation) when the command has nothing to
 	// run. We want the pipeline to fire but we don't want the user's real
 	// action, so we substitute the quietest possible runner.
-	reloadFactory := func() (any, error) {
+	reloadFactory := func(args []string, running *processingContext) (any, error) {
 		bCopy := b
 		fresh := new(Struct)
 		bCopy.Params = fresh
@@ -232,9 +355,43 @@ func (b CmdT[Struct]) ToCmd() Cmd {
 		// value-sourcing / derivation and often load additional state.
 		bCopy.PreExecuteFunc = nil
 		bCopy.PreExecuteFuncCtx = nil
+		bCopy.PostExecuteFunc = nil
+		bCopy.PostExecuteFuncCtx = nil
+
+		// A reload runs nothing, so there is nothing to confirm. The
+		// --yes flag stays registered so the same args still parse.
+		if bCopy.Confirm != "" || bCopy.ConfirmFunc != nil {
+			bCopy.Confirm = ""
+			bCopy.ConfirmFunc = func(*Struct) string { return "" }
+		}
+
+		// A reload is never interactive: missing values fail validation
+		// instead of prompting again.
+		bCopy.Prompt = false
+		bCopy.Prompter = PromptFunc(func(PromptRequest) (string, error) {
+			return "", errSkipPrompt
+		})
+
+		// A reload happens inside the running command, which already
+		// handles signals, its own timeout and its middleware.
+		bCopy.Signals = []os.Signal{}
+		bCopy.Timeout = 0
+		bCopy.Middleware = nil
+
+		// The copy runs without the parents the command is attached to,
+		// so it gets what it may have inherited from them.
+		bCopy.LookupEnv = running.lookupEnvFn()
+		bCopy.Environ = running.environFn()
+		bCopy.FS = running.fileSystem()
 
 		bCopy.RawArgs = b.RawArgs
-		if err := bCopy.RunArgsE(b.RawArgs); err != nil {
+		if args != nil {
+			// Replay the argfile-expanded args rather than re-reading the
+			// files, so the reload parses what the original run parsed.
+			bCopy.RawArgs = args
+			bCopy.ArgFiles = false
+		}
+		if err := bCopy.RunArgsE(bCopy.RawArgs); err != nil {
 			return nil, err
 		}
 		return fresh, nil
@@ -268,9 +425,29 @@ func (b CmdT[Struct]) ToCmd() Cmd {
 		PostCreateFuncCtx:  postCreateFuncCtx,
 		PreValidateFuncCtx: preValidateFuncCtx,
 		PreExecuteFuncCtx:  preExecuteFuncCtx,
+		PostExecuteFunc:    postExecuteFunc,
+		PostExecuteFuncCtx: postExecuteFuncCtx,
 		ConfigUnmarshal:    b.ConfigUnmarshal,
 		ConfigFormat:       b.ConfigFormat,
 		RawArgs:            b.RawArgs,
+		Prompt:             b.Prompt,
+		Prompter:           b.Prompter,
+		Confirm:            b.Confirm,
+		ConfirmFunc:        confirmFunc,
+		ConfirmIn:          b.ConfirmIn,
+		ArgFiles:           b.ArgFiles,
+		HelpJSON:           b.HelpJSON,
+		Signals:            b.Signals,
+		Timeout:            b.Timeout,
+		Middleware:         b.Middleware,
+		ExitCodeMapper:     b.ExitCodeMapper,
+		CleanRunErrors:     b.CleanRunErrors,
+		ErrorRenderer:      b.ErrorRenderer,
+		ErrorFormatFlag:    b.ErrorFormatFlag,
+		LookupEnv:          b.LookupEnv,
+		Environ:            b.Environ,
+		FS:                 b.FS,
+		Example:            b.Example,
 		reloadFactory:      reloadFactory,
 	}
 }
@@ -311,3 +488,14 @@ func (b CmdT[Struct]) RunArgsE(rawArgs []string) error {
 	b.RawArgs = rawArgs
 	return b.RunE()
 }
+
+// RunContextE is like RunE, with ctx as the parent of the run context.
+func (b CmdT[Struct]) RunContextE(ctx context.Context) error {
+	return b.ToCmd().RunContextE(ctx)
+}
+
+// RunArgsContextE is like RunContextE with the provided arguments.
+func (b CmdT[Struct]) RunArgsContextE(ctx context.Context, rawArgs []string) error {
+	b.RawArgs = rawArgs
+	return b.RunContextE(ctx)
+}
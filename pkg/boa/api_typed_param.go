@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"reflect"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -104,6 +105,11 @@ type ParamT[T any] interface {
 	// rather than a named flag. Cannot be combined with SetNoFlag(true).
 	SetPositional(positional bool)
 
+	// SetRestArgs toggles whether this []string parameter receives every
+	// argument after the `--` terminator. Mirrors `boa:"rest"`, including
+	// making the parameter optional.
+	SetRestArgs(rest bool)
+
 	// SetMinT / SetMaxT set a typed numeric bound. Works on numeric fields
 	// (signed int, unsigned int, float). Panics on non-numeric T — use
 	// SetMinLen / SetMaxLen for string / slice / map fields instead. The
@@ -127,6 +133,38 @@ type ParamT[T any] interface {
 	// an empty string to clear the pattern. Mirrors the `pattern:"..."` tag.
 	// Panics if called on a non-string field.
 	SetPattern(pattern string)
+
+	// SetTimeLayout sets the time.Parse layout for a time.Time or
+	// []time.Time field, used for parsing and for the default in help.
+	// Mirrors the `layout:"..."` tag. Panics on other field types.
+	SetTimeLayout(layout string)
+
+	// SetTimeZone sets the location for times written without an offset,
+	// and for rendering. Mirrors the `tz:"..."` tag. Panics on other field
+	// types.
+	SetTimeZone(loc *time.Location)
+
+	// SetTransforms sets the registered transforms (e.g. "trim", "lower")
+	// run over a string or []string value before validation. Mirrors the
+	// `transform:"..."` tag. Panics on an unknown name or a non-string field.
+	SetTransforms(names []string)
+
+	// SetPrompt sets the question asked when this parameter is missing and
+	// prompting is on. A non-empty prompt opts the parameter into prompting
+	// even without CmdT.Prompt. Mirrors the `prompt:"..."` tag.
+	SetPrompt(question string)
+
+	// SetSecret makes prompts for this parameter read input without echo.
+	// Mirrors the `secret:"true"` tag.
+	SetSecret(secret bool)
+
+	// SetExample sets a sample value used in the example invocation shown
+	// in help. Mirrors the `example:"..."` tag.
+	SetExample(sample string)
+
+	// SetCompletion selects shell completion for the value: "file",
+	// "file:*.yaml,*.json", "dir" or "none". Mirrors the `complete:"..."` tag.
+	SetCompletion(spec string)
 }
 
 // GetParamT returns a typed ParamT[T] view for the given field pointer.
@@ -302,8 +340,13 @@ func (w *ParamTView[T]) SetPositional(positional bool) {
 	w.param.SetPositional(positional)
 }
 
-// SetMinT sets a typed numeric lower bound. Panics if T is not numeric —
-// use SetMinLen for string / slice / map fields.
+// SetRestArgs toggles rest-args (post-`--`) mode.
+func (w *ParamTView[T]) SetRestArgs(rest bool) {
+	w.param.SetRestArgs(rest)
+}
+
+// SetMinT sets a typed numeric lower bound. Panics if T is not numeric
+// (big.Int / big.Float / time.Time count) — use SetMinLen for string / slice / map fields.
 func (w *ParamTView[T]) SetMinT(min T) {
 	assertNumericT[T]("SetMinT")
 	w.param.SetMin(min)
@@ -342,6 +385,10 @@ func (w *ParamTView[T]) ClearMax() {
 // assertNumericT panics if T is not a numeric kind. Used to guard SetMinT /
 // SetMaxT at runtime since Go methods can't carry their own type constraints.
 func assertNumericT[T any](method string) {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); boundKindOf(t) == bigBound || boundKindOf(t) == timeBound ||
+		(t.Kind() == reflect.Pointer && (boundKindOf(t.Elem()) == bigBound || boundKindOf(t.Elem()) == timeBound)) {
+		return // big.Int / big.Float / time.Time, by value or pointer
+	}
 	var zero T
 	k := reflect.TypeOf(zero).Kind()
 	switch k {
@@ -368,3 +415,38 @@ func assertLengthT[T any](method string) {
 func (w *ParamTView[T]) SetPattern(pattern string) {
 	w.param.SetPattern(pattern)
 }
+
+// SetTimeLayout sets the time layout (empty string clears).
+func (w *ParamTView[T]) SetTimeLayout(layout string) {
+	w.param.SetTimeLayout(layout)
+}
+
+// SetTimeZone sets the time zone (nil clears).
+func (w *ParamTView[T]) SetTimeZone(loc *time.Location) {
+	w.param.SetTimeZone(loc)
+}
+
+// SetTransforms sets the value transforms (nil clears).
+func (w *ParamTView[T]) SetTransforms(names []string) {
+	w.param.SetTransforms(names)
+}
+
+// SetPrompt sets the prompt question (empty string clears).
+func (w *ParamTView[T]) SetPrompt(question string) {
+	w.param.SetPrompt(question)
+}
+
+// SetSecret toggles echo-free prompting.
+func (w *ParamTView[T]) SetSecret(secret bool) {
+	w.param.SetSecret(secret)
+}
+
+// SetExample sets the help example value (empty string clears).
+func (w *ParamTView[T]) SetExample(sample string) {
+	w.param.SetExample(sample)
+}
+
+// SetCompletion sets the shell completion spec (empty string clears).
+func (w *ParamTView[T]) SetCompletion(spec string) {
+	w.param.SetCompletion(spec)
+}
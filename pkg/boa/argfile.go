@@ -0,0 +1,166 @@
+package boa
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/GiGurra/boa/internal/fspath"
+)
+
+// expandArgFiles replaces every "@path" argument with the arguments read
+// from that file (see CmdT.ArgFiles). "@@x" becomes the literal "@x", a
+// lone "@" is kept as is, and nothing after "--" is expanded. Argfiles are
+// read from fsys, or the OS filesystem if it is nil.
+func expandArgFiles(fsys fs.FS, args []string) ([]string, error) {
+	e := &argFileExpander{fsys: fsys, out: make([]string, 0, len(args))}
+	if err := e.expand(args, ""); err != nil {
+		return nil, err
+	}
+	return e.out, nil
+}
+
+type argFileExpander struct {
+	fsys    fs.FS
+	out     []string
+	stack   []string // absolute paths of the argfiles being expanded
+	literal bool     // set once "--" has been seen
+}
+
+// expand appends args to e.out, expanding argfiles. Relative argfile paths
+// are resolved against dir, the directory of the including argfile, or
+// the working directory at the top level.
+func (e *argFileExpander) expand(args []string, dir string) error {
+	for _, arg := range args {
+		switch {
+		case e.literal || len(arg) < 2 || arg[0] != '@':
+			e.out = append(e.out, arg)
+			if arg == "--" {
+				e.literal = true
+			}
+		case arg[1] == '@':
+			e.out = append(e.out, arg[1:])
+		default:
+			if err := e.expandFile(arg[1:], dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *argFileExpander) expandFile(path, dir string) error {
+	if dir != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	abs, err := e.abs(path)
+	if err != nil {
+		return fmt.Errorf("argfile %s: %w", path, err)
+	}
+	for i, seen := range e.stack {
+		if seen == abs {
+			chain := append(append([]string{}, e.stack[i:]...), abs)
+			return fmt.Errorf("argfile cycle: %s", strings.Join(chain, " -> "))
+		}
+	}
+
+	data, err := fspath.ReadFile(e.fsys, path)
+	if err != nil {
+		return fmt.Errorf("argfile %s: %w", path, err)
+	}
+	args, err := splitArgFile(string(data))
+	if err != nil {
+		return fmt.Errorf("argfile %s: %w", path, err)
+	}
+
+	e.stack = append(e.stack, abs)
+	defer func() { e.stack = e.stack[:len(e.stack)-1] }()
+	return e.expand(args, filepath.Dir(abs))
+}
+
+// abs is filepath.Abs, with an FS's root as the working directory.
+func (e *argFileExpander) abs(path string) (string, error) {
+	if e.fsys == nil {
+		return filepath.Abs(path)
+	}
+	p, err := fspath.Clean(path)
+	if err != nil {
+		return "", err
+	}
+	if p == "." {
+		p = ""
+	}
+	return filepath.FromSlash("/" + p), nil
+}
+
+// splitArgFile splits argfile contents into arguments with shell-like
+// rules: whitespace separates arguments, single quotes are literal, double
+// quotes allow \" and \\ escapes, a backslash outside quotes escapes the
+// next character (a backslash-newline joins lines), and a # at the start
+// of an argument comments out the rest of the line.
+func splitArgFile(s string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inArg   bool // cur holds an argument, possibly empty ('')
+		line    = 1
+		runes   = []rune(s)
+		flush   = func() { args = append(args, cur.String()); cur.Reset(); inArg = false }
+		isSpace = func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' || r == '\r' }
+	)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case isSpace(r):
+			if inArg {
+				flush()
+			}
+		case r == '#' && !inArg:
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\n' {
+					line++
+					continue
+				}
+				cur.WriteRune(runes[i])
+				inArg = true
+			}
+		case r == '\'' || r == '"':
+			start := line
+			inArg = true
+			for i++; ; i++ {
+				if i >= len(runes) {
+					return nil, fmt.Errorf("line %d: unterminated %c quote", start, r)
+				}
+				c := runes[i]
+				if c == r {
+					break
+				}
+				if c == '\n' {
+					line++
+				}
+				if c == '\\' && r == '"' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+					i++
+					c = runes[i]
+				}
+				cur.WriteRune(c)
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+		if i < len(runes) && runes[i] == '\n' {
+			line++
+		}
+	}
+	if inArg {
+		flush()
+	}
+	return args, nil
+}
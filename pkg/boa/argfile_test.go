@@ -0,0 +1,195 @@
+package boa
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestSplitArgFile(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"whitespace", "--a 1\n\t--b  2\r\n", []string{"--a", "1", "--b", "2"}},
+		{"comments", "# header\n--a 1 # trailing\n#--b 2\n", []string{"--a", "1"}},
+		{"hash inside arg", "--tag a#b", []string{"--tag", "a#b"}},
+		{"single quotes", `--msg 'hello "world" \n'`, []string{"--msg", `hello "world" \n`}},
+		{"double quotes", `--msg "say \"hi\" \\ \x"`, []string{"--msg", `say "hi" \ \x`}},
+		{"adjacent quotes", `--kv=a'b c'"d"`, []string{"--kv=ab cd"}},
+		{"empty quoted", `--name '' --x ""`, []string{"--name", "", "--x", ""}},
+		{"backslash escapes", `a\ b \#c`, []string{"a b", "#c"}},
+		{"line continuation", "--a \\\n1", []string{"--a", "1"}},
+		{"multiline quote", "'a\nb' c", []string{"a\nb", "c"}},
+		{"empty", "  \n# only a comment\n", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitArgFile(tt.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	_, err := splitArgFile("--a 1\n--b 'oops\n")
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected unterminated quote error on line 2, got %v", err)
+	}
+}
+
+func writeArgFile(t *testing.T, path, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExpandArgFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := writeArgFile(t, filepath.Join(dir, "base.args"), "--host example.com\n@sub/more.args\n")
+	writeArgFile(t, filepath.Join(dir, "sub", "more.args"), "--port 8080 @@literal")
+
+	got, err := expandArgFiles(nil, []string{"run", "@" + base, "--name", "@@bob", "@", "--", "@" + base})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"run", "--host", "example.com", "--port", "8080", "@literal", "--name", "@bob", "@", "--", "@" + base}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestExpandArgFiles_Errors(t *testing.T) {
+	dir := t.TempDir()
+	a := writeArgFile(t, filepath.Join(dir, "a.args"), "@b.args")
+	writeArgFile(t, filepath.Join(dir, "b.args"), "--x 1 @a.args")
+	self := writeArgFile(t, filepath.Join(dir, "self.args"), "@self.args")
+
+	_, err := expandArgFiles(nil, []string{"@" + a})
+	if err == nil || !strings.Contains(err.Error(), "cycle") || !strings.Contains(err.Error(), "b.args") {
+		t.Errorf("expected cycle error through b.args, got %v", err)
+	}
+	_, err = expandArgFiles(nil, []string{"@" + self})
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("expected self-include cycle error, got %v", err)
+	}
+
+	_, err = expandArgFiles(nil, []string{"@" + filepath.Join(dir, "missing.args")})
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+
+	// The same file twice side by side is not a cycle
+	twice := writeArgFile(t, filepath.Join(dir, "twice.args"), "--v")
+	got, err := expandArgFiles(nil, []string{"@" + twice, "@" + twice})
+	if err != nil || len(got) != 2 {
+		t.Errorf("expected repeated argfile to expand twice, got %q (%v)", got, err)
+	}
+}
+
+type argFileParams struct {
+	Host  string   `descr:"host"`
+	Port  int      `descr:"port" default:"80"`
+	Tags  []string `descr:"tags" optional:"true"`
+	Files []string `positional:"true" optional:"true"`
+}
+
+func TestArgFiles_Command(t *testing.T) {
+	path := writeArgFile(t, filepath.Join(t.TempDir(), "ci.args"), `
+# CI settings
+--host "build server.local"
+--port 9000
+--tags a,b
+`)
+
+	var got argFileParams
+	err := (CmdT[argFileParams]{
+		Use:      "test",
+		ArgFiles: true,
+		RunFunc: func(p *argFileParams, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"@" + path, "--port", "9001", "@@weird-file"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := argFileParams{Host: "build server.local", Port: 9001, Tags: []string{"a", "b"}, Files: []string{"@weird-file"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestArgFiles_OffByDefault(t *testing.T) {
+	var got argFileParams
+	err := (CmdT[argFileParams]{
+		Use: "test",
+		RunFunc: func(p *argFileParams, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--host", "h", "@not-a-file"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got.Files, []string{"@not-a-file"}) {
+		t.Errorf("expected @ args untouched, got %q", got.Files)
+	}
+}
+
+func TestArgFiles_ErrorIsUserInputError(t *testing.T) {
+	ran := false
+	for _, cmd := range []CmdT[argFileParams]{
+		{Use: "test", ArgFiles: true, RunFunc: func(*argFileParams, *cobra.Command, []string) { ran = true }},
+		{Use: "test", ArgFiles: true, SubCmds: SubCmds(CmdT[NoParams]{Use: "sub"})},
+	} {
+		err := cmd.RunArgsE([]string{"--host", "h", "@" + filepath.Join(t.TempDir(), "missing.args")})
+		if err == nil || !strings.Contains(err.Error(), "missing.args") {
+			t.Errorf("expected argfile error, got %v", err)
+		}
+		if !IsUserInputError(err) {
+			t.Errorf("expected user input error, got %v", err)
+		}
+	}
+	if ran {
+		t.Error("command should not run when argfile expansion fails")
+	}
+}
+
+func TestArgFiles_ReloadReplaysExpandedArgs(t *testing.T) {
+	path := writeArgFile(t, filepath.Join(t.TempDir(), "run.args"), "--host first")
+
+	var first, second string
+	err := (CmdT[argFileParams]{
+		Use:      "test",
+		ArgFiles: true,
+		RunFuncCtx: func(ctx *HookContext, p *argFileParams, cmd *cobra.Command, args []string) {
+			first = p.Host
+			writeArgFile(t, path, "--host second")
+			fresh, err := Reload[argFileParams](ctx)
+			if err != nil {
+				t.Fatalf("Reload: %v", err)
+			}
+			second = fresh.Host
+		},
+	}).RunArgsE([]string{"@" + path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != "first" || second != "first" {
+		t.Errorf("expected reload to replay the original expansion, got %q then %q", first, second)
+	}
+}
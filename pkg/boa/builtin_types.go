@@ -0,0 +1,149 @@
+package boa
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ipNetType    = reflect.TypeOf(net.IPNet{})
+	locationType = reflect.TypeOf(time.Location{})
+	regexpType   = reflect.TypeOf(regexp.Regexp{})
+	fileModeType = reflect.TypeOf(fs.FileMode(0))
+	urlValsType  = reflect.TypeOf(url.Values{})
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+// registerStdlibTypes registers the standard library types beyond the core
+// set in registerBuiltinTypes. All of them are string-backed in cobra, so
+// each gets scalar, pointer, []T and []*T support from registerStringBacked.
+func registerStdlibTypes() {
+	// Types that already implement encoding.TextUnmarshaler would be picked
+	// up lazily anyway; registering them here keeps the catalogue explicit.
+	for _, t := range []reflect.Type{
+		reflect.TypeOf(netip.Addr{}),
+		reflect.TypeOf(netip.Prefix{}),
+		reflect.TypeOf(netip.AddrPort{}),
+		reflect.TypeOf(slog.Level(0)),
+		bigIntType,
+		bigFloatType,
+	} {
+		registerInterfaceType(t)
+	}
+
+	registerConfigText(ipNetType,
+		func(s string) (reflect.Value, error) {
+			_, ipNet, err := net.ParseCIDR(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid CIDR %q (expected e.g. 10.0.0.0/8)", s)
+			}
+			return reflect.ValueOf(ipNet), nil
+		},
+		func(v reflect.Value) string {
+			ipNet := v.Interface().(net.IPNet)
+			if ipNet.IP == nil {
+				return ""
+			}
+			return ipNet.String()
+		})
+
+	registerConfigText(locationType,
+		func(s string) (reflect.Value, error) {
+			loc, err := time.LoadLocation(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("unknown time zone %q", s)
+			}
+			return reflect.ValueOf(loc), nil
+		},
+		func(v reflect.Value) string {
+			return addressable(v).Interface().(*time.Location).String()
+		})
+
+	registerStringBacked(regexpType,
+		func(s string) (reflect.Value, error) {
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid regular expression %q: %w", s, err)
+			}
+			return reflect.ValueOf(re), nil
+		},
+		func(v reflect.Value) string {
+			return addressable(v).Interface().(*regexp.Regexp).String()
+		})
+
+	registerConfigText(fileModeType,
+		func(s string) (reflect.Value, error) {
+			mode, err := parseFileMode(s)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(&mode), nil
+		},
+		func(v reflect.Value) string {
+			return formatFileMode(v.Interface().(fs.FileMode))
+		})
+
+	registerStringBacked(urlValsType,
+		func(s string) (reflect.Value, error) {
+			vals, err := url.ParseQuery(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("invalid query string %q: %w", s, err)
+			}
+			return reflect.ValueOf(&vals), nil
+		},
+		func(v reflect.Value) string {
+			return v.Interface().(url.Values).Encode()
+		})
+}
+
+// registerConfigText registers a string-backed type that config files also
+// carry in its string form (see configTextCodec).
+func registerConfigText(t reflect.Type, parse func(string) (reflect.Value, error), format func(reflect.Value) string) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	setConfigText(t, parse, format)
+}
+
+// setConfigText is registerConfigText for callers holding handlersMu.
+func setConfigText(t reflect.Type, parse func(string) (reflect.Value, error), format func(reflect.Value) string) {
+	setStringBacked(t, parse, format)
+	configTextTypes[t] = configTextCodec{parse: parse, format: format}
+}
+
+// parseFileMode parses an octal permission string: "0644", "644" or "0o644".
+func parseFileMode(s string) (fs.FileMode, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "0o"), "0O")
+	v, err := strconv.ParseUint(digits, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file mode %q (expected octal, e.g. 0644)", s)
+	}
+	return fs.FileMode(v), nil
+}
+
+// formatFileMode renders a file mode the way parseFileMode reads it.
+func formatFileMode(mode fs.FileMode) string {
+	return fmt.Sprintf("%04o", uint32(mode))
+}
+
+// addressable returns a pointer to v, copying it first if v itself isn't
+// addressable, so pointer-receiver methods like (*time.Location).String can
+// be called on it.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	return ptr
+}
@@ -0,0 +1,372 @@
+package boa
+
+import (
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestBuiltinTypes_Scalars(t *testing.T) {
+	type Params struct {
+		Prefix  netip.Prefix   `optional:"true"`
+		Listen  netip.AddrPort `default:"127.0.0.1:8080"`
+		Subnet  *net.IPNet     `optional:"true"`
+		Subnet2 net.IPNet      `optional:"true"`
+		Match   *regexp.Regexp `optional:"true"`
+		Tz      *time.Location `default:"UTC"`
+		Mode    os.FileMode    `default:"0644"`
+		Query   url.Values     `optional:"true"`
+		Count   *big.Int       `optional:"true"`
+		Ratio   *big.Float     `optional:"true"`
+		Level   slog.Level     `default:"info"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{
+		"--prefix", "10.0.0.0/8",
+		"--subnet", "192.168.0.0/16",
+		"--subnet2", "fd00::/8",
+		"--match", "^v[0-9]+$",
+		"--query", "a=1&a=2&b=x",
+		"--count", "123456789012345678901234567890",
+		"--ratio", "0.125",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Prefix != netip.MustParsePrefix("10.0.0.0/8") {
+		t.Errorf("unexpected prefix: %v", got.Prefix)
+	}
+	if got.Listen != netip.MustParseAddrPort("127.0.0.1:8080") {
+		t.Errorf("unexpected listen: %v", got.Listen)
+	}
+	if got.Subnet == nil || got.Subnet.String() != "192.168.0.0/16" {
+		t.Errorf("unexpected subnet: %v", got.Subnet)
+	}
+	if got.Subnet2.String() != "fd00::/8" {
+		t.Errorf("unexpected subnet2: %v", got.Subnet2)
+	}
+	if got.Match == nil || !got.Match.MatchString("v12") || got.Match.MatchString("x") {
+		t.Errorf("unexpected regexp: %v", got.Match)
+	}
+	if got.Tz == nil || got.Tz.String() != "UTC" {
+		t.Errorf("expected UTC, got %v", got.Tz)
+	}
+	if got.Mode != 0o644 {
+		t.Errorf("expected mode 0644, got %o", got.Mode)
+	}
+	if !reflect.DeepEqual(got.Query, url.Values{"a": {"1", "2"}, "b": {"x"}}) {
+		t.Errorf("unexpected query: %v", got.Query)
+	}
+	if got.Count == nil || got.Count.String() != "123456789012345678901234567890" {
+		t.Errorf("unexpected count: %v", got.Count)
+	}
+	if got.Ratio == nil || got.Ratio.String() != "0.125" {
+		t.Errorf("unexpected ratio: %v", got.Ratio)
+	}
+	if got.Level != slog.LevelInfo {
+		t.Errorf("unexpected level: %v", got.Level)
+	}
+}
+
+func TestBuiltinTypes_Slices(t *testing.T) {
+	type Params struct {
+		Subnets  []*net.IPNet     `optional:"true"`
+		Prefixes []netip.Prefix   `optional:"true"`
+		Patterns []*regexp.Regexp `optional:"true"`
+		Zones    []*time.Location `optional:"true"`
+		Modes    []os.FileMode    `optional:"true"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{
+		"--subnets", "10.0.0.0/8,172.16.0.0/12",
+		"--prefixes", "::1/128",
+		"--patterns", "^a", "--patterns", "b$",
+		"--zones", "UTC,Europe/Stockholm",
+		"--modes", "0600,755",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Subnets) != 2 || got.Subnets[1].String() != "172.16.0.0/12" {
+		t.Errorf("unexpected subnets: %v", got.Subnets)
+	}
+	if len(got.Prefixes) != 1 || got.Prefixes[0] != netip.MustParsePrefix("::1/128") {
+		t.Errorf("unexpected prefixes: %v", got.Prefixes)
+	}
+	if len(got.Patterns) != 2 || got.Patterns[0].String() != "^a" || got.Patterns[1].String() != "b$" {
+		t.Errorf("unexpected patterns: %v", got.Patterns)
+	}
+	if len(got.Zones) != 2 || got.Zones[0].String() != "UTC" || got.Zones[1].String() != "Europe/Stockholm" {
+		t.Errorf("unexpected zones: %v", got.Zones)
+	}
+	if !reflect.DeepEqual(got.Modes, []os.FileMode{0o600, 0o755}) {
+		t.Errorf("unexpected modes: %v", got.Modes)
+	}
+}
+
+func TestBuiltinTypes_Errors(t *testing.T) {
+	type Params struct {
+		Subnet *net.IPNet     `optional:"true"`
+		Match  *regexp.Regexp `optional:"true"`
+		Tz     *time.Location `optional:"true"`
+		Mode   os.FileMode    `optional:"true"`
+	}
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--subnet", "10.0.0.1"}, `invalid CIDR "10.0.0.1"`},
+		{[]string{"--match", "a(b"}, `invalid regular expression "a(b"`},
+		{[]string{"--tz", "Mars/Olympus"}, `unknown time zone "Mars/Olympus"`},
+		{[]string{"--mode", "0999"}, `invalid file mode "0999"`},
+	}
+	for _, tc := range cases {
+		err := (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(tc.args)
+		if err == nil || !IsUserInputError(err) {
+			t.Errorf("%v: expected user input error, got %v", tc.args, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected error containing %q, got: %v", tc.args, tc.want, err)
+		}
+	}
+}
+
+func TestBuiltinTypes_HelpDefaults(t *testing.T) {
+	type Params struct {
+		Mode   os.FileMode    `default:"0o750" descr:"mode"`
+		Tz     *time.Location `default:"Europe/Stockholm" descr:"zone"`
+		Subnet *net.IPNet     `default:"10.0.0.0/8" descr:"subnet"`
+		Match  *regexp.Regexp `default:"^x+$" descr:"match"`
+		Nets   []*net.IPNet   `default:"[10.0.0.0/8,fd00::/8]" descr:"nets"`
+		Query  url.Values     `default:"a=1" descr:"query"`
+	}
+
+	usage := captureUsage(t, CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	})
+	for _, want := range []string{`(default "0750")`, `(default "Europe/Stockholm")`, `(default "10.0.0.0/8")`, `(default "^x+$")`, "[10.0.0.0/8,fd00::/8]", `(default "a=1")`} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("expected %s in help:\n%s", want, usage)
+		}
+	}
+}
+
+func TestBuiltinTypes_MinMax(t *testing.T) {
+	type Params struct {
+		Count *big.Int    `optional:"true" min:"1" max:"1e30"`
+		Ratio big.Float   `optional:"true" max:"0.5"`
+		Level slog.Level  `optional:"true" max:"4"`
+		Mode  os.FileMode `optional:"true" max:"511"`
+	}
+
+	run := func(args ...string) error {
+		return (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(args)
+	}
+
+	if err := run("--count", "999999999999999999999999999999", "--ratio", "0.5", "--level", "warn", "--mode", "0777"); err != nil {
+		t.Errorf("expected values within bounds to pass, got %v", err)
+	}
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--count", "0"}, "value 0 is below min 1"},
+		{[]string{"--count", "1000000000000000000000000000001"}, "exceeds max"},
+		{[]string{"--ratio", "0.75"}, "value 0.75 exceeds max 0.5"},
+		{[]string{"--level", "error"}, "value 8 exceeds max 4"},
+		{[]string{"--mode", "1777"}, "exceeds max 511"},
+	}
+	for _, tc := range cases {
+		err := run(tc.args...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tc.args, tc.want, err)
+		}
+	}
+}
+
+func TestBuiltinTypes_BigBoundProgrammatic(t *testing.T) {
+	type Params struct {
+		Count *big.Int `optional:"true"`
+	}
+
+	err := (CmdT[Params]{
+		Use: "test",
+		InitFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command) error {
+			GetParamT(ctx, &p.Count).SetMaxT(big.NewInt(10))
+			return nil
+		},
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--count", "11"})
+	if err == nil || !strings.Contains(err.Error(), "value 11 exceeds max 10") {
+		t.Errorf("expected max violation, got %v", err)
+	}
+}
+
+func TestBuiltinTypes_DumpRoundTrip(t *testing.T) {
+	type Params struct {
+		ConfigFile string         `configfile:"true" optional:"true"`
+		Tz         *time.Location `optional:"true"`
+		Subnet     *net.IPNet     `optional:"true"`
+		Nets       []*net.IPNet   `optional:"true"`
+		Mode       os.FileMode    `optional:"true"`
+		Match      *regexp.Regexp `optional:"true"`
+		Prefix     netip.Prefix   `optional:"true"`
+		Count      *big.Int       `optional:"true"`
+		Query      url.Values     `optional:"true"`
+	}
+
+	var dumped []byte
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command, args []string) {
+			var err error
+			if dumped, err = ctx.DumpBytes(".json", nil); err != nil {
+				t.Fatalf("DumpBytes: %v", err)
+			}
+		},
+	}).RunArgsE([]string{
+		"--tz", "Europe/Stockholm",
+		"--subnet", "10.1.0.0/16",
+		"--nets", "10.0.0.0/8,fd00::/8",
+		"--mode", "0640",
+		"--match", "^a.*z$",
+		"--prefix", "192.168.0.0/24",
+		"--count", "12345678901234567890",
+		"--query", "k=v",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(dumped, &tree); err != nil {
+		t.Fatalf("parse dump: %v\n%s", err, dumped)
+	}
+	for key, want := range map[string]any{
+		"Tz":     "Europe/Stockholm",
+		"Subnet": "10.1.0.0/16",
+		"Mode":   "0640",
+		"Match":  "^a.*z$",
+		"Prefix": "192.168.0.0/24",
+	} {
+		if tree[key] != want {
+			t.Errorf("expected %s=%v in dump, got %v\n%s", key, want, tree[key], dumped)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "dump.json")
+	if err := os.WriteFile(path, dumped, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var got Params
+	err = (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--config-file", path})
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got.Tz == nil || got.Tz.String() != "Europe/Stockholm" {
+		t.Errorf("round-trip Tz: %v", got.Tz)
+	}
+	if got.Subnet == nil || got.Subnet.String() != "10.1.0.0/16" {
+		t.Errorf("round-trip Subnet: %v", got.Subnet)
+	}
+	if len(got.Nets) != 2 || got.Nets[1].String() != "fd00::/8" {
+		t.Errorf("round-trip Nets: %v", got.Nets)
+	}
+	if got.Mode != 0o640 {
+		t.Errorf("round-trip Mode: %o", got.Mode)
+	}
+	if got.Match == nil || got.Match.String() != "^a.*z$" {
+		t.Errorf("round-trip Match: %v", got.Match)
+	}
+	if got.Prefix != netip.MustParsePrefix("192.168.0.0/24") {
+		t.Errorf("round-trip Prefix: %v", got.Prefix)
+	}
+	if got.Count == nil || got.Count.String() != "12345678901234567890" {
+		t.Errorf("round-trip Count: %v", got.Count)
+	}
+	if got.Query.Get("k") != "v" {
+		t.Errorf("round-trip Query: %v", got.Query)
+	}
+}
+
+func TestBuiltinTypes_ConfigFileForms(t *testing.T) {
+	type Server struct {
+		Tz   *time.Location `optional:"true"`
+		Mode os.FileMode    `optional:"true"`
+	}
+	type Params struct {
+		ConfigFile string `configfile:"true" optional:"true"`
+		Server     Server
+		Tz         *time.Location `default:"UTC"`
+	}
+
+	// Nested string forms, a numeric FileMode, and an untouched top-level
+	// default all load together.
+	path := writeTestConfigFile(t, `{"Server":{"Tz":"Asia/Tokyo","Mode":420}}`)
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--config-file", path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Server.Tz == nil || got.Server.Tz.String() != "Asia/Tokyo" {
+		t.Errorf("unexpected server tz: %v", got.Server.Tz)
+	}
+	if got.Server.Mode != 0o644 {
+		t.Errorf("unexpected server mode: %o", got.Server.Mode)
+	}
+	if got.Tz == nil || got.Tz.String() != "UTC" {
+		t.Errorf("expected default tz to survive config load, got %v", got.Tz)
+	}
+
+	bad := writeTestConfigFile(t, `{"Server":{"Tz":"Nowhere/Land"}}`)
+	err = (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--config-file", bad})
+	if err == nil || !strings.Contains(err.Error(), "Server.Tz") || !strings.Contains(err.Error(), "unknown time zone") {
+		t.Errorf("expected config error naming the field, got %v", err)
+	}
+}
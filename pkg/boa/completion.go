@@ -0,0 +1,142 @@
+package boa
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// completionFunc is the signature cobra uses for flag and positional
+// completion.
+type completionFunc = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// durationUnits are appended to a number being completed for a duration.
+var durationUnits = []string{"ms", "s", "m", "h"}
+
+// parseCompletion parses a `complete` tag into the values and directive
+// handed to cobra:
+//
+//	file               any file (the shell's default completion)
+//	file:*.yaml,*.yml  files with one of the extensions, and directories
+//	dir                directories only
+//	none               no completion at all
+func parseCompletion(spec string) ([]string, cobra.ShellCompDirective, error) {
+	kind, exts, hasExts := strings.Cut(spec, ":")
+	switch strings.TrimSpace(kind) {
+	case "file":
+		if !hasExts {
+			return nil, cobra.ShellCompDirectiveDefault, nil
+		}
+		var out []string
+		for _, ext := range splitAltsTag(exts) {
+			ext = strings.TrimPrefix(strings.TrimPrefix(ext, "*"), ".")
+			if ext == "" || strings.ContainsAny(ext, "*?/") {
+				return nil, 0, fmt.Errorf("invalid file extension %q in %q", ext, spec)
+			}
+			out = append(out, ext)
+		}
+		if len(out) == 0 {
+			return nil, 0, fmt.Errorf("no file extensions in %q", spec)
+		}
+		return out, cobra.ShellCompDirectiveFilterFileExt, nil
+	case "dir":
+		if hasExts {
+			return nil, 0, fmt.Errorf("dir completion takes no arguments, got %q", spec)
+		}
+		return nil, cobra.ShellCompDirectiveFilterDirs, nil
+	case "none":
+		if hasExts {
+			return nil, 0, fmt.Errorf("none completion takes no arguments, got %q", spec)
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp, nil
+	}
+	return nil, 0, fmt.Errorf("unknown completion %q: expected file, file:<exts>, dir or none", spec)
+}
+
+// paramCompletion returns the shell completion for f, or nil if it has
+// none. In order of precedence: an alternatives func, static alternatives
+// (which includes registered enums), the `complete` tag, the registered
+// config formats for configfile params, and finally completions derived
+// from the type for bools and durations.
+func paramCompletion(ctx *processingContext, f Param) completionFunc {
+	if fn := f.GetAlternativesFunc(); fn != nil {
+		return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			// Sync cobra's parsed flag values into raw struct fields before calling
+			// the user's completion function. Without this, raw fields (plain string,
+			// int, etc.) are zero during completion because PreRunE (which normally
+			// calls syncMirrors) is never executed for shell completion.
+			syncMirrors(ctx)
+			return fn(cmd, args, toComplete), cobra.ShellCompDirectiveDefault
+		}
+	}
+	if alts := f.GetAlternatives(); alts != nil {
+		return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return alts, cobra.ShellCompDirectiveDefault
+		}
+	}
+	if spec := f.GetCompletion(); spec != "" {
+		values, directive, err := parseCompletion(spec)
+		if err != nil {
+			// Validated by the tag and SetCompletion, so this is a bug
+			panic(fmt.Errorf("boa: completion for %q: %w", f.GetName(), err))
+		}
+		return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return values, directive
+		}
+	}
+	if f.IsConfigFile() {
+		return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			var exts []string
+			for _, ext := range ConfigFormatExtensions() {
+				exts = append(exts, strings.TrimPrefix(ext, "."))
+			}
+			return exts, cobra.ShellCompDirectiveFilterFileExt
+		}
+	}
+
+	t := f.GetType()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType:
+		return completeDuration
+	case t.Kind() == reflect.Bool:
+		return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil
+}
+
+// completeDuration suggests units once a number has been typed, so
+// "30<TAB>" offers 30ms, 30s, 30m and 30h.
+func completeDuration(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if toComplete == "" || strings.IndexFunc(toComplete, func(r rune) bool { return (r < '0' || r > '9') && r != '.' }) >= 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	out := make([]string, 0, len(durationUnits))
+	for _, unit := range durationUnits {
+		out = append(out, toComplete+unit)
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// positionalCompletion returns the completion for the positional at
+// position pos, or nil if it has none. A trailing slice positional covers
+// every position from its own onwards.
+func positionalCompletion(ctx *processingContext, positional []Param, pos int) completionFunc {
+	if len(positional) == 0 {
+		return nil
+	}
+	if pos >= len(positional) {
+		last := positional[len(positional)-1]
+		if last.GetKind() != reflect.Slice {
+			return nil
+		}
+		pos = len(positional) - 1
+	}
+	return paramCompletion(ctx, positional[pos])
+}
@@ -0,0 +1,145 @@
+package boa
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type completionParams struct {
+	Config  string        `configfile:"true" optional:"true"`
+	Values  string        `complete:"file:*.yaml,.json" optional:"true"`
+	Any     string        `complete:"file" optional:"true"`
+	OutDir  string        `complete:"dir" optional:"true"`
+	Name    string        `complete:"none" optional:"true"`
+	Mode    string        `alts:"fast,slow" complete:"none" optional:"true"`
+	Verbose bool          `optional:"true"`
+	Wait    time.Duration `optional:"true"`
+	Plain   string        `optional:"true"`
+	Src     string        `positional:"true" complete:"dir"`
+	Files   []string      `positional:"true" complete:"file:*.txt" optional:"true"`
+}
+
+func completionCmd() *cobra.Command {
+	return (CmdT[completionParams]{
+		Use:     "app",
+		RunFunc: func(*completionParams, *cobra.Command, []string) {},
+	}).ToCobra()
+}
+
+func complete(t *testing.T, fn completionFunc, toComplete string) ([]string, cobra.ShellCompDirective) {
+	t.Helper()
+	if fn == nil {
+		t.Fatal("expected a completion func")
+	}
+	return fn(nil, nil, toComplete)
+}
+
+func TestCompletion_Flags(t *testing.T) {
+	cmd := completionCmd()
+	var configExts []string
+	for _, ext := range ConfigFormatExtensions() {
+		configExts = append(configExts, strings.TrimPrefix(ext, "."))
+	}
+	tests := []struct {
+		flag       string
+		toComplete string
+		values     []string
+		directive  cobra.ShellCompDirective
+	}{
+		{"config", "", configExts, cobra.ShellCompDirectiveFilterFileExt},
+		{"values", "", []string{"yaml", "json"}, cobra.ShellCompDirectiveFilterFileExt},
+		{"any", "", nil, cobra.ShellCompDirectiveDefault},
+		{"out-dir", "", nil, cobra.ShellCompDirectiveFilterDirs},
+		{"name", "", nil, cobra.ShellCompDirectiveNoFileComp},
+		{"mode", "", []string{"fast", "slow"}, cobra.ShellCompDirectiveDefault},
+		{"verbose", "", []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp},
+		{"wait", "30", []string{"30ms", "30s", "30m", "30h"}, cobra.ShellCompDirectiveNoFileComp},
+		{"wait", "1h", nil, cobra.ShellCompDirectiveNoFileComp},
+	}
+	for _, tt := range tests {
+		fn, _ := cmd.GetFlagCompletionFunc(tt.flag)
+		values, directive := complete(t, fn, tt.toComplete)
+		if !reflect.DeepEqual(values, tt.values) || directive != tt.directive {
+			t.Errorf("--%s %q: expected %v, %v, got %v, %v", tt.flag, tt.toComplete, tt.values, tt.directive, values, directive)
+		}
+	}
+	if fn, _ := cmd.GetFlagCompletionFunc("plain"); fn != nil {
+		t.Error("expected no completion func for a plain string")
+	}
+}
+
+func TestCompletion_Positionals(t *testing.T) {
+	cmd := completionCmd()
+	if cmd.ValidArgsFunction == nil {
+		t.Fatal("expected a ValidArgsFunction")
+	}
+	if values, directive := cmd.ValidArgsFunction(cmd, nil, ""); values != nil || directive != cobra.ShellCompDirectiveFilterDirs {
+		t.Errorf("src: got %v, %v", values, directive)
+	}
+	// The trailing slice completes every remaining position
+	for _, args := range [][]string{{"src"}, {"src", "a.txt", "b.txt"}} {
+		values, directive := cmd.ValidArgsFunction(cmd, args, "")
+		if !reflect.DeepEqual(values, []string{"txt"}) || directive != cobra.ShellCompDirectiveFilterFileExt {
+			t.Errorf("files after %v: got %v, %v", args, values, directive)
+		}
+	}
+}
+
+func TestCompletion_Programmatic(t *testing.T) {
+	type params struct {
+		Out string `optional:"true"`
+	}
+	cmd := (CmdT[params]{
+		Use: "app",
+		InitFuncCtx: func(ctx *HookContext, p *params, cmd *cobra.Command) error {
+			GetParamT(ctx, &p.Out).SetCompletion("dir")
+			return nil
+		},
+		RunFunc: func(*params, *cobra.Command, []string) {},
+	}).ToCobra()
+	fn, _ := cmd.GetFlagCompletionFunc("out")
+	if _, directive := complete(t, fn, ""); directive != cobra.ShellCompDirectiveFilterDirs {
+		t.Errorf("expected dir completion, got %v", directive)
+	}
+}
+
+func TestCompletion_InvalidTag(t *testing.T) {
+	for _, spec := range []string{"files", "file:", "dir:x", "file:*.y/ml"} {
+		type params struct {
+			Out string `optional:"true"`
+		}
+		p := &params{}
+		err := (CmdT[params]{
+			Use:    "app",
+			Params: p,
+			InitFuncCtx: func(ctx *HookContext, p *params, cmd *cobra.Command) error {
+				defer func() {
+					if r := recover(); r == nil {
+						t.Errorf("expected SetCompletion(%q) to panic", spec)
+					}
+				}()
+				GetParamT(ctx, &p.Out).SetCompletion(spec)
+				return nil
+			},
+			RunFunc: func(*params, *cobra.Command, []string) {},
+		}).RunArgsE(nil)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	type badParams struct {
+		Out string `complete:"folder" optional:"true"`
+	}
+	err := (CmdT[badParams]{
+		Use:     "app",
+		RunFunc: func(*badParams, *cobra.Command, []string) {},
+	}).RunArgsE(nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid complete tag") {
+		t.Errorf("expected an invalid complete tag error, got %v", err)
+	}
+}
@@ -0,0 +1,327 @@
+package boa
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// configTextCodec is the string form of a built-in type whose native
+// encoding is no use in a config file: *time.Location marshals to {},
+// net.IPNet to an object with a base64 mask, and fs.FileMode to a decimal
+// number. Source-aware dumps write these in their CLI string form, and
+// config loading accepts that form (as well as the native one) through a
+// shadow struct — see unmarshalConfig.
+type configTextCodec struct {
+	parse  func(string) (reflect.Value, error) // returns *T
+	format func(reflect.Value) string          // takes T
+}
+
+// configTextTypes is filled in by registerStdlibTypes.
+var configTextTypes = map[reflect.Type]configTextCodec{}
+
+// configTextLeaf returns the codec for t or *t, if any.
+func configTextLeaf(t reflect.Type) (configTextCodec, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	c, ok := configTextTypes[t]
+	return c, ok
+}
+
+// dumpValue returns the value to place in a source-aware dump for v,
+// rendering config text types (also inside slices and maps) as strings.
+func dumpValue(v reflect.Value) any {
+	if _, changed := shadowTypeOf(v.Type()); !changed {
+		return v.Interface()
+	}
+	return dumpValueRec(v)
+}
+
+func dumpValueRec(v reflect.Value) any {
+	t := v.Type()
+	if codec, ok := configTextLeaf(t); ok {
+		if t.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		return codec.format(v)
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return dumpValueRec(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		out := make([]any, v.Len())
+		for i := range out {
+			out[i] = dumpValueRec(v.Index(i))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		out := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[fmt.Sprint(iter.Key().Interface())] = dumpValueRec(iter.Value())
+		}
+		return out
+	case reflect.Struct:
+		out := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			if sf := t.Field(i); sf.IsExported() {
+				out[sf.Name] = dumpValueRec(v.Field(i))
+			}
+		}
+		return out
+	}
+	return v.Interface()
+}
+
+// --- Shadow structs for config loading ---
+//
+// Config formats unmarshal straight into the params struct, which fails for
+// config text types written as strings. When a target type contains any, it
+// is unmarshaled into a shadow type instead: the same shape, field names and
+// tags, but with every config text leaf replaced by `any`. The shadow is
+// seeded from the target (so keys missing from the file keep their current
+// values), unmarshaled into, and copied back with the leaves parsed.
+
+var (
+	anyType        = reflect.TypeOf((*any)(nil)).Elem()
+	shadowTypes    = map[reflect.Type]shadowTypeEntry{}
+	shadowTypesMu  sync.Mutex
+	shadowBuilding = map[reflect.Type]bool{}
+)
+
+type shadowTypeEntry struct {
+	typ     reflect.Type
+	changed bool
+}
+
+// shadowTypeOf returns the shadow type for t and whether it differs from t.
+func shadowTypeOf(t reflect.Type) (reflect.Type, bool) {
+	shadowTypesMu.Lock()
+	defer shadowTypesMu.Unlock()
+	return shadowTypeLocked(t)
+}
+
+func shadowTypeLocked(t reflect.Type) (st reflect.Type, changed bool) {
+	if e, ok := shadowTypes[t]; ok {
+		return e.typ, e.changed
+	}
+	if _, ok := configTextLeaf(t); ok {
+		return anyType, true
+	}
+	if shadowBuilding[t] {
+		// Recursive type: leave the cycle alone
+		return t, false
+	}
+	shadowBuilding[t] = true
+	defer delete(shadowBuilding, t)
+	defer func() {
+		if r := recover(); r != nil {
+			// reflect.StructOf rejects some shapes (e.g. embedded types
+			// with methods); fall back to plain unmarshaling for those.
+			st, changed = t, false
+		}
+		shadowTypes[t] = shadowTypeEntry{st, changed}
+	}()
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		if elem, ok := shadowTypeLocked(t.Elem()); ok {
+			return reflect.PointerTo(elem), true
+		}
+	case reflect.Slice:
+		if elem, ok := shadowTypeLocked(t.Elem()); ok {
+			return reflect.SliceOf(elem), true
+		}
+	case reflect.Map:
+		if elem, ok := shadowTypeLocked(t.Elem()); ok {
+			return reflect.MapOf(t.Key(), elem), true
+		}
+	case reflect.Struct:
+		if _, ok := registeredHandler(t); ok {
+			return t, false
+		}
+		var fields []reflect.StructField
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			ft, ok := shadowTypeLocked(sf.Type)
+			changed = changed || ok
+			fields = append(fields, reflect.StructField{Name: sf.Name, Type: ft, Tag: sf.Tag, Anonymous: sf.Anonymous})
+		}
+		if changed {
+			return reflect.StructOf(fields), true
+		}
+	}
+	return t, false
+}
+
+// unmarshalConfig runs unmarshal against target, routing through a shadow
+// struct when the target contains config text types.
+func unmarshalConfig(unmarshal func([]byte, any) error, data []byte, target any) error {
+	tv := reflect.ValueOf(target)
+	if tv.Kind() != reflect.Pointer || tv.IsNil() {
+		return unmarshal(data, target)
+	}
+	st, changed := shadowTypeOf(tv.Elem().Type())
+	if !changed {
+		return unmarshal(data, target)
+	}
+	shadow := reflect.New(st)
+	toShadow(shadow.Elem(), tv.Elem())
+	if err := unmarshal(data, shadow.Interface()); err != nil {
+		return err
+	}
+	return fromShadow(tv.Elem(), shadow.Elem(), "")
+}
+
+// toShadow seeds dst (a shadow value) from src. Config text leaves are left
+// nil so an untouched key keeps the original value in fromShadow.
+func toShadow(dst, src reflect.Value) {
+	if dst.Type() == src.Type() {
+		dst.Set(src)
+		return
+	}
+	if _, ok := configTextLeaf(src.Type()); ok {
+		return
+	}
+	switch src.Kind() {
+	case reflect.Pointer:
+		if !src.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+			toShadow(dst.Elem(), src.Elem())
+		}
+	case reflect.Slice:
+		if !src.IsNil() {
+			dst.Set(reflect.MakeSlice(dst.Type(), src.Len(), src.Len()))
+			for i := 0; i < src.Len(); i++ {
+				toShadow(dst.Index(i), src.Index(i))
+			}
+		}
+	case reflect.Map:
+		if !src.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), src.Len()))
+			iter := src.MapRange()
+			for iter.Next() {
+				elem := reflect.New(dst.Type().Elem()).Elem()
+				toShadow(elem, iter.Value())
+				dst.SetMapIndex(iter.Key(), elem)
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < dst.NumField(); i++ {
+			toShadow(dst.Field(i), src.FieldByName(dst.Type().Field(i).Name))
+		}
+	}
+}
+
+// fromShadow copies an unmarshaled shadow value back into dst, parsing
+// config text leaves. path names the field in errors.
+func fromShadow(dst, src reflect.Value, path string) error {
+	if dst.Type() == src.Type() {
+		dst.Set(src)
+		return nil
+	}
+	if codec, ok := configTextLeaf(dst.Type()); ok {
+		return fromShadowLeaf(dst, src, codec, path)
+	}
+	switch dst.Kind() {
+	case reflect.Pointer:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return fromShadow(dst.Elem(), src.Elem(), path)
+	case reflect.Slice:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		out := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := fromShadow(out.Index(i), src.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+	case reflect.Map:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		out := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := fromShadow(elem, iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key().Interface())); err != nil {
+				return err
+			}
+			out.SetMapIndex(iter.Key(), elem)
+		}
+		dst.Set(out)
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			name := src.Type().Field(i).Name
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+			if err := fromShadow(dst.FieldByName(name), src.Field(i), fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fromShadowLeaf stores one decoded config text value. Strings go through
+// the CLI parser; anything else (e.g. a FileMode written as a number) is
+// round-tripped through JSON into the real type. nil leaves dst untouched.
+func fromShadowLeaf(dst, src reflect.Value, codec configTextCodec, path string) error {
+	if src.IsNil() {
+		return nil
+	}
+	raw := src.Elem().Interface()
+	if s, ok := raw.(string); ok {
+		ptr, err := codec.parse(s)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", path, err)
+		}
+		if dst.Kind() == reflect.Pointer {
+			dst.Set(ptr)
+		} else {
+			dst.Set(ptr.Elem())
+		}
+		return nil
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", path, err)
+	}
+	ptr := reflect.New(dst.Type())
+	if err := json.Unmarshal(b, ptr.Interface()); err != nil {
+		return fmt.Errorf("field %s: %w", path, err)
+	}
+	dst.Set(ptr.Elem())
+	return nil
+}
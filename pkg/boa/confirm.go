@@ -0,0 +1,164 @@
+package boa
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ErrNotConfirmed is returned (wrapped in a UserInputError) when the user
+// declines a confirmation prompt, or when one is needed but can't be asked
+// because stdin isn't a terminal and --yes wasn't given.
+var ErrNotConfirmed = errors.New("not confirmed")
+
+// confirmGate holds what a command needs to ask "Are you sure?" before
+// running. It is built in toCobraBase and checked right before the run
+// function.
+type confirmGate struct {
+	question func() string
+	yes      *bool
+	flag     string
+	env      string
+	in       io.Reader
+}
+
+// needsConfirm reports whether the command has a confirmation step, and
+// therefore a --yes flag.
+func (b Cmd) needsConfirm() bool {
+	return b.Confirm != "" || b.ConfirmFunc != nil
+}
+
+// withoutConfirm keeps the --yes flag (so the same args still parse) but
+// never asks. Used by Validate and reloads, which don't run the command.
+func (b Cmd) withoutConfirm() Cmd {
+	if b.needsConfirm() {
+		b.Confirm = ""
+		b.ConfirmFunc = func(any) string { return "" }
+	}
+	return b
+}
+
+// addConfirmFlag registers --yes / -y (and its env var) for a command with
+// a confirmation step. The env var is YES, passed through the command's
+// enricher so env prefixes apply. The short flag is skipped if taken.
+func (b Cmd) addConfirmFlag(cmd *cobra.Command, ctx *processingContext, enrich ParamEnricher) error {
+	if !b.needsConfirm() {
+		return nil
+	}
+	if cmd.Flags().Lookup("yes") != nil {
+		return fmt.Errorf("confirm: flag --yes is already taken by a parameter")
+	}
+
+	field := reflect.StructField{Name: "Yes", Type: reflect.TypeOf(false)}
+	yesParam := newParam(&field, field.Type)
+	yesParam.SetName("yes")
+	yesParam.SetEnv("YES")
+	if enrich != nil {
+		if err := enrich(nil, yesParam, field.Name); err != nil {
+			return fmt.Errorf("error enriching params: %s", err.Error())
+		}
+	}
+	short := "y"
+	if cmd.Flags().ShorthandLookup(short) != nil {
+		short = ""
+	}
+
+	descr := "skip the confirmation prompt"
+	if env := yesParam.GetEnv(); env != "" {
+		descr += fmt.Sprintf(" (env: %s)", env)
+	}
+
+	params := b.Params
+	gate := &confirmGate{
+		yes:  cmd.Flags().BoolP("yes", short, false, descr),
+		flag: "yes",
+		env:  yesParam.GetEnv(),
+		in:   b.ConfirmIn,
+	}
+	if b.ConfirmFunc != nil {
+		gate.question = func() string { return b.ConfirmFunc(params) }
+	} else {
+		gate.question = func() string { return b.Confirm }
+	}
+	ctx.confirm = gate
+	return nil
+}
+
+// check asks for confirmation unless --yes (or its env var) was given, or
+// the question is empty. It returns nil only when it is OK to proceed.
+func (g *confirmGate) check(cmd *cobra.Command) error {
+	question := g.question()
+	if question == "" {
+		return nil
+	}
+	yes, err := g.preConfirmed(cmd)
+	if err != nil || yes {
+		return err
+	}
+
+	in := g.in
+	if in == nil {
+		stdin, interactive := stdinOf(cmd)
+		if !interactive {
+			hint := "--" + g.flag
+			if g.env != "" {
+				hint += " or set " + g.env + "=true"
+			}
+			return newUserInputError(fmt.Errorf("%w: refusing to continue without confirmation when not running interactively, pass %s", ErrNotConfirmed, hint))
+		}
+		in = stdin
+	}
+
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s [y/N]: ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		fmt.Fprintln(cmd.ErrOrStderr())
+		return newUserInputError(fmt.Errorf("%w: %w", ErrNotConfirmed, err))
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return nil
+	default:
+		return newUserInputError(fmt.Errorf("%w: aborted", ErrNotConfirmed))
+	}
+}
+
+// preConfirmed reports whether --yes was passed or its env var is true.
+func (g *confirmGate) preConfirmed(cmd *cobra.Command) (bool, error) {
+	if cmd.Flags().Changed(g.flag) {
+		return *g.yes, nil
+	}
+	if g.env == "" {
+		return false, nil
+	}
+	val, ok := LookupEnv(cmd, g.env)
+	if !ok || val == "" {
+		return false, nil
+	}
+	yes, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, newUserInputErrorf("invalid value for env var %s: %q is not a boolean", g.env, val)
+	}
+	return yes, nil
+}
+
+// wrapConfirm runs the confirmation step, if the command has one, before
+// the run function installed on cmd.
+func wrapConfirm(cmd *cobra.Command, ctx *processingContext) {
+	if ctx.confirm == nil || cmd.RunE == nil {
+		return
+	}
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := ctx.confirm.check(cmd); err != nil {
+			return err
+		}
+		return run(cmd, args)
+	}
+}
@@ -0,0 +1,232 @@
+package boa
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type confirmParams struct {
+	Clusters []string `descr:"clusters to delete"`
+	Env      string   `descr:"environment" default:"prod"`
+}
+
+func confirmCmd(in string, ran *bool) CmdT[confirmParams] {
+	return CmdT[confirmParams]{
+		Use: "delete-cluster",
+		ConfirmFunc: func(p *confirmParams) string {
+			return fmt.Sprintf("Delete %d clusters in %s?", len(p.Clusters), p.Env)
+		},
+		ConfirmIn: strings.NewReader(in),
+		RunFunc: func(p *confirmParams, cmd *cobra.Command, args []string) {
+			*ran = true
+		},
+	}
+}
+
+func TestConfirm_Answers(t *testing.T) {
+	tests := []struct {
+		answer  string
+		proceed bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{" yes ", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+		{"sure\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.answer), func(t *testing.T) {
+			ran := false
+			err := confirmCmd(tt.answer, &ran).RunArgsE([]string{"--clusters", "a,b,c"})
+			if ran != tt.proceed {
+				t.Errorf("expected ran=%v, got %v", tt.proceed, ran)
+			}
+			if tt.proceed && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.proceed {
+				if !errors.Is(err, ErrNotConfirmed) {
+					t.Errorf("expected ErrNotConfirmed, got %v", err)
+				}
+				if !IsUserInputError(err) {
+					t.Errorf("expected user input error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestConfirm_QuestionUsesResolvedParams(t *testing.T) {
+	ran := false
+	var stderr bytes.Buffer
+	cmd := confirmCmd("y\n", &ran).ToCobra()
+	cmd.SetArgs([]string{"--clusters", "a,b,c", "--env", "staging"})
+	cmd.SetErr(&stderr)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Delete 3 clusters in staging? [y/N]: "; stderr.String() != want {
+		t.Errorf("expected %q, got %q", want, stderr.String())
+	}
+}
+
+func TestConfirm_YesSkipsQuestion(t *testing.T) {
+	for _, args := range [][]string{{"--yes"}, {"-y"}, {"--yes=true"}} {
+		ran := false
+		err := confirmCmd("", &ran).RunArgsE(append([]string{"--clusters", "a"}, args...))
+		if err != nil || !ran {
+			t.Errorf("%v: expected to run without asking, got ran=%v err=%v", args, ran, err)
+		}
+	}
+
+	ran := false
+	err := confirmCmd("", &ran).RunArgsE([]string{"--clusters", "a", "--yes=false"})
+	if ran || !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("--yes=false should still ask, got ran=%v err=%v", ran, err)
+	}
+}
+
+func TestConfirm_YesFromEnv(t *testing.T) {
+	t.Setenv("YES", "true")
+	ran := false
+	if err := confirmCmd("", &ran).RunArgsE([]string{"--clusters", "a"}); err != nil || !ran {
+		t.Fatalf("expected YES=true to skip the question, got ran=%v err=%v", ran, err)
+	}
+
+	t.Setenv("YES", "maybe")
+	ran = false
+	err := confirmCmd("", &ran).RunArgsE([]string{"--clusters", "a"})
+	if ran || err == nil || !strings.Contains(err.Error(), "YES") {
+		t.Errorf("expected invalid env error, got ran=%v err=%v", ran, err)
+	}
+}
+
+func TestConfirm_EnvPrefix(t *testing.T) {
+	t.Setenv("MYAPP_YES", "1")
+	ran := false
+	cmd := confirmCmd("", &ran)
+	cmd.ParamEnrich = ParamEnricherCombine(ParamEnricherDefault, ParamEnricherEnvPrefix("MYAPP"))
+	if err := cmd.RunArgsE([]string{"--clusters", "a"}); err != nil || !ran {
+		t.Fatalf("expected MYAPP_YES to skip the question, got ran=%v err=%v", ran, err)
+	}
+	if !strings.Contains(cmd.ToCobra().Flags().Lookup("yes").Usage, "env: MYAPP_YES") {
+		t.Errorf("expected help to mention MYAPP_YES")
+	}
+}
+
+func TestConfirm_NonInteractiveRefuses(t *testing.T) {
+	if isTerminal(os.Stdin) {
+		t.Skip("stdin is a terminal")
+	}
+	ran := false
+	cmd := confirmCmd("", &ran)
+	cmd.ConfirmIn = nil
+	err := cmd.RunArgsE([]string{"--clusters", "a"})
+	if ran || !errors.Is(err, ErrNotConfirmed) {
+		t.Fatalf("expected refusal, got ran=%v err=%v", ran, err)
+	}
+	if !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("expected hint about --yes, got %v", err)
+	}
+}
+
+func TestConfirm_RunsAfterPreExecute(t *testing.T) {
+	var order []string
+	err := (CmdT[NoParams]{
+		Use:       "test",
+		Confirm:   "Sure?",
+		ConfirmIn: strings.NewReader("y\n"),
+		PreExecuteFunc: func(p *NoParams, cmd *cobra.Command, args []string) error {
+			order = append(order, "pre-execute")
+			return nil
+		},
+		RunFunc: func(p *NoParams, cmd *cobra.Command, args []string) {
+			order = append(order, "run")
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(order, ",") != "pre-execute,run" {
+		t.Errorf("unexpected order: %v", order)
+	}
+}
+
+func TestConfirm_EmptyQuestionSkips(t *testing.T) {
+	ran := false
+	err := (CmdT[confirmParams]{
+		Use: "test",
+		ConfirmFunc: func(p *confirmParams) string {
+			if p.Env != "prod" {
+				return ""
+			}
+			return "Really touch prod?"
+		},
+		RunFunc: func(p *confirmParams, cmd *cobra.Command, args []string) { ran = true },
+	}).RunArgsE([]string{"--clusters", "a", "--env", "dev"})
+	if err != nil || !ran {
+		t.Fatalf("expected no confirmation outside prod, got ran=%v err=%v", ran, err)
+	}
+}
+
+func TestConfirm_ValidateDoesNotAsk(t *testing.T) {
+	ran := false
+	cmd := confirmCmd("", &ran)
+	cmd.ConfirmIn = nil
+	cmd.RawArgs = []string{"--clusters", "a", "--yes"}
+	if err := cmd.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cmd.RawArgs = []string{"--clusters", "a"}
+	if err := cmd.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ran {
+		t.Error("validate should not run the command")
+	}
+}
+
+func TestConfirm_FlagConflicts(t *testing.T) {
+	type Params struct {
+		Yank bool `short:"y" optional:"true"`
+	}
+	cmd := (CmdT[Params]{
+		Use:     "test",
+		Confirm: "Sure?",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).ToCobra()
+	yes := cmd.Flags().Lookup("yes")
+	if yes == nil || yes.Shorthand != "" {
+		t.Errorf("expected --yes without a short flag when -y is taken, got %+v", yes)
+	}
+
+	type Clash struct {
+		Yes bool `optional:"true"`
+	}
+	_, err := (CmdT[Clash]{
+		Use:     "test",
+		Confirm: "Sure?",
+		RunFunc: func(p *Clash, cmd *cobra.Command, args []string) {},
+	}).ToCobraE()
+	if err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("expected --yes conflict error, got %v", err)
+	}
+}
+
+func TestConfirm_NoFlagWithoutConfirm(t *testing.T) {
+	cmd := (CmdT[NoParams]{
+		Use:     "test",
+		RunFunc: func(p *NoParams, cmd *cobra.Command, args []string) {},
+	}).ToCobra()
+	if cmd.Flags().Lookup("yes") != nil {
+		t.Error("expected no --yes flag without Confirm")
+	}
+}
@@ -1870,7 +1870,7 @@ func TestLoadConfigFileExtensionLookup(t *testing.T) {
 	_ = tmpFile.Close()
 
 	var cfg Config
-	_, _, err := loadConfigFileInto(tmpFile.Name(), &cfg, ConfigFormat{})
+	_, _, err := loadConfigFileInto(nil, tmpFile.Name(), &cfg, ConfigFormat{})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
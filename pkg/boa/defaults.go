@@ -2,6 +2,8 @@ package boa
 
 type globalConfig struct {
 	defaultOptional bool
+	lookupEnv       func(key string) (string, bool)
+	environ         func() []string
 }
 
 var cfg globalConfig
@@ -24,3 +26,20 @@ func WithDefaultOptional() Option {
 		c.defaultOptional = true
 	}
 }
+
+// WithLookupEnv makes commands read env vars through lookupEnv instead of
+// os.LookupEnv, unless they set CmdT.LookupEnv.
+func WithLookupEnv(lookupEnv func(key string) (string, bool)) Option {
+	return func(c *globalConfig) {
+		c.lookupEnv = lookupEnv
+	}
+}
+
+// WithEnviron lists the environment that WithLookupEnv reads from, in
+// os.Environ's "key=value" form, for env vars found by prefix (the entries
+// of map-of-struct params). Commands that set CmdT.Environ use theirs.
+func WithEnviron(environ func() []string) Option {
+	return func(c *globalConfig) {
+		c.environ = environ
+	}
+}
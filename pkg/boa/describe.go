@@ -0,0 +1,230 @@
+package boa
+
+import (
+	"reflect"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"weak"
+
+	"github.com/spf13/cobra"
+)
+
+// ConfigSearchPathsAnnotation is the cobra annotation key under which
+// config discovery helpers (e.g. boaviper.AutoConfig) record the config
+// file candidates they try, newline separated and in priority order.
+// Describe reports them as CommandInfo.ConfigSearchPaths.
+const ConfigSearchPathsAnnotation = "boa/config-search-paths"
+
+// CommandInfo describes a command built by boa, for documentation and
+// tooling (see Describe). Commands not built by boa are described from
+// their cobra fields only.
+type CommandInfo struct {
+	// Name is the command name and Path the full command path, e.g.
+	// "deploy" and "app deploy".
+	Name string
+	Path string
+	// Use, Short and Long are the cobra usage line and descriptions.
+	Use   string
+	Short string
+	Long  string
+	// Example is the Example text followed by the invocation built from
+	// `example` tags, as shown in help.
+	Example string
+	Aliases []string
+	// Runnable is false for commands that only group subcommands.
+	Runnable bool
+	// Params are the command's parameters in declaration order, excluding
+	// ignored ones.
+	Params []ParamInfo
+	// HasConfigFile reports whether the command loads a config file (has a
+	// `configfile` param). Only then are ParamInfo.ConfigKey set.
+	HasConfigFile bool
+	// ConfigSearchPaths are the config files looked for when none is given,
+	// as recorded under ConfigSearchPathsAnnotation.
+	ConfigSearchPaths []string
+	// ConfirmFlag and ConfirmEnv are the flag and env var that skip the
+	// confirmation prompt, for commands with Confirm / ConfirmFunc; Confirm
+	// reports whether there is one at all.
+	Confirm     bool
+	ConfirmFlag string
+	ConfirmEnv  string
+	// Commands are the available (non-hidden) subcommands.
+	Commands []CommandInfo
+}
+
+// ParamInfo describes one parameter of a command (see Describe).
+type ParamInfo struct {
+	// Name is the flag name (without dashes) and Short its shorthand.
+	Name  string
+	Short string
+	// Env is the env var the param is read from, or "" if none.
+	Env string
+	// ConfigKey is the dotted config-file key, e.g. "db.host", when the
+	// command loads a config file.
+	ConfigKey string
+	// Type is the Go type of the value, e.g. "int" or "[]string", and
+	// ValueType the type itself.
+	Type        string
+	ValueType   reflect.Type
+	Description string
+	// Default is the default value as text; HasDefault tells an empty
+	// default from none.
+	Default    string
+	HasDefault bool
+	// Required reports whether a value must be given (required without a
+	// default). Conditional reports that the param is only enabled under
+	// some condition, so Required may not always apply.
+	Required    bool
+	Conditional bool
+	Positional  bool
+	RestArgs    bool
+	// NoFlag reports params without a CLI flag (env / config only).
+	NoFlag     bool
+	ConfigFile bool
+	Secret     bool
+	// Alternatives are the allowed values; StrictAlts whether other values
+	// are rejected (otherwise they are only completion suggestions).
+	Alternatives []string
+	StrictAlts   bool
+	// Min and Max are the bounds as text, or "" if unbounded. They bound
+	// the length rather than the value when LengthBounds is set.
+	Min          string
+	Max          string
+	LengthBounds bool
+	Pattern      string
+	Transforms   []string
+	Example      string
+}
+
+// describeRegistry maps each cobra command built by boa to its processing
+// context. Both sides are weak: the command keeps its context alive through
+// its closures, and the entry is dropped when the command is collected.
+var describeRegistry sync.Map // weak.Pointer[cobra.Command] -> weak.Pointer[processingContext]
+
+func registerDescribe(cmd *cobra.Command, ctx *processingContext) {
+	key := weak.Make(cmd)
+	describeRegistry.Store(key, weak.Make(ctx))
+	runtime.AddCleanup(cmd, func(key weak.Pointer[cobra.Command]) {
+		describeRegistry.Delete(key)
+	}, key)
+}
+
+func lookupDescribe(cmd *cobra.Command) *processingContext {
+	v, ok := describeRegistry.Load(weak.Make(cmd))
+	if !ok {
+		return nil
+	}
+	return v.(weak.Pointer[processingContext]).Value()
+}
+
+// Describe returns what boa knows about cmd and its subcommands: params
+// with their flags, env vars, config keys, defaults and validation rules.
+// It is meant for generating documentation (see the boadoc package) and
+// other tooling, and does not run or modify the command.
+func Describe(cmd *cobra.Command) CommandInfo {
+	info := CommandInfo{
+		Name:     cmd.Name(),
+		Path:     cmd.CommandPath(),
+		Use:      cmd.UseLine(),
+		Short:    cmd.Short,
+		Long:     cmd.Long,
+		Example:  cmd.Example,
+		Aliases:  slices.Clone(cmd.Aliases),
+		Runnable: cmd.Runnable(),
+	}
+	if paths := cmd.Annotations[ConfigSearchPathsAnnotation]; paths != "" {
+		info.ConfigSearchPaths = strings.Split(paths, "\n")
+	}
+
+	if ctx := lookupDescribe(cmd); ctx != nil {
+		info.Example = joinExamples(cmd.Example, exampleInvocation(cmd, ctx))
+		info.HasConfigFile = len(ctx.ConfigFiles) > 0
+		info.Runnable = info.Runnable && !ctx.groupOnly
+		tag := structTagForExt(configFileExt(ctx))
+		for _, param := range helpParams(ctx) {
+			pi := describeParam(param)
+			if info.HasConfigFile && !param.IsConfigFile() && !param.IsRestArgs() {
+				pi.ConfigKey, _ = configKeyFor(ctx, param, tag)
+			}
+			info.Params = append(info.Params, pi)
+		}
+		if ctx.confirm != nil {
+			info.Confirm = true
+			info.ConfirmFlag = ctx.confirm.flag
+			info.ConfirmEnv = ctx.confirm.env
+		}
+	}
+
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			info.Commands = append(info.Commands, Describe(sub))
+		}
+	}
+	return info
+}
+
+func describeParam(param Param) ParamInfo {
+	pi := ParamInfo{
+		Name:         param.GetName(),
+		Short:        param.GetShort(),
+		Type:         param.GetType().String(),
+		ValueType:    param.GetType(),
+		Description:  param.getDescr(),
+		HasDefault:   param.hasDefaultValue(),
+		Required:     param.IsRequired() && !param.hasDefaultValue(),
+		Conditional:  param.GetIsEnabledFn() != nil,
+		Positional:   param.isPositional(),
+		RestArgs:     param.IsRestArgs(),
+		NoFlag:       param.IsNoFlag(),
+		ConfigFile:   param.IsConfigFile(),
+		Secret:       param.IsSecret(),
+		Alternatives: slices.Clone(param.GetAlternatives()),
+		Pattern:      param.GetPattern(),
+		Transforms:   param.GetTransforms(),
+		Example:      param.GetExample(),
+	}
+	if !param.IsNoEnv() {
+		pi.Env = param.GetEnv()
+	}
+	if pi.HasDefault {
+		pi.Default = param.defaultValueStr()
+	}
+	if len(pi.Alternatives) > 0 {
+		pi.StrictAlts = param.GetStrictAlts()
+	}
+	if pm, ok := param.(*paramMeta); ok {
+		pi.Min = pm.describeBound(pm.minVal)
+		pi.Max = pm.describeBound(pm.maxVal)
+		pi.LengthBounds = pm.boundKind() == lengthBound
+	}
+	return pi
+}
+
+// describeBound renders a min/max bound for documentation. Relative time
+// bounds keep their expression ("now+30d") so the output is stable.
+func (f *paramMeta) describeBound(v any) string {
+	if v == nil {
+		return ""
+	}
+	if tb, ok := v.(*timeBoundValue); ok {
+		if tb == nil {
+			return ""
+		}
+		if tb.relative {
+			return tb.expr
+		}
+		return f.timeFormat().format(tb.at)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+		if _, isBig := v.(interface{ Text(byte, int) string }); !isBig {
+			rv = rv.Elem()
+		}
+	}
+	return f.formatBound(rv.Interface())
+}
@@ -0,0 +1,136 @@
+package boa
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+	"weak"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDescribe(t *testing.T) {
+	type DB struct {
+		Host string `descr:"db host" json:"host" default:"localhost"`
+	}
+	type Params struct {
+		ConfigFile string        `configfile:"true" optional:"true"`
+		Port       int           `descr:"port" short:"p" env:"PORT" min:"1" max:"65535"`
+		Name       string        `descr:"name" pattern:"^[a-z]+$" min:"2" transform:"trim" example:"bob" optional:"true"`
+		Level      string        `alts:"debug,info" default:"info"`
+		Hint       string        `alts:"a,b" strict:"false" optional:"true"`
+		Token      string        `env:"TOKEN" boa:"noflag" secret:"true" optional:"true"`
+		Quiet      bool          `env:"QUIET" boa:"noenv"`
+		Timeout    time.Duration `min:"1s" optional:"true"`
+		Since      time.Time     `min:"now-7d" optional:"true"`
+		DB         DB            `json:"db"`
+		Ignored    string        `boa:"ignore"`
+		Files      []string      `positional:"true" optional:"true"`
+	}
+
+	cmd := (CmdT[NoParams]{
+		Use:   "app",
+		Short: "the app",
+		SubCmds: SubCmds(CmdT[Params]{
+			Use:     "run",
+			Short:   "run it",
+			Confirm: "Sure?",
+			RunFunc: func(*Params, *cobra.Command, []string) {},
+		}, CmdT[NoParams]{
+			Use:     "secret",
+			RunFunc: func(*NoParams, *cobra.Command, []string) {},
+		}),
+	}).ToCobra()
+	cmd.Commands()[1].Hidden = true
+
+	info := Describe(cmd)
+	if info.Path != "app" || info.Short != "the app" || len(info.Params) != 0 {
+		t.Errorf("unexpected root info: %+v", info)
+	}
+	if len(info.Commands) != 1 {
+		t.Fatalf("expected only the visible subcommand, got %d", len(info.Commands))
+	}
+	run := info.Commands[0]
+	if run.Path != "app run" || run.Use != "app run [files...] [flags]" || !run.HasConfigFile || !run.Confirm || run.ConfirmFlag != "yes" || run.ConfirmEnv != "YES" {
+		t.Errorf("unexpected run info: %+v", run)
+	}
+	if run.Example != "  app run --name bob" {
+		t.Errorf("unexpected example %q", run.Example)
+	}
+
+	byName := map[string]ParamInfo{}
+	var names []string
+	for _, p := range run.Params {
+		byName[p.Name] = p
+		names = append(names, p.Name)
+	}
+	wantNames := []string{"config-file", "port", "name", "level", "hint", "token", "quiet", "timeout", "since", "db-host", "files"}
+	if !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("expected params %v, got %v", wantNames, names)
+	}
+
+	port := byName["port"]
+	if port.Short != "p" || port.Env != "PORT" || port.ConfigKey != "Port" || port.Type != "int" || port.ValueType != reflect.TypeOf(0) || !port.Required || port.Min != "1" || port.Max != "65535" || port.LengthBounds {
+		t.Errorf("unexpected port info: %+v", port)
+	}
+	name := byName["name"]
+	if name.Required || name.Pattern != "^[a-z]+$" || name.Min != "2" || !name.LengthBounds || !reflect.DeepEqual(name.Transforms, []string{"trim"}) || name.Example != "bob" {
+		t.Errorf("unexpected name info: %+v", name)
+	}
+	level := byName["level"]
+	if !level.HasDefault || level.Default != "info" || level.Required || !level.StrictAlts || !reflect.DeepEqual(level.Alternatives, []string{"debug", "info"}) {
+		t.Errorf("unexpected level info: %+v", level)
+	}
+	if byName["hint"].StrictAlts {
+		t.Errorf("expected non-strict alts for hint")
+	}
+	if token := byName["token"]; !token.NoFlag || !token.Secret || token.Env != "TOKEN" {
+		t.Errorf("unexpected token info: %+v", token)
+	}
+	if quiet := byName["quiet"]; quiet.Env != "" {
+		t.Errorf("expected noenv param without env, got %+v", quiet)
+	}
+	if byName["timeout"].Min != "1s" || byName["since"].Min != "now-7d" {
+		t.Errorf("unexpected time bounds: %q %q", byName["timeout"].Min, byName["since"].Min)
+	}
+	if db := byName["db-host"]; db.ConfigKey != "db.host" || db.Default != "localhost" {
+		t.Errorf("unexpected db host info: %+v", db)
+	}
+	if cf := byName["config-file"]; !cf.ConfigFile || cf.ConfigKey != "" {
+		t.Errorf("unexpected config file info: %+v", cf)
+	}
+	if files := byName["files"]; !files.Positional || files.ConfigKey != "Files" {
+		t.Errorf("unexpected files info: %+v", files)
+	}
+}
+
+func TestDescribe_PlainCobraCommand(t *testing.T) {
+	cmd := &cobra.Command{Use: "plain", Short: "plain cobra", Annotations: map[string]string{
+		ConfigSearchPathsAnnotation: "./plain.json\n/etc/plain/config.json",
+	}}
+	info := Describe(cmd)
+	if info.Path != "plain" || info.Params != nil || info.HasConfigFile {
+		t.Errorf("unexpected info: %+v", info)
+	}
+	if !reflect.DeepEqual(info.ConfigSearchPaths, []string{"./plain.json", "/etc/plain/config.json"}) {
+		t.Errorf("unexpected search paths: %v", info.ConfigSearchPaths)
+	}
+}
+
+func TestDescribe_RegistryDropsCollectedCommands(t *testing.T) {
+	cmd := (CmdT[NoParams]{Use: "x", RunFunc: func(*NoParams, *cobra.Command, []string) {}}).ToCobra()
+	key := weak.Make(cmd)
+	if _, ok := describeRegistry.Load(key); !ok {
+		t.Fatal("expected command to be registered")
+	}
+	cmd = nil
+	for i := 0; i < 20; i++ {
+		runtime.GC()
+		if _, ok := describeRegistry.Load(key); !ok {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Error("expected registry entry to be dropped after the command was collected")
+}
@@ -0,0 +1,210 @@
+package boa
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// EnumDef describes a named-constant type for RegisterEnumDef.
+type EnumDef[T comparable] struct {
+	// Values maps each canonical name to its value. The canonical name is
+	// what help, shell completion, DumpBytes and config files show, so each
+	// value must have exactly one.
+	Values map[string]T
+	// Aliases are extra names accepted when parsing, e.g. {"warning": Warn}.
+	Aliases map[string]T
+	// CaseSensitive disables the default case-insensitive matching.
+	CaseSensitive bool
+}
+
+// enumInfo is the type-erased part of a registered enum that the rest of
+// boa needs: the names (for alternatives) and value → name (for strict-alts
+// checks).
+type enumInfo struct {
+	names  []string
+	nameOf func(v reflect.Value) (string, bool)
+}
+
+// enumTypes holds every type registered with RegisterEnum / RegisterEnumDef.
+var enumTypes = map[reflect.Type]*enumInfo{}
+
+// RegisterEnum registers T as an enum parsed from the given names, so a
+// field of type T (or *T, []T) is set with e.g. `--level warn`. Matching is
+// case-insensitive. See RegisterEnumDef for aliases and case-sensitive enums.
+//
+// Example:
+//
+//	type LogLevel int
+//
+//	const (
+//	    Debug LogLevel = iota
+//	    Info
+//	    Warn
+//	)
+//
+//	boa.RegisterEnum(map[string]LogLevel{"debug": Debug, "info": Info, "warn": Warn})
+func RegisterEnum[T comparable](values map[string]T) {
+	RegisterEnumDef(EnumDef[T]{Values: values})
+}
+
+// RegisterEnumDef registers T as an enum described by def. The names become
+// the param's alternatives (shown in help and offered by shell completion),
+// values are validated while parsing, and DumpBytes and config files use
+// the canonical names. Config files may also give the underlying value.
+// Panics if def is inconsistent: no values, a value with two names, or two
+// names that only differ in case on a case-insensitive enum.
+func RegisterEnumDef[T comparable](def EnumDef[T]) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if len(def.Values) == 0 {
+		panic(fmt.Errorf("boa: RegisterEnumDef[%s]: no values", t))
+	}
+
+	key := func(name string) string {
+		if def.CaseSensitive {
+			return name
+		}
+		return strings.ToLower(name)
+	}
+	nameOf := make(map[T]string, len(def.Values))
+	lookup := make(map[string]T, len(def.Values)+len(def.Aliases))
+	add := func(name string, v T) {
+		if prev, ok := lookup[key(name)]; ok && prev != v {
+			panic(fmt.Errorf("boa: RegisterEnumDef[%s]: name %q is ambiguous", t, name))
+		}
+		lookup[key(name)] = v
+	}
+	for name, v := range def.Values {
+		if other, ok := nameOf[v]; ok {
+			panic(fmt.Errorf("boa: RegisterEnumDef[%s]: value %v has two names, %q and %q — use Aliases for extra names", t, v, other, name))
+		}
+		nameOf[v] = name
+		add(name, v)
+	}
+	for name, v := range def.Aliases {
+		if _, ok := nameOf[v]; !ok {
+			panic(fmt.Errorf("boa: RegisterEnumDef[%s]: alias %q refers to a value with no name", t, name))
+		}
+		add(name, v)
+	}
+
+	names := make([]string, 0, len(def.Values))
+	for name := range def.Values {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if c := compareEnumValues(reflect.ValueOf(def.Values[a]), reflect.ValueOf(def.Values[b])); c != 0 {
+			return c
+		}
+		return strings.Compare(a, b)
+	})
+
+	parse := func(s string) (reflect.Value, error) {
+		v, ok := lookup[key(strings.TrimSpace(s))]
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("invalid value %q (expected one of: %s)", s, strings.Join(names, ", "))
+		}
+		return reflect.ValueOf(&v), nil
+	}
+	format := func(v reflect.Value) string {
+		if name, ok := nameOf[v.Interface().(T)]; ok {
+			return name
+		}
+		// Unnamed value (e.g. an unset zero value): show the plain number
+		// rather than going through the type's own String method
+		if h, ok := kindHandlers[v.Kind()]; ok {
+			v = v.Convert(h.baseType)
+		}
+		return fmt.Sprint(v.Interface())
+	}
+
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	setConfigText(t, parse, format)
+	exactTypeHandlers[t].bindFlag = enumBindFlag(strings.Join(names, "|"), format)
+	enumTypes[t] = &enumInfo{
+		names: names,
+		nameOf: func(v reflect.Value) (string, bool) {
+			name, ok := nameOf[v.Interface().(T)]
+			return name, ok
+		},
+	}
+}
+
+// compareEnumValues orders enum values by their underlying number or
+// string, so help lists e.g. debug, info, warn, error in declaration order.
+// Other kinds compare equal (and fall back to name order).
+func compareEnumValues(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float())
+	case reflect.String:
+		return cmp.Compare(a.String(), b.String())
+	}
+	return 0
+}
+
+// enumFlag is a string flag whose type placeholder in help lists the enum's
+// names: `--level debug|info|warn|error`.
+type enumFlag struct {
+	value    string
+	typeName string
+}
+
+func (f *enumFlag) String() string     { return f.value }
+func (f *enumFlag) Set(s string) error { f.value = s; return nil }
+func (f *enumFlag) Type() string       { return f.typeName }
+
+// enumBindFlag binds an enum param as an enumFlag. Like any string-backed
+// type, the raw string is converted (and validated) after parsing.
+func enumBindFlag(typeName string, format func(reflect.Value) string) func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
+	return func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
+		flag := &enumFlag{typeName: typeName}
+		if defaultVal != nil {
+			if v := reflect.ValueOf(defaultVal); v.Kind() == reflect.Pointer && !v.IsNil() {
+				flag.value = format(v.Elem())
+			}
+		}
+		cmd.Flags().VarP(flag, name, short, descr)
+		return &flag.value
+	}
+}
+
+// enumNames returns the names of the enum behind a param type (T, *T, []T
+// or []*T), or nil if it isn't one.
+func enumNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	if e, ok := enumTypes[t]; ok {
+		return slices.Clone(e.names)
+	}
+	return nil
+}
+
+// altString renders a param value for comparison against its alternatives:
+// the canonical name for enums, %v for everything else.
+func altString(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	handlersMu.RLock()
+	e, ok := enumTypes[v.Type()]
+	handlersMu.RUnlock()
+	if ok {
+		if name, ok := e.nameOf(v); ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
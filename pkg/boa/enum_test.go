@@ -0,0 +1,220 @@
+package boa
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type testLogLevel int
+
+const (
+	testDebug testLogLevel = iota
+	testInfo
+	testWarn
+	testError
+)
+
+// String deliberately differs from the enum names, to show boa uses the
+// registered names rather than the type's own formatting.
+func (l testLogLevel) String() string { return "LEVEL" }
+
+type testShape string
+
+func init() {
+	RegisterEnumDef(EnumDef[testLogLevel]{
+		Values:  map[string]testLogLevel{"debug": testDebug, "info": testInfo, "warn": testWarn, "error": testError},
+		Aliases: map[string]testLogLevel{"warning": testWarn, "err": testError},
+	})
+	RegisterEnumDef(EnumDef[testShape]{
+		Values:        map[string]testShape{"Circle": "circle", "Square": "square"},
+		CaseSensitive: true,
+	})
+}
+
+func TestEnum_ParsesNamesAndAliases(t *testing.T) {
+	type Params struct {
+		Level  testLogLevel            `default:"info" descr:"log level"`
+		Shape  *testShape              `optional:"true"`
+		Levels []testLogLevel          `optional:"true"`
+		Ptrs   []*testLogLevel         `optional:"true"`
+		Mods   map[string]testLogLevel `optional:"true"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--level", "WARNING", "--shape", "Square", "--levels", "debug,Err", "--ptrs", "info", "--mods", "db=debug,http=warn"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Level != testWarn {
+		t.Errorf("expected warn, got %d", got.Level)
+	}
+	if got.Shape == nil || *got.Shape != "square" {
+		t.Errorf("expected square, got %v", got.Shape)
+	}
+	if !reflect.DeepEqual(got.Levels, []testLogLevel{testDebug, testError}) {
+		t.Errorf("unexpected levels: %v", got.Levels)
+	}
+	if len(got.Ptrs) != 1 || *got.Ptrs[0] != testInfo {
+		t.Errorf("unexpected ptrs: %v", got.Ptrs)
+	}
+	if !reflect.DeepEqual(got.Mods, map[string]testLogLevel{"db": testDebug, "http": testWarn}) {
+		t.Errorf("unexpected mods: %v", got.Mods)
+	}
+}
+
+func TestEnum_RejectsUnknownNames(t *testing.T) {
+	type Params struct {
+		Level testLogLevel `optional:"true"`
+		Shape testShape    `optional:"true"`
+	}
+	run := func(args ...string) error {
+		return (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(args)
+	}
+
+	err := run("--level", "verbose")
+	if err == nil || !IsUserInputError(err) || !strings.Contains(err.Error(), `invalid value "verbose" (expected one of: debug, info, warn, error)`) {
+		t.Errorf("expected unknown-name error, got %v", err)
+	}
+	if err := run("--shape", "circle"); err == nil {
+		t.Error("expected case-sensitive enum to reject a lower-case name")
+	}
+}
+
+func TestEnum_Alternatives(t *testing.T) {
+	type Params struct {
+		Level testLogLevel `optional:"true"`
+		Mode  testLogLevel `optional:"true" alts:"warn,error"`
+	}
+
+	var levelAlts, modeAlts []string
+	cmd := CmdT[Params]{
+		Use: "test",
+		RunFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command, args []string) {
+			levelAlts = ctx.GetParam(&p.Level).GetAlternatives()
+			modeAlts = ctx.GetParam(&p.Mode).GetAlternatives()
+		},
+	}
+	if err := cmd.RunArgsE([]string{"--mode", "error"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(levelAlts, []string{"debug", "info", "warn", "error"}) {
+		t.Errorf("expected alternatives in value order, got %v", levelAlts)
+	}
+	if !reflect.DeepEqual(modeAlts, []string{"warn", "error"}) {
+		t.Errorf("expected explicit alts to win, got %v", modeAlts)
+	}
+
+	// Strict alts compare by name, not by the type's String method
+	err := cmd.RunArgsE([]string{"--mode", "info"})
+	if err == nil || !strings.Contains(err.Error(), "'info' is not in the list of allowed values") {
+		t.Errorf("expected strict alts error, got %v", err)
+	}
+
+	cobraCmd := cmd.ToCobra()
+	completions, _ := cobraCmd.GetFlagCompletionFunc("level")
+	if completions == nil {
+		t.Fatal("expected a completion func for --level")
+	}
+	comps, _ := completions(cobraCmd, nil, "")
+	if !reflect.DeepEqual(comps, []string{"debug", "info", "warn", "error"}) {
+		t.Errorf("unexpected completions: %v", comps)
+	}
+}
+
+func TestEnum_Help(t *testing.T) {
+	type Params struct {
+		Level testLogLevel `default:"warn" descr:"log level"`
+	}
+	usage := captureUsage(t, CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	})
+	if !strings.Contains(usage, "--level debug|info|warn|error") || !strings.Contains(usage, "(default warn)") {
+		t.Errorf("expected enum names in help:\n%s", usage)
+	}
+}
+
+func TestEnum_ConfigAndDump(t *testing.T) {
+	type Params struct {
+		ConfigFile string         `configfile:"true" optional:"true"`
+		Level      testLogLevel   `optional:"true"`
+		Numeric    testLogLevel   `optional:"true"`
+		Levels     []testLogLevel `optional:"true"`
+	}
+
+	path := writeTestConfigFile(t, `{"Level":"Warning","Numeric":3,"Levels":["info","debug"]}`)
+	var got Params
+	var dumped []byte
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+			var err error
+			if dumped, err = ctx.DumpBytes(".json", nil); err != nil {
+				t.Fatalf("DumpBytes: %v", err)
+			}
+		},
+	}).RunArgsE([]string{"--config-file", path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Level != testWarn || got.Numeric != testError || !reflect.DeepEqual(got.Levels, []testLogLevel{testInfo, testDebug}) {
+		t.Errorf("unexpected config values: %+v", got)
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(dumped, &tree); err != nil {
+		t.Fatalf("parse dump: %v", err)
+	}
+	if tree["Level"] != "warn" || tree["Numeric"] != "error" || !reflect.DeepEqual(tree["Levels"], []any{"info", "debug"}) {
+		t.Errorf("expected names in dump, got %s", dumped)
+	}
+
+	bad := writeTestConfigFile(t, `{"Level":"loud"}`)
+	err = (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--config-file", bad})
+	if err == nil || !strings.Contains(err.Error(), `field Level: invalid value "loud"`) {
+		t.Errorf("expected config enum error, got %v", err)
+	}
+}
+
+func TestRegisterEnumDef_Panics(t *testing.T) {
+	type dup int
+	type folded string
+	cases := map[string]func(){
+		"empty": func() { RegisterEnum(map[string]dup{}) },
+		"two names": func() {
+			RegisterEnum(map[string]dup{"a": 1, "b": 1})
+		},
+		"case clash": func() {
+			RegisterEnum(map[string]folded{"A": "x", "a": "y"})
+		},
+		"dangling alias": func() {
+			RegisterEnumDef(EnumDef[dup]{Values: map[string]dup{"a": 1}, Aliases: map[string]dup{"b": 2}})
+		},
+	}
+	for name, fn := range cases {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			fn()
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package boa
+
+import (
+	"errors"
+	"os"
+	"runtime"
+
+	"github.com/spf13/cobra"
+)
+
+// envSource reads environment variables for a command: the process's, or
+// those of Cmd.LookupEnv / WithLookupEnv.
+type envSource interface {
+	lookupEnv(key string) (string, bool)
+	environ() ([]string, error)
+}
+
+var (
+	_ envSource = &processingContext{}
+	_ envSource = processEnv{}
+)
+
+// processEnv is the environment commands read without a processingContext.
+type processEnv struct{}
+
+func (processEnv) lookupEnv(key string) (string, bool) { return lookupEnvDefault(key) }
+
+func (processEnv) environ() ([]string, error) {
+	switch {
+	case cfg.environ != nil:
+		return cfg.environ(), nil
+	case cfg.lookupEnv != nil:
+		return nil, errNoEnviron
+	}
+	return os.Environ(), nil
+}
+
+// errNoEnviron is returned when env vars have to be listed but only a
+// LookupEnv is known, rather than silently listing none.
+var errNoEnviron = errors.New("a LookupEnv can't be listed, set Environ (or WithEnviron) as well")
+
+// lookupEnvDefault is the lookup used when no command sets LookupEnv.
+func lookupEnvDefault(key string) (string, bool) {
+	if cfg.lookupEnv != nil {
+		return cfg.lookupEnv(key)
+	}
+	return os.LookupEnv(key)
+}
+
+// LookupEnv reads an environment variable the way cmd does (see
+// CmdT.LookupEnv), for hooks and helpers that only have the cobra command.
+// For commands not built by boa it is os.LookupEnv, or the WithLookupEnv
+// function.
+func LookupEnv(cmd *cobra.Command, key string) (string, bool) {
+	for c := cmd; c != nil; c = c.Parent() {
+		if ctx := lookupDescribe(c); ctx != nil {
+			return ctx.lookupEnv(key)
+		}
+	}
+	return lookupEnvDefault(key)
+}
+
+// inherited returns ctx, or once the command is attached to its parents
+// the nearest ancestor's context, for which has is true; nil if none.
+func (ctx *processingContext) inherited(has func(*processingContext) bool) *processingContext {
+	if has(ctx) {
+		return ctx
+	}
+	if ctx.cmd == nil {
+		return nil
+	}
+	for c := ctx.cmd.Parent(); c != nil; c = c.Parent() {
+		if pctx := lookupDescribe(c); pctx != nil && has(pctx) {
+			return pctx
+		}
+	}
+	return nil
+}
+
+// lookupEnvFn returns the Cmd.LookupEnv that applies: the command's own
+// or the nearest ancestor's.
+func (ctx *processingContext) lookupEnvFn() func(string) (string, bool) {
+	if c := ctx.inherited(func(c *processingContext) bool { return c.lookupEnvFunc != nil }); c != nil {
+		return c.lookupEnvFunc
+	}
+	return nil
+}
+
+// environFn returns the Cmd.Environ that applies: that of the command, or
+// of the nearest ancestor, setting LookupEnv or Environ, so the listing
+// always goes with the lookup it lists.
+func (ctx *processingContext) environFn() func() []string {
+	if c := ctx.inherited(func(c *processingContext) bool { return c.lookupEnvFunc != nil || c.environFunc != nil }); c != nil {
+		return c.environFunc
+	}
+	return nil
+}
+
+func (ctx *processingContext) lookupEnv(key string) (string, bool) {
+	if fn := ctx.lookupEnvFn(); fn != nil {
+		return fn(key)
+	}
+	return lookupEnvDefault(key)
+}
+
+// environ lists the environment for env vars that are found by prefix. A
+// LookupEnv can't be listed, so it is an error when one applies without an
+// Environ.
+func (ctx *processingContext) environ() ([]string, error) {
+	if fn := ctx.environFn(); fn != nil {
+		return fn(), nil
+	}
+	if ctx.lookupEnvFn() != nil {
+		return nil, errNoEnviron
+	}
+	return processEnv{}.environ()
+}
+
+// homeDir is os.UserHomeDir reading env.
+func homeDir(env envSource) (string, error) {
+	key := "HOME"
+	switch runtime.GOOS {
+	case "windows":
+		key = "USERPROFILE"
+	case "plan9":
+		key = "home"
+	}
+	if home, _ := env.lookupEnv(key); home != "" {
+		return home, nil
+	}
+	return "", errors.New("$" + key + " is not defined")
+}
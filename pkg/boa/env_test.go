@@ -0,0 +1,127 @@
+package boa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func mapLookup(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	}
+}
+
+func TestLookupEnv_Cmd(t *testing.T) {
+	type params struct {
+		Name string `env:"APP_NAME"`
+		Dir  string `env:"APP_DIR" transform:"expand-home"`
+		Path string `default:"$ROOT/bin" transform:"expand-env"`
+	}
+	t.Setenv("APP_NAME", "from-process")
+	env := map[string]string{"APP_NAME": "injected", "APP_DIR": "~/work", "HOME": "/home/me", "ROOT": "/opt", "YES": "true"}
+	var fromHook string
+	p := &params{}
+	err := (CmdT[params]{
+		Use:       "app",
+		Params:    p,
+		LookupEnv: mapLookup(env),
+		Confirm:   "Sure?",
+		PreExecuteFuncCtx: func(ctx *HookContext, _ *params, cmd *cobra.Command, _ []string) error {
+			fromHook, _ = ctx.LookupEnv("ROOT")
+			return nil
+		},
+		RunFunc: func(*params, *cobra.Command, []string) {},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "injected" || p.Dir != "/home/me/work" || p.Path != "/opt/bin" || fromHook != "/opt" {
+		t.Errorf("expected every env read to go through LookupEnv, got %+v and %q", p, fromHook)
+	}
+}
+
+func TestLookupEnv_InheritedAndReloaded(t *testing.T) {
+	type params struct {
+		Port int `env:"APP_PORT"`
+	}
+	env := map[string]string{"APP_PORT": "1"}
+	var first, reloaded int
+	var viaCobra string
+	root := (CmdT[NoParams]{
+		Use:       "app",
+		LookupEnv: mapLookup(env),
+		SubCmds: SubCmds(CmdT[params]{
+			Use: "serve",
+			RunFuncCtxE: func(ctx *HookContext, p *params, cmd *cobra.Command, _ []string) error {
+				first = p.Port
+				viaCobra, _ = LookupEnv(cmd, "APP_PORT")
+				env["APP_PORT"] = "2"
+				fresh, err := Reload[params](ctx)
+				if err != nil {
+					return err
+				}
+				reloaded = fresh.Port
+				return nil
+			},
+		}),
+	}).ToCobra()
+	root.SetArgs([]string{"serve"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if first != 1 || viaCobra != "1" || reloaded != 2 {
+		t.Errorf("expected the root's LookupEnv for the run and the reload, got %d, %q, %d", first, viaCobra, reloaded)
+	}
+}
+
+func TestLookupEnv_Environ(t *testing.T) {
+	type db struct {
+		Host string
+	}
+	type params struct {
+		DBs map[string]db `env:"APP_DBS" optional:"true"`
+	}
+	env := map[string]string{"APP_DBS_MAIN_HOST": "injected"}
+	t.Setenv("APP_DBS_OTHER_HOST", "from-process")
+
+	run := func(environ func() []string) (map[string]db, error) {
+		p := &params{}
+		err := (CmdT[params]{
+			Use:       "app",
+			Params:    p,
+			LookupEnv: mapLookup(env),
+			Environ:   environ,
+			RunFunc:   func(*params, *cobra.Command, []string) {},
+		}).RunArgsE([]string{})
+		return p.DBs, err
+	}
+	got, err := run(func() []string { return []string{"APP_DBS_MAIN_HOST=injected"} })
+	if err != nil || len(got) != 1 || got["main"].Host != "injected" {
+		t.Errorf("expected map entries listed through Environ, got %+v, %v", got, err)
+	}
+	if _, err := run(nil); err == nil || !strings.Contains(err.Error(), "set Environ") {
+		t.Errorf("expected an error rather than silently ignoring the entries, got %v", err)
+	}
+}
+
+func TestWithLookupEnv(t *testing.T) {
+	defer func(old globalConfig) { cfg = old }(cfg)
+	Init(WithLookupEnv(mapLookup(map[string]string{"APP_NAME": "global"})))
+
+	type params struct {
+		Name string `env:"APP_NAME"`
+	}
+	p := &params{}
+	if err := (CmdT[params]{Use: "app", Params: p, RunFunc: func(*params, *cobra.Command, []string) {}}).RunArgsE([]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "global" {
+		t.Errorf("expected the global LookupEnv, got %q", p.Name)
+	}
+	if v, _ := LookupEnv(&cobra.Command{}, "APP_NAME"); v != "global" {
+		t.Errorf("expected plain cobra commands to use it too, got %q", v)
+	}
+}
@@ -0,0 +1,268 @@
+package boa
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// errorFormatFlag is the name of the flag added by Cmd.ErrorFormatFlag.
+const errorFormatFlag = "error-format"
+
+// ValidationError is a param value failing validation: a missing required
+// param or positional arg, or a value rejected by alts, min/max, pattern, a
+// transform or a custom validator. Its message is the underlying error's.
+type ValidationError struct {
+	// Param is the param's name, i.e. its flag without dashes.
+	Param string
+	// Env is the param's env var, if it has one.
+	Env string
+	// Positional is set for positional args.
+	Positional bool
+	Err        error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorReport is what an ErrorRenderer renders: a failed command's error
+// along with what boa knows about it.
+type ErrorReport struct {
+	// Cmd is the command that failed.
+	Cmd *cobra.Command
+	// Err is the error as returned, e.g. a *ValidationError or a pflag error.
+	Err error
+	// Code is the exit code Run exits with.
+	Code int
+	// Param is the offending param or flag without dashes, if known.
+	Param string
+	// ShowUsage is false when usage wouldn't help, e.g. for run function
+	// errors with CleanRunErrors set.
+	ShowUsage bool
+	// Color reports whether the output is a terminal and NO_COLOR isn't set.
+	Color bool
+	// Format is the value of --error-format ("text" or "json"), or "" if
+	// it wasn't given (see Cmd.ErrorFormatFlag).
+	Format string
+}
+
+// ErrorRenderer prints a failed command's error to w, the command's
+// ErrOrStderr when used by Run and Execute. See CmdT.ErrorRenderer and
+// SetDefaultErrorRenderer.
+type ErrorRenderer func(w io.Writer, r *ErrorReport)
+
+// UsageErrorRenderer prints the command's usage, then "Error: <message>".
+// It is the default.
+func UsageErrorRenderer(w io.Writer, r *ErrorReport) {
+	if r.ShowUsage {
+		fmt.Fprintln(w, r.Cmd.UsageString())
+	}
+	fmt.Fprintln(w, "Error:", r.Err.Error())
+}
+
+// HintErrorRenderer prints "Error: <message>" with the offending flag
+// highlighted, followed by a hint to run --help instead of the full usage.
+func HintErrorRenderer(w io.Writer, r *ErrorReport) {
+	msg := r.Err.Error()
+	prefix := "Error:"
+	if r.Color {
+		prefix = ansiRed + prefix + ansiReset
+		msg = highlightParam(msg, r.Param)
+	}
+	fmt.Fprintln(w, prefix, msg)
+	if r.ShowUsage {
+		fmt.Fprintf(w, "Run '%s --help' for usage.\n", r.Cmd.CommandPath())
+	}
+}
+
+// JSONErrorRenderer prints the error as a single JSON object, e.g.
+//
+//	{"error":"missing required param 'name'","code":5,"command":"app add","param":"name"}
+func JSONErrorRenderer(w io.Writer, r *ErrorReport) {
+	out := struct {
+		Error   string `json:"error"`
+		Code    int    `json:"code"`
+		Command string `json:"command"`
+		Param   string `json:"param,omitempty"`
+		Env     string `json:"env,omitempty"`
+	}{
+		Error:   r.Err.Error(),
+		Code:    r.Code,
+		Command: r.Cmd.CommandPath(),
+		Param:   r.Param,
+	}
+	var ve *ValidationError
+	if errors.As(r.Err, &ve) {
+		out.Env = ve.Env
+	}
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		fmt.Fprintln(w, "Error:", r.Err.Error())
+	}
+}
+
+// AutoErrorRenderer renders errors as JSON when stdout isn't a terminal,
+// e.g. when another program runs the CLI, and with text otherwise.
+// --error-format overrides the guess either way.
+func AutoErrorRenderer(text ErrorRenderer) ErrorRenderer {
+	return func(w io.Writer, r *ErrorReport) {
+		if r.Format == "json" || r.Format == "" && !stdoutIsTerminal() {
+			JSONErrorRenderer(w, r)
+			return
+		}
+		text(w, r)
+	}
+}
+
+var defaultErrorRenderer ErrorRenderer = UsageErrorRenderer
+
+// SetDefaultErrorRenderer sets the renderer used by commands without an
+// ErrorRenderer of their own. nil restores UsageErrorRenderer.
+func SetDefaultErrorRenderer(r ErrorRenderer) {
+	if r == nil {
+		r = UsageErrorRenderer
+	}
+	defaultErrorRenderer = r
+}
+
+// stdoutIsTerminal is replaced in tests.
+var stdoutIsTerminal = func() bool {
+	return isTerminal(os.Stdout)
+}
+
+const (
+	ansiRed     = "\x1b[31m"
+	ansiBoldRed = "\x1b[1;31m"
+	ansiReset   = "\x1b[0m"
+)
+
+// colorEnabled reports whether escape codes may be written to f, reading
+// NO_COLOR and TERM the way cmd reads env vars.
+func colorEnabled(cmd *cobra.Command, f *os.File) bool {
+	if _, ok := LookupEnv(cmd, "NO_COLOR"); ok {
+		return false
+	}
+	if term, _ := LookupEnv(cmd, "TERM"); term == "dumb" {
+		return false
+	}
+	return isTerminal(f)
+}
+
+// highlightParam colors the first mention of param in msg, as "--param",
+// "'param'" or "\"param\"".
+func highlightParam(msg, param string) string {
+	if param == "" {
+		return msg
+	}
+	for _, s := range []string{"--" + param, "'" + param + "'", `"` + param + `"`} {
+		if i := strings.Index(msg, s); i >= 0 {
+			return msg[:i] + ansiBoldRed + s + ansiReset + msg[i+len(s):]
+		}
+	}
+	return msg
+}
+
+// errorParam returns the name of the param or flag err is about, if any.
+func errorParam(err error) string {
+	var ve *ValidationError
+	var notExist *pflag.NotExistError
+	var invalidValue *pflag.InvalidValueError
+	var valueRequired *pflag.ValueRequiredError
+	var invalidSyntax *pflag.InvalidSyntaxError
+	switch {
+	case errors.As(err, &ve):
+		return ve.Param
+	case errors.As(err, &invalidValue):
+		return invalidValue.GetFlag().Name
+	case errors.As(err, &valueRequired):
+		return valueRequired.GetFlag().Name
+	case errors.As(err, &notExist):
+		return notExist.GetSpecifiedName()
+	case errors.As(err, &invalidSyntax):
+		return strings.TrimLeft(invalidSyntax.GetSpecifiedFlag(), "-")
+	}
+	return ""
+}
+
+// errorRendererFor returns the ErrorRenderer of cmd or its nearest
+// ancestor setting one, or the default.
+func errorRendererFor(cmd *cobra.Command) ErrorRenderer {
+	for c := cmd; c != nil; c = c.Parent() {
+		if ctx := lookupDescribe(c); ctx != nil && ctx.errorRenderer != nil {
+			return ctx.errorRenderer
+		}
+	}
+	return defaultErrorRenderer
+}
+
+// errorFormat is the value of --error-format on cmd, "" if not given.
+func errorFormat(cmd *cobra.Command) string {
+	if f := cmd.Flags().Lookup(errorFormatFlag); f != nil && f.Changed {
+		return f.Value.String()
+	}
+	return ""
+}
+
+// errorFormatValue backs the --error-format flag.
+type errorFormatValue string
+
+func (v *errorFormatValue) String() string { return string(*v) }
+func (v *errorFormatValue) Type() string   { return "string" }
+
+func (v *errorFormatValue) Set(s string) error {
+	if s != "text" && s != "json" {
+		return fmt.Errorf("expected text or json")
+	}
+	*v = errorFormatValue(s)
+	return nil
+}
+
+// addErrorFormatFlag adds the persistent --error-format flag for
+// Cmd.ErrorFormatFlag.
+func (b Cmd) addErrorFormatFlag(cmd *cobra.Command) error {
+	if !b.ErrorFormatFlag {
+		return nil
+	}
+	if cmd.Flags().Lookup(errorFormatFlag) != nil || cmd.PersistentFlags().Lookup(errorFormatFlag) != nil {
+		return fmt.Errorf("error format: flag --%s is already taken", errorFormatFlag)
+	}
+	v := errorFormatValue("text")
+	cmd.PersistentFlags().Var(&v, errorFormatFlag, "how errors are printed: text or json")
+	_ = cmd.RegisterFlagCompletionFunc(errorFormatFlag, cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return nil
+}
+
+// printError renders err of cmd to its ErrOrStderr with the applicable
+// ErrorRenderer. An ExitError without an Err prints nothing.
+func printError(cmd *cobra.Command, err error, withUsage bool) {
+	var ee *ExitError
+	if errors.As(err, &ee) && ee.Err == nil {
+		return
+	}
+	w := cmd.ErrOrStderr()
+	f, isFile := w.(*os.File)
+	r := &ErrorReport{
+		Cmd:       cmd,
+		Err:       err,
+		Code:      ExitCodeFor(cmd, err),
+		Param:     errorParam(err),
+		ShowUsage: withUsage,
+		Color:     isFile && colorEnabled(cmd, f),
+		Format:    errorFormat(cmd),
+	}
+	render := errorRendererFor(cmd)
+	if r.Format == "json" {
+		render = JSONErrorRenderer
+	}
+	render(w, r)
+}
@@ -0,0 +1,185 @@
+package boa
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type renderParams struct {
+	Name string `env:"APP_NAME"`
+	Port int    `short:"p" min:"1" default:"80"`
+	File string `positional:"true"`
+}
+
+func TestValidationError(t *testing.T) {
+	run := func(args ...string) error {
+		return (CmdT[renderParams]{
+			Use:     "app",
+			RunFunc: func(*renderParams, *cobra.Command, []string) {},
+		}).RunArgsE(args)
+	}
+
+	err := run("--name", "x", "--port", "0", "f")
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Param != "port" || ve.Positional {
+		t.Fatalf("expected a ValidationError for port, got %#v", err)
+	}
+	if err.Error() != "invalid value for param 'port': value 0 is below min 1" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+
+	err = run("f")
+	if !errors.As(err, &ve) || ve.Param != "name" || ve.Env != "APP_NAME" {
+		t.Errorf("expected a ValidationError for name, got %#v", err)
+	}
+}
+
+func TestErrorRenderers(t *testing.T) {
+	cmd := (CmdT[renderParams]{Use: "app"}).ToCobra()
+	report := &ErrorReport{
+		Cmd:       cmd,
+		Err:       &ValidationError{Param: "port", Env: "APP_PORT", Err: errors.New("invalid value for param 'port': too low")},
+		Code:      ExitValidation,
+		Param:     "port",
+		ShowUsage: true,
+	}
+
+	var buf bytes.Buffer
+	HintErrorRenderer(&buf, report)
+	if want := "Error: invalid value for param 'port': too low\nRun 'app --help' for usage.\n"; buf.String() != want {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	report.Color = true
+	HintErrorRenderer(&buf, report)
+	if want := "\x1b[31mError:\x1b[0m invalid value for param \x1b[1;31m'port'\x1b[0m: too low\n"; !strings.HasPrefix(buf.String(), want) {
+		t.Errorf("expected %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	JSONErrorRenderer(&buf, report)
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	want := map[string]any{"error": "invalid value for param 'port': too low", "code": 5.0, "command": "app", "param": "port", "env": "APP_PORT"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, got[k])
+		}
+	}
+
+	buf.Reset()
+	UsageErrorRenderer(&buf, report)
+	if !strings.HasPrefix(buf.String(), "Usage:") || !strings.HasSuffix(buf.String(), "Error: invalid value for param 'port': too low\n") {
+		t.Errorf("expected usage then the error, got %q", buf.String())
+	}
+}
+
+func TestAutoErrorRenderer(t *testing.T) {
+	old := stdoutIsTerminal
+	defer func() { stdoutIsTerminal = old }()
+	report := &ErrorReport{Cmd: &cobra.Command{Use: "app"}, Err: errors.New("boom"), Code: 1}
+	render := AutoErrorRenderer(HintErrorRenderer)
+
+	tests := []struct {
+		terminal bool
+		format   string
+		json     bool
+	}{
+		{true, "", false},
+		{false, "", true},
+		{false, "text", false},
+		{true, "json", true},
+	}
+	for _, tt := range tests {
+		stdoutIsTerminal = func() bool { return tt.terminal }
+		report.Format = tt.format
+		var buf bytes.Buffer
+		render(&buf, report)
+		if isJSON := strings.HasPrefix(buf.String(), "{"); isJSON != tt.json {
+			t.Errorf("terminal=%v format=%q: expected json=%v, got %q", tt.terminal, tt.format, tt.json, buf.String())
+		}
+	}
+}
+
+func TestColorEnabled_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	if colorEnabled(&cobra.Command{}, os.Stderr) {
+		t.Error("expected NO_COLOR to disable color, even when empty")
+	}
+}
+
+func TestColorEnabled_LookupEnv(t *testing.T) {
+	cmd := (CmdT[NoParams]{
+		Use:       "app",
+		LookupEnv: mapLookup(map[string]string{"TERM": "dumb"}),
+		RunFunc:   func(*NoParams, *cobra.Command, []string) {},
+	}).ToCobra()
+	if colorEnabled(cmd, os.Stderr) {
+		t.Error("expected TERM to be read through the command's LookupEnv")
+	}
+}
+
+func TestErrorParam(t *testing.T) {
+	cmd := (CmdT[renderParams]{Use: "app", RunFunc: func(*renderParams, *cobra.Command, []string) {}}).ToCobra()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--bogus"}, "bogus"},
+		{[]string{"--port", "x"}, "port"},
+		{[]string{"-p"}, "port"},
+		{[]string{"--name", "x", "--port", "0", "f"}, "port"},
+	}
+	for _, tt := range tests {
+		cmd.SetArgs(tt.args)
+		err := cmd.Execute()
+		if got := errorParam(err); got != tt.want {
+			t.Errorf("%v: expected %q, got %q (%v)", tt.args, tt.want, got, err)
+		}
+	}
+}
+
+func TestRun_ErrorRenderer(t *testing.T) {
+	root := (CmdT[NoParams]{
+		Use:             "app",
+		ErrorRenderer:   HintErrorRenderer,
+		ErrorFormatFlag: true,
+		SubCmds: SubCmds(CmdT[renderParams]{
+			Use:     "serve",
+			RunFunc: func(*renderParams, *cobra.Command, []string) {},
+		}),
+	}).ToCobra()
+
+	root.SetArgs([]string{"serve", "--bogus"})
+	code, _, stderr := runCapturingExit(t, root)
+	if code != ExitUsage || stderr != "Error: unknown flag: --bogus\nRun 'app serve --help' for usage.\n" {
+		t.Errorf("expected a hint, got exit %d and %q", code, stderr)
+	}
+
+	root.SetArgs([]string{"serve", "--error-format", "json", "--name", "x", "--port", "0", "f"})
+	code, _, stderr = runCapturingExit(t, root)
+	var got struct {
+		Error, Command, Param string
+		Code                  int
+	}
+	if err := json.Unmarshal([]byte(stderr), &got); err != nil {
+		t.Fatalf("expected JSON, got %q", stderr)
+	}
+	if code != ExitValidation || got.Code != ExitValidation || got.Param != "port" || got.Command != "app serve" {
+		t.Errorf("unexpected exit %d and error %+v", code, got)
+	}
+
+	root.SetArgs([]string{"serve", "--error-format", "yaml"})
+	if code, _, stderr = runCapturingExit(t, root); code != ExitUsage || !strings.Contains(stderr, "expected text or json") {
+		t.Errorf("expected an invalid --error-format to fail, got exit %d and %q", code, stderr)
+	}
+}
@@ -0,0 +1,128 @@
+package boa
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes used by Run for the errors boa recognizes (see ExitCode).
+const (
+	ExitOK          = 0
+	ExitFailure     = 1   // the command failed
+	ExitUsage       = 2   // bad flags, arguments or subcommand
+	ExitConfig      = 3   // a config file couldn't be read or parsed
+	ExitNotFound    = 4   // something the command needed doesn't exist
+	ExitValidation  = 5   // a param value failed validation
+	ExitTimeout     = 124 // stopped by Cmd.Timeout, see ErrTimeout
+	ExitInterrupted = 130 // stopped by a signal, see ErrInterrupted
+)
+
+// ExitError makes Run exit with Code. Return it from a run function or hook
+// to pick the exit code; Err, if set, is printed as the error message.
+// Run prints ExitErrors cleanly instead of panicking.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode classifies err into the exit code Run uses, without any
+// ExitCodeMapper: an ExitError's Code, ExitInterrupted for ErrInterrupted,
+// ExitTimeout for ErrTimeout, ExitConfig for config file errors, ExitValidation for invalid param
+// values, ExitUsage for other user input errors, ExitNotFound for
+// fs.ErrNotExist, ExitFailure for anything else and ExitOK for nil.
+// Use it with RunE to exit like Run does:
+//
+//	if err := cmd.RunE(); err != nil {
+//	    os.Exit(boa.ExitCode(err))
+//	}
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var ee *ExitError
+	if errors.As(err, &ee) {
+		return ee.Code
+	}
+	if errors.Is(err, ErrInterrupted) {
+		return ExitInterrupted
+	}
+	if errors.Is(err, ErrTimeout) {
+		return ExitTimeout
+	}
+	var uie *UserInputError
+	if errors.As(err, &uie) && uie.code != 0 {
+		return uie.code
+	}
+	if errors.Is(err, ErrNotConfirmed) {
+		return ExitFailure
+	}
+	if IsUserInputError(err) {
+		return ExitUsage
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return ExitNotFound
+	}
+	return ExitFailure
+}
+
+// withExitCode marks err as a user input error exiting with code, unless
+// it already carries one.
+func withExitCode(err error, code int) error {
+	if err == nil {
+		return nil
+	}
+	var uie *UserInputError
+	if errors.As(err, &uie) && uie.code != 0 {
+		return err
+	}
+	return &UserInputError{Err: err, code: code}
+}
+
+// ExitCodeFor is ExitCode with the ExitCodeMappers of cmd and its
+// ancestors applied first, nearest first. An ExitError always wins.
+// cmd is the command that ran, as returned by ExecuteC.
+func ExitCodeFor(cmd *cobra.Command, err error) int {
+	var ee *ExitError
+	if errors.As(err, &ee) {
+		return ee.Code
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if ctx := lookupDescribe(c); ctx != nil && ctx.exitCodeMapper != nil {
+			if code := ctx.exitCodeMapper(err); code != 0 {
+				return code
+			}
+		}
+	}
+	return ExitCode(err)
+}
+
+// cleanRunErrorsFor reports whether run function errors of cmd should be
+// printed instead of panicking: when the error asks for an exit code or
+// comes from a signal or the timeout, or when cmd or an ancestor sets
+// CleanRunErrors.
+func cleanRunErrorsFor(cmd *cobra.Command, err error) bool {
+	var ee *ExitError
+	if errors.As(err, &ee) || errors.Is(err, ErrInterrupted) || errors.Is(err, ErrTimeout) {
+		return true
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if ctx := lookupDescribe(c); ctx != nil && ctx.cleanRunErrors {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package boa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestExitCode(t *testing.T) {
+	type params struct {
+		Config string `configfile:"true" optional:"true"`
+		Port   int    `min:"1" optional:"true"`
+	}
+	run := func(args ...string) error {
+		return (CmdT[params]{
+			Use:     "app",
+			RunFunc: func(*params, *cobra.Command, []string) {},
+		}).RunArgsE(args)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, ExitOK},
+		{"plain", errors.New("boom"), ExitFailure},
+		{"exit error", fmt.Errorf("wrapped: %w", &ExitError{Code: 42}), 42},
+		{"interrupted", fmt.Errorf("stopping: %w", ErrInterrupted), ExitInterrupted},
+		{"interrupted ctx.Err", fmt.Errorf("%w: %w", ErrInterrupted, context.Canceled), ExitInterrupted},
+		{"timeout", fmt.Errorf("%w: %w", ErrTimeout, context.DeadlineExceeded), ExitTimeout},
+		{"timeout ctx.Err", timeoutErr(), ExitTimeout},
+		{"not found", fmt.Errorf("open: %w", fs.ErrNotExist), ExitNotFound},
+		{"user input", NewUserInputErrorf("bad"), ExitUsage},
+		{"not confirmed", NewUserInputError(ErrNotConfirmed), ExitFailure},
+		{"unknown flag", run("--nope"), ExitUsage},
+		{"bad flag value", run("--port", "x"), ExitUsage},
+		{"validation", run("--port", "0"), ExitValidation},
+		{"config file", run("--config", missing), ExitConfig},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("%s: expected %d for %v, got %d", tt.name, tt.want, tt.err, got)
+		}
+	}
+}
+
+// runCapturingExit runs cmd with Run, returning the exit code (-1 if Run
+// didn't exit), the panic value and stderr.
+func runCapturingExit(t *testing.T, cmd *cobra.Command) (code int, panicked any, stderr string) {
+	t.Helper()
+	code = -1
+	oldOsExit := osExit
+	osExit = func(c int) { code = c }
+	defer func() { osExit = oldOsExit }()
+
+	oldStderr := os.Stderr
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stderr = w
+	func() {
+		defer func() { panicked = recover() }()
+		runH(cmd, resultHandler{})
+	}()
+	_ = w.Close()
+	os.Stderr = oldStderr
+	out, _ := io.ReadAll(r)
+	return code, panicked, string(out)
+}
+
+func TestRun_ExitCodes(t *testing.T) {
+	errGone := errors.New("resource gone")
+	mapper := func(err error) int {
+		if errors.Is(err, errGone) {
+			return ExitNotFound
+		}
+		return 0
+	}
+
+	// The root's mapper and CleanRunErrors apply to subcommands
+	root := (CmdT[NoParams]{
+		Use:            "app",
+		ExitCodeMapper: mapper,
+		CleanRunErrors: true,
+		SubCmds: SubCmds(CmdT[NoParams]{
+			Use: "get",
+			RunFuncE: func(*NoParams, *cobra.Command, []string) error {
+				return fmt.Errorf("get: %w", errGone)
+			},
+		}),
+	}).ToCobra()
+	root.SetArgs([]string{"get"})
+	code, panicked, stderr := runCapturingExit(t, root)
+	if panicked != nil || code != ExitNotFound {
+		t.Errorf("expected exit %d without panic, got %d (panic %v)", ExitNotFound, code, panicked)
+	}
+	if stderr != "Error: get: resource gone\n" {
+		t.Errorf("expected just the error, got %q", stderr)
+	}
+
+	// An ExitError picks the code and never panics
+	cmd := (CmdT[NoParams]{
+		Use: "app",
+		RunFuncE: func(*NoParams, *cobra.Command, []string) error {
+			return &ExitError{Code: 7}
+		},
+	}).ToCobra()
+	cmd.SetArgs(nil)
+	code, panicked, stderr = runCapturingExit(t, cmd)
+	if panicked != nil || code != 7 || stderr != "" {
+		t.Errorf("expected a silent exit 7, got %d (panic %v, stderr %q)", code, panicked, stderr)
+	}
+
+	// So does an interrupted command
+	cmd = (CmdT[NoParams]{
+		Use: "app",
+		RunFuncE: func(*NoParams, *cobra.Command, []string) error {
+			return context.Cause(canceledContext())
+		},
+	}).ToCobra()
+	cmd.SetArgs(nil)
+	if code, panicked, _ = runCapturingExit(t, cmd); panicked != nil || code != ExitInterrupted {
+		t.Errorf("expected exit %d, got %d (panic %v)", ExitInterrupted, code, panicked)
+	}
+
+	// And one that timed out, returning ctx.Err()
+	cmd = (CmdT[NoParams]{
+		Use:     "app",
+		Timeout: time.Millisecond,
+		RunFuncCtxE: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+			<-ctx.Context().Done()
+			return ctx.Context().Err()
+		},
+	}).ToCobra()
+	cmd.SetArgs(nil)
+	if code, panicked, stderr = runCapturingExit(t, cmd); panicked != nil || code != ExitTimeout || strings.Contains(stderr, "Usage:") {
+		t.Errorf("expected a clean exit %d, got %d (panic %v, stderr %q)", ExitTimeout, code, panicked, stderr)
+	}
+
+	// Without CleanRunErrors, other run errors still panic
+	cmd = (CmdT[NoParams]{
+		Use: "app",
+		RunFuncE: func(*NoParams, *cobra.Command, []string) error {
+			return errors.New("bug")
+		},
+	}).ToCobra()
+	cmd.SetArgs(nil)
+	if code, panicked, stderr = runCapturingExit(t, cmd); panicked == nil || code != -1 || !strings.Contains(stderr, "Usage:") {
+		t.Errorf("expected a panic after usage, got exit %d (panic %v, stderr %q)", code, panicked, stderr)
+	}
+}
+
+// timeoutErr is what a command returning ctx.Err() after its Timeout fails
+// with.
+func timeoutErr() error {
+	return (CmdT[NoParams]{
+		Use:     "app",
+		Timeout: time.Millisecond,
+		RunFuncCtxE: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+			<-ctx.Context().Done()
+			return ctx.Context().Err()
+		},
+	}).RunArgsE(nil)
+}
+
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrInterrupted)
+	return ctx
+}
+
+func TestExecuteC_ExitCodeFor(t *testing.T) {
+	errGone := errors.New("resource gone")
+	root := (CmdT[NoParams]{
+		Use: "app",
+		ExitCodeMapper: func(err error) int {
+			if errors.Is(err, errGone) {
+				return ExitNotFound
+			}
+			return 0
+		},
+		CleanRunErrors: true,
+		SubCmds: SubCmds(CmdT[NoParams]{
+			Use: "get",
+			RunFuncE: func(*NoParams, *cobra.Command, []string) error {
+				return errGone
+			},
+		}),
+	}).ToCobra()
+	root.SetArgs([]string{"get"})
+	root.SetErr(io.Discard)
+	executed, err := ExecuteC(root)
+	if executed == nil || executed.Name() != "get" {
+		t.Fatalf("expected the get subcommand to be returned, got %v", executed)
+	}
+	if code := ExitCodeFor(executed, err); code != ExitNotFound {
+		t.Errorf("expected the root's mapper to give %d, got %d", ExitNotFound, code)
+	}
+	if code := ExitCode(err); code != ExitFailure {
+		t.Errorf("expected ExitCode to ignore the mapper, got %d", code)
+	}
+}
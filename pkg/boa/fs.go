@@ -0,0 +1,28 @@
+package boa
+
+import (
+	"io/fs"
+
+	"github.com/spf13/cobra"
+)
+
+// FileSystem returns the filesystem cmd reads config files from (see
+// CmdT.FS), for hooks and helpers that only have the cobra command. nil
+// means the OS filesystem.
+func FileSystem(cmd *cobra.Command) fs.FS {
+	for c := cmd; c != nil; c = c.Parent() {
+		if ctx := lookupDescribe(c); ctx != nil {
+			return ctx.fileSystem()
+		}
+	}
+	return nil
+}
+
+// fileSystem returns the Cmd.FS that applies: the command's own or the
+// nearest ancestor's. nil means the OS filesystem.
+func (ctx *processingContext) fileSystem() fs.FS {
+	if c := ctx.inherited(func(c *processingContext) bool { return c.fsys != nil }); c != nil {
+		return c.fsys
+	}
+	return nil
+}
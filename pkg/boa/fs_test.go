@@ -0,0 +1,166 @@
+package boa
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/spf13/cobra"
+)
+
+// writableMapFS is a fstest.MapFS that config files can be dumped to.
+type writableMapFS struct{ fstest.MapFS }
+
+func (m writableMapFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.MapFS[name] = &fstest.MapFile{Data: data, Mode: perm}
+	return nil
+}
+
+func TestFS_ConfigFile(t *testing.T) {
+	type params struct {
+		Config string `configfile:"true" optional:"true"`
+		Host   string `optional:"true"`
+	}
+	fsys := fstest.MapFS{"etc/app.json": {Data: []byte(`{"Host": "from-fs"}`)}}
+
+	var watched []string
+	var viaCobra fs.FS
+	p := &params{}
+	err := (CmdT[params]{
+		Use:    "app",
+		Params: p,
+		FS:     fsys,
+		RunFuncCtx: func(ctx *HookContext, _ *params, cmd *cobra.Command, _ []string) {
+			watched = ctx.WatchedConfigFiles()
+			viaCobra = FileSystem(cmd)
+		},
+	}).RunArgsE([]string{"--config", "/etc/app.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Host != "from-fs" || !reflect.DeepEqual(watched, []string{"/etc/app.json"}) || viaCobra == nil {
+		t.Errorf("expected the config from the FS under the path as given, got %+v, %q", p, watched)
+	}
+
+	err = (CmdT[params]{Use: "app", FS: fsys, RunFunc: func(*params, *cobra.Command, []string) {}}).
+		RunArgsE([]string{"--config", "/etc/missing.json"})
+	if !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), "failed to read config file /etc/missing.json: open /etc/missing.json:") {
+		t.Errorf("expected a not-exist error for the path as given, got %v", err)
+	}
+}
+
+func TestFS_InheritedAndReloaded(t *testing.T) {
+	type params struct {
+		Config string `configfile:"true" default:"app.json"`
+		Port   int
+	}
+	fsys := fstest.MapFS{"app.json": {Data: []byte(`{"Port": 1}`)}}
+	var first, reloaded int
+	root := (CmdT[NoParams]{
+		Use: "app",
+		FS:  fsys,
+		SubCmds: SubCmds(CmdT[params]{
+			Use: "serve",
+			RunFuncCtxE: func(ctx *HookContext, p *params, _ *cobra.Command, _ []string) error {
+				first = p.Port
+				fsys["app.json"] = &fstest.MapFile{Data: []byte(`{"Port": 2}`)}
+				fresh, err := Reload[params](ctx)
+				if err != nil {
+					return err
+				}
+				reloaded = fresh.Port
+				return nil
+			},
+		}),
+	}).ToCobra()
+	root.SetArgs([]string{"serve"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if first != 1 || reloaded != 2 {
+		t.Errorf("expected the root's FS for the run and the reload, got %d and %d", first, reloaded)
+	}
+}
+
+func TestFS_ArgFiles(t *testing.T) {
+	type params struct {
+		Name string
+		Port int
+	}
+	fsys := fstest.MapFS{
+		"ci/main.args": {Data: []byte("--name bob @port.args")},
+		"ci/port.args": {Data: []byte("--port 8080")},
+	}
+	p := &params{}
+	err := (CmdT[params]{
+		Use:      "app",
+		Params:   p,
+		FS:       fsys,
+		ArgFiles: true,
+		RunFunc:  func(*params, *cobra.Command, []string) {},
+	}).RunArgsE([]string{"@/ci/main.args"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "bob" || p.Port != 8080 {
+		t.Errorf("expected argfiles from the FS, relative to the including one, got %+v", p)
+	}
+}
+
+func TestLoadConfigFileFS(t *testing.T) {
+	type cfg struct {
+		Host string
+		Port int
+	}
+	fsys := fstest.MapFS{
+		"base.json":  {Data: []byte(`{"Host": "a", "Port": 1}`)},
+		"local.json": {Data: []byte(`{"Port": 2}`)},
+	}
+	var c cfg
+	if err := LoadConfigFilesFS(fsys, []string{"/base.json", "", "./local.json"}, &c, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c.Host != "a" || c.Port != 2 {
+		t.Errorf("expected the overlay from the FS, got %+v", c)
+	}
+	err := LoadConfigFileFS(fsys, "../base.json", &c, nil)
+	if !errors.Is(err, fs.ErrInvalid) || !strings.Contains(err.Error(), "../base.json") {
+		t.Errorf("expected paths leaving the FS to be invalid, got %v", err)
+	}
+}
+
+func TestDumpConfigFileFS(t *testing.T) {
+	type cfg struct {
+		Host string
+	}
+	fsys := writableMapFS{fstest.MapFS{}}
+	if err := DumpConfigFileFS(fsys, "/etc/app.json", &cfg{Host: "h"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	var back cfg
+	if err := LoadConfigFileFS(fsys, "/etc/app.json", &back, nil); err != nil || back.Host != "h" {
+		t.Errorf("expected the dump to load back from the same path, got %+v, %v", back, err)
+	}
+
+	type params struct {
+		Host string `optional:"true"`
+	}
+	dump := func(fsys fs.FS) error {
+		return (CmdT[params]{
+			Use: "app",
+			FS:  fsys,
+			RunFuncCtxE: func(ctx *HookContext, _ *params, _ *cobra.Command, _ []string) error {
+				return ctx.DumpFile("/out.json", nil)
+			},
+		}).RunArgsE([]string{"--host", "x"})
+	}
+	if err := dump(fsys); err != nil || fsys.MapFS["out.json"] == nil {
+		t.Errorf("expected DumpFile to write to the command's FS, got %v", err)
+	}
+	if err := dump(fstest.MapFS{}); err == nil || !strings.Contains(err.Error(), "WriteFileFS") {
+		t.Errorf("expected DumpFile to fail for a read-only FS, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package boa
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// installHelpSections extends the command's help: the Examples block gets
+// an invocation built from `example` tags after cmd.Example, and
+// Environment and Configuration sections after the flags list every
+// env-bound param and every config-file key, including params that have
+// no flag. Everything is read when help is rendered.
+func installHelpSections(cmd *cobra.Command, ctx *processingContext) {
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		if writeHelpJSON(c) {
+			return
+		}
+		if c != cmd {
+			// A non-boa child inherits this help func; render it normally.
+			baseHelpFunc(cmd)(c, args)
+			return
+		}
+		example := cmd.Example
+		defer func() { cmd.Example = example }()
+		cmd.Example = joinExamples(example, exampleInvocation(cmd, ctx))
+		baseHelpFunc(cmd)(cmd, args)
+		w := cmd.OutOrStdout()
+		writeEnvSection(w, ctx)
+		writeConfigSection(w, ctx)
+	})
+}
+
+// baseHelpFunc is the help func cmd would have without boa's: its parent's,
+// or cobra's default for a root command.
+func baseHelpFunc(cmd *cobra.Command) func(*cobra.Command, []string) {
+	if cmd.HasParent() {
+		return cmd.Parent().HelpFunc()
+	}
+	return (&cobra.Command{}).HelpFunc()
+}
+
+func joinExamples(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.TrimRight(p, "\n"); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+// exampleInvocation builds "  <command path> --flag value ... <positional>"
+// from the params' `example` tags, or "" if none have one. Flag values are
+// quoted as needed; positional and rest-args examples are used verbatim so
+// they can hold several words.
+func exampleInvocation(cmd *cobra.Command, ctx *processingContext) string {
+	var flags, positional, rest []string
+	for _, param := range helpParams(ctx) {
+		sample := param.GetExample()
+		if sample == "" || param.IsNoFlag() {
+			continue
+		}
+		switch {
+		case param.IsRestArgs():
+			rest = append(rest, "--", sample)
+		case param.isPositional():
+			positional = append(positional, sample)
+		case param.GetKind() == reflect.Bool && sample == "true":
+			flags = append(flags, "--"+param.GetName())
+		default:
+			flags = append(flags, "--"+param.GetName(), shellQuote(sample))
+		}
+	}
+	if len(flags)+len(positional)+len(rest) == 0 {
+		return ""
+	}
+	words := append([]string{cmd.CommandPath()}, flags...)
+	words = append(append(words, positional...), rest...)
+	return "  " + strings.Join(words, " ")
+}
+
+// shellQuote quotes s if it would otherwise not survive a shell as one word.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`*?[]{}()<>|&;#~") {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// helpParams returns the params to document, in declaration order.
+func helpParams(ctx *processingContext) []Param {
+	var out []Param
+	for _, path := range ctx.pathOrder {
+		if param, ok := ctx.mirrorByPath[path]; ok && !param.IsIgnored() {
+			out = append(out, param)
+		}
+	}
+	return out
+}
+
+// writeEnvSection lists every param read from an env var, including ones
+// without a CLI flag, plus the --yes env var of a Confirm command.
+func writeEnvSection(w io.Writer, ctx *processingContext) {
+	var rows [][]string
+	for _, param := range helpParams(ctx) {
+		if param.GetEnv() == "" || param.IsNoEnv() {
+			continue
+		}
+		descr := helpDescription(param)
+		if param.IsNoFlag() {
+			descr = strings.TrimSpace(descr + " (no flag)")
+		}
+		rows = append(rows, []string{param.GetEnv(), descr})
+	}
+	if ctx.confirm != nil && ctx.confirm.env != "" {
+		rows = append(rows, []string{ctx.confirm.env, "skip the confirmation prompt"})
+	}
+	writeHelpSection(w, "Environment:", rows)
+}
+
+// writeConfigSection lists the keys a config file may set, for commands
+// that load one. Keys use the struct tag of the config file's format.
+func writeConfigSection(w io.Writer, ctx *processingContext) {
+	if len(ctx.ConfigFiles) == 0 {
+		return
+	}
+	tag := structTagForExt(configFileExt(ctx))
+	var rows [][]string
+	for _, param := range helpParams(ctx) {
+		if param.IsConfigFile() || param.IsRestArgs() {
+			continue
+		}
+		key, ok := configKeyFor(ctx, param, tag)
+		if !ok {
+			continue
+		}
+		rows = append(rows, []string{key, param.GetType().String(), helpDescription(param)})
+	}
+	writeHelpSection(w, "Configuration:", rows)
+}
+
+// helpDescription is the param's description followed by its default,
+// unless the param is secret.
+func helpDescription(param Param) string {
+	descr := param.getDescr()
+	if param.hasDefaultValue() && !param.IsSecret() {
+		def := param.defaultValueStr()
+		if param.GetKind() == reflect.String {
+			def = strconv.Quote(def)
+		}
+		descr = strings.TrimSpace(fmt.Sprintf("%s (default %s)", descr, def))
+	}
+	return descr
+}
+
+func writeHelpSection(w io.Writer, title string, rows [][]string) {
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s\n", title)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintf(tw, "  %s\n", strings.Join(row, "\t"))
+	}
+	_ = tw.Flush()
+}
+
+// configFileExt is the extension of the first config file path the command
+// knows about (its default, before parsing), or "" for JSON.
+func configFileExt(ctx *processingContext) string {
+	for _, entry := range ctx.ConfigFiles {
+		if paths := configFilePathsFromMirror(entry.mirror); len(paths) > 0 {
+			return filepath.Ext(paths[0])
+		}
+	}
+	return ""
+}
+
+// configKeyFor is the dotted config-file key of param, e.g. "db.host",
+// using the given struct tag for key names. ok is false for fields the
+// format skips (tag "-").
+func configKeyFor(ctx *processingContext, param Param, tag string) (key string, ok bool) {
+	pm, isMeta := param.(*paramMeta)
+	if !isMeta || ctx.rootStructPtr == nil {
+		return "", false
+	}
+	t := reflect.TypeOf(ctx.rootStructPtr)
+	var parts []string
+	for _, idx := range splitPath(pm.pathKey) {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		sf := t.Field(idx)
+		name := fieldRawKey(sf, tag)
+		if name == "" {
+			return "", false
+		}
+		// Embedded structs without an explicit key are flattened, as in
+		// encoding/json
+		if !sf.Anonymous || name != sf.Name {
+			parts = append(parts, name)
+		}
+		t = sf.Type
+	}
+	return strings.Join(parts, "."), len(parts) > 0
+}
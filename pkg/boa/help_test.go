@@ -0,0 +1,223 @@
+package boa
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func renderHelp(t *testing.T, cmd *cobra.Command, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs(append(args, "--help"))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("help failed: %v", err)
+	}
+	return out.String()
+}
+
+func TestHelp_EnvironmentSection(t *testing.T) {
+	type Params struct {
+		Host   string `descr:"server host" env:"HOST" default:"localhost"`
+		Token  string `descr:"api token" env:"API_TOKEN" boa:"noflag" optional:"true"`
+		Quiet  bool   `descr:"less output" env:"QUIET" boa:"noenv"`
+		NoEnv  string `descr:"no env here" optional:"true"`
+		Hidden string `env:"HIDDEN" boa:"ignore"`
+	}
+
+	help := renderHelp(t, (CmdT[Params]{
+		Use:     "app",
+		RunFunc: func(*Params, *cobra.Command, []string) {},
+	}).ToCobra())
+
+	_, env, found := strings.Cut(help, "\nEnvironment:\n")
+	if !found {
+		t.Fatalf("expected an Environment section:\n%s", help)
+	}
+	want := "  HOST        server host (default \"localhost\")\n" +
+		"  API_TOKEN   api token (no flag)\n"
+	if env != want {
+		t.Errorf("expected environment section\n%q\ngot\n%q", want, env)
+	}
+}
+
+func TestHelp_SecretDefaultHidden(t *testing.T) {
+	type Params struct {
+		Config string `configfile:"true" optional:"true"`
+		Token  string `descr:"api token" env:"API_TOKEN" secret:"true" default:"s3cret"`
+	}
+
+	help := renderHelp(t, (CmdT[Params]{
+		Use:     "app",
+		RunFunc: func(*Params, *cobra.Command, []string) {},
+	}).ToCobra())
+
+	_, sections, found := strings.Cut(help, "\nEnvironment:\n")
+	if !found || !strings.Contains(sections, "\nConfiguration") {
+		t.Fatalf("expected Environment and Configuration sections:\n%s", help)
+	}
+	if strings.Contains(sections, "s3cret") {
+		t.Errorf("expected the secret's default to be left out of the sections:\n%s", sections)
+	}
+}
+
+func TestHelp_NoSectionsWithoutContent(t *testing.T) {
+	type Params struct {
+		Name string `descr:"name"`
+	}
+	help := renderHelp(t, (CmdT[Params]{
+		Use:     "app",
+		RunFunc: func(*Params, *cobra.Command, []string) {},
+	}).ToCobra())
+	for _, section := range []string{"Environment:", "Configuration:", "Examples:"} {
+		if strings.Contains(help, section) {
+			t.Errorf("unexpected %s section:\n%s", section, help)
+		}
+	}
+}
+
+func TestHelp_ConfigurationSection(t *testing.T) {
+	type DB struct {
+		Host string `descr:"db host" json:"hostname" default:"db.local"`
+		Port int    `descr:"db port" optional:"true"`
+	}
+	type Embedded struct {
+		Region string `descr:"region" optional:"true"`
+	}
+	type Params struct {
+		Embedded
+		ConfigFile string            `configfile:"true" default:"app.json"`
+		Name       string            `descr:"name" json:"name" optional:"true"`
+		Secret     string            `descr:"only in config" boa:"configonly" optional:"true"`
+		Skipped    string            `json:"-" optional:"true"`
+		DB         DB                `json:"db"`
+		Labels     map[string]string `descr:"labels"`
+	}
+
+	help := renderHelp(t, (CmdT[Params]{
+		Use:     "app",
+		RunFunc: func(*Params, *cobra.Command, []string) {},
+	}).ToCobra())
+
+	_, cfg, found := strings.Cut(help, "\nConfiguration:\n")
+	if !found {
+		t.Fatalf("expected a Configuration section:\n%s", help)
+	}
+	want := "  Region        string              region\n" +
+		"  name          string              name\n" +
+		"  Secret        string              only in config\n" +
+		"  db.hostname   string              db host (default \"db.local\")\n" +
+		"  db.Port       int                 db port\n" +
+		"  Labels        map[string]string   labels\n"
+	if cfg != want {
+		t.Errorf("expected configuration section\n%s\ngot\n%s", want, cfg)
+	}
+}
+
+func TestHelp_ConfigurationUsesFormatTag(t *testing.T) {
+	type Params struct {
+		ConfigFile string `configfile:"true" default:"app.yaml"`
+		Name       string `descr:"name" yaml:"the_name" json:"jsonName" optional:"true"`
+	}
+	help := renderHelp(t, (CmdT[Params]{
+		Use:     "app",
+		RunFunc: func(*Params, *cobra.Command, []string) {},
+	}).ToCobra())
+	if !strings.Contains(help, "  the_name   string   name") {
+		t.Errorf("expected yaml key in configuration section:\n%s", help)
+	}
+}
+
+func TestHelp_Examples(t *testing.T) {
+	type Params struct {
+		Host    string   `descr:"host" example:"db.internal"`
+		Message string   `descr:"message" example:"hello world" optional:"true"`
+		Verbose bool     `descr:"verbose" example:"true"`
+		Token   string   `descr:"token" example:"abc" boa:"noflag" optional:"true"`
+		Files   []string `positional:"true" example:"a.txt b.txt" optional:"true"`
+	}
+
+	root := (CmdT[NoParams]{
+		Use: "tool",
+		SubCmds: SubCmds(CmdT[Params]{
+			Use:     "deploy",
+			Example: "  tool deploy --host prod.internal",
+			RunFunc: func(*Params, *cobra.Command, []string) {},
+		}),
+	}).ToCobra()
+
+	help := renderHelp(t, root, "deploy")
+	want := "Examples:\n" +
+		"  tool deploy --host prod.internal\n" +
+		"  tool deploy --host db.internal --message \"hello world\" --verbose a.txt b.txt\n"
+	if !strings.Contains(help, want) {
+		t.Errorf("expected examples\n%s\nin help:\n%s", want, help)
+	}
+}
+
+func TestHelp_ProgrammaticExample(t *testing.T) {
+	type Params struct {
+		Host string
+	}
+	help := renderHelp(t, (CmdT[Params]{
+		Use: "app",
+		InitFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command) error {
+			GetParamT(ctx, &p.Host).SetExample("example.com")
+			return nil
+		},
+		RunFunc: func(*Params, *cobra.Command, []string) {},
+	}).ToCobra())
+	if !strings.Contains(help, "Examples:\n  app --host example.com\n") {
+		t.Errorf("expected programmatic example in help:\n%s", help)
+	}
+}
+
+func TestHelp_ConfirmEnv(t *testing.T) {
+	help := renderHelp(t, (CmdT[NoParams]{
+		Use:         "app",
+		Confirm:     "Sure?",
+		ParamEnrich: ParamEnricherCombine(ParamEnricherDefault, ParamEnricherEnvPrefix("APP")),
+		RunFunc:     func(*NoParams, *cobra.Command, []string) {},
+	}).ToCobra())
+	if !strings.Contains(help, "Environment:\n  APP_YES   skip the confirmation prompt\n") {
+		t.Errorf("expected confirm env var in help:\n%s", help)
+	}
+}
+
+func TestHelp_PlainCobraChildKeepsDefaultHelp(t *testing.T) {
+	type Params struct {
+		Host string `env:"HOST"`
+	}
+	root := (CmdT[Params]{
+		Use:     "app",
+		RunFunc: func(*Params, *cobra.Command, []string) {},
+	}).ToCobra()
+	root.AddCommand(&cobra.Command{Use: "plain", Run: func(*cobra.Command, []string) {}})
+
+	help := renderHelp(t, root, "plain")
+	if strings.Contains(help, "Environment:") || !strings.Contains(help, "app plain") {
+		t.Errorf("expected plain cobra help for child:\n%s", help)
+	}
+}
+
+func TestHelp_CustomHelpFuncWins(t *testing.T) {
+	type Params struct {
+		Host string `env:"HOST"`
+	}
+	help := renderHelp(t, (CmdT[Params]{
+		Use: "app",
+		PostCreateFunc: func(p *Params, cmd *cobra.Command) error {
+			cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+				c.Print("custom help")
+			})
+			return nil
+		},
+		RunFunc: func(*Params, *cobra.Command, []string) {},
+	}).ToCobra())
+	if help != "custom help" {
+		t.Errorf("expected the user's help func to replace boa's, got:\n%s", help)
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
+	"math/big"
 	"net"
 	"net/url"
 	"os"
@@ -31,6 +33,8 @@ var osExit = os.Exit
 // exit with error message rather than a panic with stack trace.
 type UserInputError struct {
 	Err error
+	// code is the exit code when it isn't ExitUsage, see ExitCode
+	code int
 }
 
 func (e *UserInputError) Error() string {
@@ -100,19 +104,40 @@ func (e *runFuncError) Error() string { return e.Err.Error() }
 func (e *runFuncError) Unwrap() error { return e.Err }
 
 // Execute runs a cobra command with boa's error handling convention:
-// usage is printed first, then the error message, both to stderr.
+// usage is printed first, then the error message, both to stderr (see
+// ErrorRenderer).
 // Use this instead of cmd.Execute() when working with commands from ToCobra().
 func Execute(cmd *cobra.Command) error {
+	_, err := ExecuteC(cmd)
+	return err
+}
+
+// ExecuteC is like Execute and also returns the command that was executed,
+// e.g. a subcommand, for use with ExitCodeFor.
+func ExecuteC(cmd *cobra.Command) (*cobra.Command, error) {
 	cmd.SilenceErrors = true
 	cmd.SilenceUsage = true
 	executedCmd, err := cmd.ExecuteC()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, executedCmd.UsageString())
-		fmt.Fprintln(os.Stderr, "Error:", err.Error())
+		printError(executedCmd, err, true)
 	}
+	return executedCmd, err
+}
+
+// ExecuteContext is like Execute, with ctx as the parent of the run
+// context (see HookContext.Context).
+func ExecuteContext(ctx context.Context, cmd *cobra.Command) error {
+	_, err := ExecuteContextC(ctx, cmd)
 	return err
 }
 
+// ExecuteContextC is like ExecuteC, with ctx as the parent of the run
+// context.
+func ExecuteContextC(ctx context.Context, cmd *cobra.Command) (*cobra.Command, error) {
+	cmd.SetContext(ctx)
+	return ExecuteC(cmd)
+}
+
 // wrapArgsValidator wraps a cobra.PositionalArgs validator to return UserInputError
 func wrapArgsValidator(validator cobra.PositionalArgs) cobra.PositionalArgs {
 	return func(cmd *cobra.Command, args []string) error {
@@ -211,6 +236,11 @@ type Param interface {
 	IsPositional() bool
 	SetPositional(bool)
 
+	// IsRestArgs / SetRestArgs mirror `boa:"rest"` / `passthrough:"true"`:
+	// the []string parameter receives every argument after `--` verbatim.
+	IsRestArgs() bool
+	SetRestArgs(bool)
+
 	// GetMin / SetMin / ClearMin / GetMax / SetMax / ClearMax / GetPattern /
 	// SetPattern mirror the validation tags. GetMin / GetMax return the bound
 	// as a typed pointer matching the field kind, or nil when no bound is set:
@@ -218,8 +248,11 @@ type Param interface {
 	//   - unsigned int field → *uint64
 	//   - float field        → *float64
 	//   - string/slice/map   → *int (length bound)
-	// SetMin / SetMax accept any numeric value; it's coerced to match the
-	// field kind. ClearMin / ClearMax remove a previously set bound.
+	//   - big.Int/big.Float  → *big.Float
+	//   - time.Time          → *time.Time (relative bounds resolved now)
+	// SetMin / SetMax accept any numeric value (or a time.Time / time string
+	// on time fields); it's coerced to match the field kind. ClearMin /
+	// ClearMax remove a previously set bound.
 	GetMin() any
 	SetMin(any)
 	ClearMin()
@@ -229,6 +262,36 @@ type Param interface {
 	GetPattern() string
 	SetPattern(string)
 
+	// GetTimeLayout / SetTimeLayout / GetTimeZone / SetTimeZone mirror the
+	// `layout` and `tz` tags on time.Time and []time.Time params.
+	GetTimeLayout() string
+	SetTimeLayout(string)
+	GetTimeZone() *time.Location
+	SetTimeZone(*time.Location)
+
+	// GetTransforms / SetTransforms mirror the `transform` tag: registered
+	// transforms (see RegisterTransform) run over the value, in order,
+	// before validation.
+	GetTransforms() []string
+	SetTransforms([]string)
+
+	// GetPrompt / SetPrompt / IsSecret / SetSecret mirror the `prompt` and
+	// `secret` tags used when asking for missing values (see CmdT.Prompt).
+	GetPrompt() string
+	SetPrompt(string)
+	IsSecret() bool
+	SetSecret(bool)
+
+	// GetExample / SetExample mirror the `example` tag: a sample value
+	// used to build the example invocation in help.
+	GetExample() string
+	SetExample(string)
+
+	// GetCompletion / SetCompletion mirror the `complete` tag: "file",
+	// "file:*.yaml,*.json", "dir" or "none". Alternatives take precedence.
+	GetCompletion() string
+	SetCompletion(string)
+
 	// SetRequired is a convenience that fixes the parameter as required or
 	// optional regardless of the original tag. Equivalent to
 	// SetRequiredFn(func() bool { return val }).
@@ -370,7 +433,58 @@ type processingContext struct {
 	// is populated from b.reloadFactory at the top of toCobraBaseImpl so
 	// HookContext.reloadAny / boa.Reload can invoke it without knowing
 	// about the outer Cmd.
-	reloadFactory func() (any, error)
+	reloadFactory func(args []string, running *processingContext) (any, error)
+
+	// expandedArgs are the args after argfile expansion, recorded so a
+	// reload replays exactly what the original run parsed. nil unless
+	// Cmd.ArgFiles is set.
+	expandedArgs []string
+
+	// exitCodeMapper and cleanRunErrors are Cmd.ExitCodeMapper and
+	// Cmd.CleanRunErrors, looked up by Run from the executed command up.
+	exitCodeMapper func(error) int
+	cleanRunErrors bool
+
+	// errorRenderer is Cmd.ErrorRenderer, looked up from the failed
+	// command up (see errorRendererFor).
+	errorRenderer ErrorRenderer
+
+	// cmd is the cobra command built along with this context.
+	cmd *cobra.Command
+	// lookupEnvFunc is Cmd.LookupEnv, also used by subcommands without
+	// one of their own (see processingContext.lookupEnv).
+	lookupEnvFunc func(key string) (string, bool)
+	// environFunc is Cmd.Environ, inherited like lookupEnvFunc.
+	environFunc func() []string
+	// fsys is Cmd.FS, also used by subcommands without one of their own
+	// (see processingContext.fileSystem).
+	fsys fs.FS
+
+	// middleware is Cmd.Middleware, looked up by subcommands through the
+	// describe registry to inherit it (see middlewareFor).
+	middleware []Middleware
+
+	// deferred are the funcs registered with HookContext.Defer, run in
+	// reverse order once the command is done (see wrapDeferred).
+	deferred []func() error
+
+	// argFileErr is why argfile expansion failed. It is reported by the
+	// command's Args validator, before anything is parsed.
+	argFileErr error
+
+	// groupOnly is set for commands that have no run function and only
+	// show help, though boa makes them runnable to reject unknown
+	// subcommands.
+	groupOnly bool
+
+	// restParam is the `boa:"rest"` parameter receiving the args after
+	// `--`, or nil if the command doesn't declare one. At most one is
+	// allowed per command.
+	restParam Param
+
+	// confirm is the "Are you sure?" step run before the run function, or
+	// nil if the command has no Confirm / ConfirmFunc.
+	confirm *confirmGate
 }
 
 // preallocateStructPtrs walks the struct tree and allocates any nil struct pointer fields,
@@ -552,6 +666,28 @@ func canonicalizeKeyTree(raw map[string]any, targetType reflect.Type, tag string
 				continue
 			}
 		}
+		// Elements of slices and maps of structs are canonicalized too, so
+		// their keys can tell which element fields the file set.
+		if ft.Kind() == reflect.Slice && isStructElemType(ft.Elem()) {
+			if elems, ok := rawVal.([]any); ok {
+				canon := make([]any, len(elems))
+				for j, e := range elems {
+					canon[j] = canonicalizeKeyTree(asKeyMap(e), ft.Elem(), tag)
+				}
+				out[sf.Name] = canon
+				continue
+			}
+		}
+		if ft.Kind() == reflect.Map && ft.Key().Kind() == reflect.String && isStructElemType(ft.Elem()) {
+			if entries := asKeyMap(rawVal); entries != nil {
+				canon := make(map[string]any, len(entries))
+				for k, e := range entries {
+					canon[k] = canonicalizeKeyTree(asKeyMap(e), ft.Elem(), tag)
+				}
+				out[sf.Name] = canon
+				continue
+			}
+		}
 		out[sf.Name] = rawVal
 	}
 	return out
@@ -689,6 +825,7 @@ func markConfigKeysPresentInStruct(ctx *processingContext, structPtr any, keys m
 			if mirror, ok := ctx.mirrorByPath[joinPath(childPath)]; ok {
 				if pm, isPM := mirror.(*paramMeta); isPM && !pm.ignored {
 					pm.setByConfig = true
+					pm.configElemFields = configElemFields(field.Type, rawVal)
 				}
 			}
 		}
@@ -889,130 +1026,167 @@ func parseEnv(ctx *processingContext, structPtr any) error {
 			return nil
 		}
 
-		if err := readEnv(param); err != nil {
+		// Rest args only ever come from the argv tail after `--`.
+		if param.IsRestArgs() {
+			return nil
+		}
+
+		if err := readEnv(ctx, param); err != nil {
 			return err
 		}
 
 		return nil
 	}, nil)
-	return newUserInputError(err)
+	return withExitCode(err, ExitValidation)
 }
 
 func validate(ctx *processingContext, structPtr any) error {
 
 	err := traverse(ctx, structPtr, func(param Param, _ string, _ reflect.StructTag) error {
-
-		if !param.IsEnabled() {
-			return nil
+		if err := validateParam(ctx, param); err != nil {
+			return &ValidationError{Param: param.GetName(), Env: param.GetEnv(), Positional: param.IsPositional(), Err: err}
 		}
+		return nil
+	}, nil)
+	return withExitCode(err, ExitValidation)
+}
 
-		// Fully ignored params are skipped end-to-end: no required check,
-		// no conversion, no alt/min/max/pattern/custom validation. Config
-		// files write directly to the raw struct field via unmarshal, so
-		// their values still land — just without any boa-layer processing.
-		if param.IsIgnored() {
-			return nil
-		}
+// validateParam runs the required check, post-parse conversion, transforms
+// and every validator (alts, custom, min/max/pattern) for one param. env is
+// what env-reading transforms like expand-home read.
+func validateParam(env envSource, param Param) error {
 
-		envHint := ""
-		if param.GetEnv() != "" {
-			envHint = fmt.Sprintf(" (env: %s)", param.GetEnv())
-		}
+	if !param.IsEnabled() {
+		return nil
+	}
 
-		if param.IsRequired() && !HasValue(param) {
-			return fmt.Errorf("missing required param '%s'%s", param.GetName(), envHint)
+	// Fully ignored params are skipped end-to-end: no required check,
+	// no conversion, no alt/min/max/pattern/custom validation. Config
+	// files write directly to the raw struct field via unmarshal, so
+	// their values still land — just without any boa-layer processing.
+	if param.IsIgnored() {
+		return nil
+	}
+
+	envHint := ""
+	if param.GetEnv() != "" {
+		envHint = fmt.Sprintf(" (env: %s)", param.GetEnv())
+	}
+
+	if param.IsRequired() && !HasValue(param) {
+		return fmt.Errorf("missing required param '%s'%s", param.GetName(), envHint)
+	}
+
+	// A rest param without `--` has an empty tail as far as its bounds go,
+	// so `min:"1"` requires the `--` rather than being skipped.
+	if param.IsRestArgs() && !HasValue(param) {
+		if pm, ok := param.(*paramMeta); ok {
+			if err := validateMinMaxPattern(pm, &[]string{}); err != nil {
+				return fmt.Errorf("invalid value for param '%s': %s", param.GetName(), err.Error())
+			}
 		}
+	}
 
-		// Post-parse conversion for types stored as strings in cobra (time.Time, *url.URL, JSON fallback, etc.)
-		if HasValue(param) {
-			converted := false
-			if handler, _ := lookupHandler(param.GetType()); handler != nil && handler.convert != nil {
-				res, err := handler.convert(param.GetName(), param.valuePtrF())
+	// Post-parse conversion for types stored as strings in cobra (time.Time, *url.URL, JSON fallback, etc.)
+	if HasValue(param) {
+		converted := false
+		if handler := paramHandler(param); handler != nil && handler.convert != nil {
+			res, err := handler.convert(param.GetName(), param.valuePtrF())
+			if err != nil {
+				return err
+			}
+			param.setValuePtr(res)
+			converted = true
+		} else if param.GetKind() == reflect.Map {
+			if mapHandler := lookupMapHandler(param.GetType()); mapHandler != nil && mapHandler.convert != nil {
+				res, err := mapHandler.convert(param.GetName(), param.valuePtrF())
 				if err != nil {
 					return err
 				}
 				param.setValuePtr(res)
 				converted = true
-			} else if param.GetKind() == reflect.Map {
-				if mapHandler := lookupMapHandler(param.GetType()); mapHandler != nil && mapHandler.convert != nil {
-					res, err := mapHandler.convert(param.GetName(), param.valuePtrF())
-					if err != nil {
-						return err
-					}
-					param.setValuePtr(res)
-					converted = true
+			}
+		} else if param.GetKind() == reflect.Slice {
+			if sliceHandler := paramSliceHandler(param); sliceHandler != nil && sliceHandler.convert != nil {
+				res, err := sliceHandler.convert(param.GetName(), param.valuePtrF())
+				if err != nil {
+					return err
 				}
-			} else if param.GetKind() == reflect.Slice {
-				if sliceHandler := lookupSliceHandler(param.GetType().Elem()); sliceHandler != nil && sliceHandler.convert != nil {
-					res, err := sliceHandler.convert(param.GetName(), param.valuePtrF())
+				param.setValuePtr(res)
+				converted = true
+			}
+		}
+
+		// JSON fallback conversion: if value is still a *string but the target type
+		// is a complex type (map, nested slice, etc.) without a native handler, try JSON unmarshal
+		if !converted {
+			needsJsonFallback := false
+			if param.GetKind() == reflect.Map {
+				needsJsonFallback = lookupMapHandler(param.GetType()) == nil
+			} else if param.GetKind() == reflect.Slice && lookupSliceHandler(param.GetType().Elem()) == nil {
+				needsJsonFallback = true
+			}
+			if needsJsonFallback {
+				if strPtr, ok := param.valuePtrF().(*string); ok && strPtr != nil && *strPtr != "" {
+					fallback := jsonFallbackHandler(param.GetType())
+					res, err := fallback.convert(param.GetName(), param.valuePtrF())
 					if err != nil {
 						return err
 					}
 					param.setValuePtr(res)
-					converted = true
 				}
 			}
+		}
+		if pm, ok := param.(*paramMeta); ok {
+			if err := applyTransforms(env, pm); err != nil {
+				return fmt.Errorf("invalid value for param '%s': %s", param.GetName(), err.Error())
+			}
+		}
 
-			// JSON fallback conversion: if value is still a *string but the target type
-			// is a complex type (map, nested slice, etc.) without a native handler, try JSON unmarshal
-			if !converted {
-				needsJsonFallback := false
-				if param.GetKind() == reflect.Map {
-					needsJsonFallback = lookupMapHandler(param.GetType()) == nil
-				} else if param.GetKind() == reflect.Slice && lookupSliceHandler(param.GetType().Elem()) == nil {
-					needsJsonFallback = true
-				}
-				if needsJsonFallback {
-					if strPtr, ok := param.valuePtrF().(*string); ok && strPtr != nil && *strPtr != "" {
-						fallback := jsonFallbackHandler(param.GetType())
-						res, err := fallback.convert(param.GetName(), param.valuePtrF())
-						if err != nil {
-							return err
-						}
-						param.setValuePtr(res)
-					}
-				}
+		if h := compositeHandler(param); h != nil && h.validateElems != nil {
+			fillDefaults := !param.wasSetOnCli() && !param.wasSetByEnv()
+			if err := h.validateElems(param.GetName(), param.valuePtrF(), fillDefaults, givenElemFields(param)); err != nil {
+				return err
 			}
-			if alts := param.GetAlternatives(); alts != nil && param.GetStrictAlts() {
+		}
 
-				ptrVal := param.valuePtrF()
-				// check if it is a slice param
-				kind := reflect.TypeOf(ptrVal).Elem().Kind()
-				if kind == reflect.Slice {
-					// run the validation for each slice element
-					sliceVal := reflect.ValueOf(ptrVal).Elem()
-					for i := 0; i < sliceVal.Len(); i++ {
-						elem := sliceVal.Index(i)
-						strVal := fmt.Sprintf("%v", elem.Interface())
-						if !slices.Contains(alts, strVal) {
-							return fmt.Errorf("invalid value for param '%s': '%s' is not in the list of allowed values: %v", param.GetName(), strVal, alts)
-						}
+		if alts := param.GetAlternatives(); alts != nil && param.GetStrictAlts() {
+
+			ptrVal := param.valuePtrF()
+			// check if it is a slice param
+			kind := reflect.TypeOf(ptrVal).Elem().Kind()
+			if kind == reflect.Slice {
+				// run the validation for each slice element
+				sliceVal := reflect.ValueOf(ptrVal).Elem()
+				for i := 0; i < sliceVal.Len(); i++ {
+					strVal := altString(sliceVal.Index(i))
+					if !slices.Contains(alts, strVal) {
+						return fmt.Errorf("invalid value for param '%s': '%s' is not in the list of allowed values: %v", param.GetName(), strVal, alts)
 					}
-				} else {
-					if ptrVal != nil {
-						strVal := ptrToAnyToString(ptrVal)
-						if !slices.Contains(alts, strVal) {
-							return fmt.Errorf("invalid value for param '%s': '%s' is not in the list of allowed values: %v", param.GetName(), strVal, alts)
-						}
+				}
+			} else {
+				if ptrVal != nil {
+					strVal := altString(reflect.ValueOf(ptrVal))
+					if !slices.Contains(alts, strVal) {
+						return fmt.Errorf("invalid value for param '%s': '%s' is not in the list of allowed values: %v", param.GetName(), strVal, alts)
 					}
 				}
 			}
+		}
 
-			if err := param.customValidatorOfPtr()(param.valuePtrF()); err != nil {
-				return fmt.Errorf("invalid value for param '%s': %s", param.GetName(), err.Error())
-			}
+		if err := param.customValidatorOfPtr()(param.valuePtrF()); err != nil {
+			return fmt.Errorf("invalid value for param '%s': %s", param.GetName(), err.Error())
+		}
 
-			// min/max/pattern tag validation
-			if pm, ok := param.(*paramMeta); ok {
-				if err := validateMinMaxPattern(pm, param.valuePtrF()); err != nil {
-					return fmt.Errorf("invalid value for param '%s': %s", param.GetName(), err.Error())
-				}
+		// min/max/pattern tag validation
+		if pm, ok := param.(*paramMeta); ok {
+			if err := validateMinMaxPattern(pm, param.valuePtrF()); err != nil {
+				return fmt.Errorf("invalid value for param '%s': %s", param.GetName(), err.Error())
 			}
 		}
+	}
 
-		return nil
-	}, nil)
-	return newUserInputError(err)
+	return nil
 }
 
 // parseBoundTag parses a `min:"..."` or `max:"..."` tag value against the
@@ -1051,10 +1225,20 @@ func parseBoundTag(bk boundKind, s string) (any, error) {
 		}
 		n := int(v)
 		return &n, nil
+	case bigBound:
+		v, _, err := big.ParseFloat(s, 10, bigBoundPrec, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("expected number, got %q: %w", s, err)
+		}
+		return v, nil
 	}
 	return nil, nil
 }
 
+// bigBoundPrec is the mantissa precision used for big.Int/big.Float bounds.
+// Comfortably exact for any bound a human writes in a struct tag.
+const bigBoundPrec = 512
+
 // validateMinMaxPattern checks min/max/pattern tag constraints. It dispatches
 // on the field's boundKind and compares against the typed-pointer bound stored
 // in pm.minVal / pm.maxVal. The storage type is guaranteed to match the field
@@ -1074,10 +1258,10 @@ func validateMinMaxPattern(pm *paramMeta, valPtr any) error {
 	case signedIntBound:
 		val := v.Int()
 		if minP, ok := pm.minVal.(*int64); ok && minP != nil && val < *minP {
-			return fmt.Errorf("value %d is below min %d", val, *minP)
+			return fmt.Errorf("value %s is below min %s", pm.formatBound(val), pm.formatBound(*minP))
 		}
 		if maxP, ok := pm.maxVal.(*int64); ok && maxP != nil && val > *maxP {
-			return fmt.Errorf("value %d exceeds max %d", val, *maxP)
+			return fmt.Errorf("value %s exceeds max %s", pm.formatBound(val), pm.formatBound(*maxP))
 		}
 	case unsignedIntBound:
 		val := v.Uint()
@@ -1090,10 +1274,10 @@ func validateMinMaxPattern(pm *paramMeta, valPtr any) error {
 	case floatBound:
 		val := v.Float()
 		if minP, ok := pm.minVal.(*float64); ok && minP != nil && val < *minP {
-			return fmt.Errorf("value %v is below min %v", val, *minP)
+			return fmt.Errorf("value %s is below min %s", pm.formatBound(val), pm.formatBound(*minP))
 		}
 		if maxP, ok := pm.maxVal.(*float64); ok && maxP != nil && val > *maxP {
-			return fmt.Errorf("value %v exceeds max %v", val, *maxP)
+			return fmt.Errorf("value %s exceeds max %s", pm.formatBound(val), pm.formatBound(*maxP))
 		}
 	case lengthBound:
 		var l int
@@ -1109,6 +1293,31 @@ func validateMinMaxPattern(pm *paramMeta, valPtr any) error {
 		if maxP, ok := pm.maxVal.(*int); ok && maxP != nil && l > *maxP {
 			return fmt.Errorf("length %d exceeds max %d", l, *maxP)
 		}
+	case timeBound:
+		val := v.Interface().(time.Time)
+		now := time.Now()
+		tf := pm.timeFormat()
+		if minP, ok := pm.minVal.(*timeBoundValue); ok && minP != nil && val.Before(minP.resolve(now)) {
+			return fmt.Errorf("value %s is before min %s", tf.format(val), minP.describe(tf, now))
+		}
+		if maxP, ok := pm.maxVal.(*timeBoundValue); ok && maxP != nil && val.After(maxP.resolve(now)) {
+			return fmt.Errorf("value %s is after max %s", tf.format(val), maxP.describe(tf, now))
+		}
+	case bigBound:
+		ptr := addressable(v).Interface()
+		val := new(big.Float).SetPrec(bigBoundPrec)
+		switch x := ptr.(type) {
+		case *big.Int:
+			val.SetInt(x)
+		case *big.Float:
+			val.Set(x)
+		}
+		if minP, ok := pm.minVal.(*big.Float); ok && minP != nil && val.Cmp(minP) < 0 {
+			return fmt.Errorf("value %v is below min %v", ptr, minP)
+		}
+		if maxP, ok := pm.maxVal.(*big.Float); ok && maxP != nil && val.Cmp(maxP) > 0 {
+			return fmt.Errorf("value %v exceeds max %v", ptr, maxP)
+		}
 	}
 
 	if pm.pattern != "" && v.Kind() == reflect.String {
@@ -1262,6 +1471,10 @@ func connect(f Param, cmd *cobra.Command, posArgs []Param, ctx *processingContex
 	}
 	f.setParentCmd(cmd)
 
+	if f.IsRestArgs() {
+		return nil // bound after all positionals, see connectRestArgs
+	}
+
 	if f.isPositional() {
 		startSign := func() string {
 			if f.IsRequired() {
@@ -1313,7 +1526,7 @@ func connect(f Param, cmd *cobra.Command, posArgs []Param, ctx *processingContex
 						f.setValuePtr(f.defaultValuePtr())
 						return nil
 					} else {
-						return newUserInputErrorf("missing positional arg '%s'", f.GetName())
+						return newUserInputError(&ValidationError{Param: f.GetName(), Env: f.GetEnv(), Positional: true, Err: fmt.Errorf("missing positional arg '%s'", f.GetName())})
 					}
 				} else {
 					return nil
@@ -1333,31 +1546,19 @@ func connect(f Param, cmd *cobra.Command, posArgs []Param, ctx *processingContex
 
 	// Must happen last, because the flags must have been created
 	defer func() {
-		if f.GetAlternatives() != nil {
-			err := cmd.RegisterFlagCompletionFunc(f.GetName(), func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-				return f.GetAlternatives(), cobra.ShellCompDirectiveDefault
-			})
-			if err != nil {
-				panic(fmt.Errorf("failed to register static flag completion func for flag '%s': %v", f.GetName(), err))
-			}
+		// A completion registered by the user's own hooks wins
+		if _, registered := cmd.GetFlagCompletionFunc(f.GetName()); registered {
+			return
 		}
-		if f.GetAlternativesFunc() != nil {
-			err := cmd.RegisterFlagCompletionFunc(f.GetName(), func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-				// Sync cobra's parsed flag values into raw struct fields before calling
-				// the user's completion function. Without this, raw fields (plain string,
-				// int, etc.) are zero during completion because PreRunE (which normally
-				// calls syncMirrors) is never executed for shell completion.
-				syncMirrors(ctx)
-				return f.GetAlternativesFunc()(cmd, args, toComplete), cobra.ShellCompDirectiveDefault
-			})
-			if err != nil {
-				panic(fmt.Errorf("failed to register dynamic flag completion func for flag '%s': %v", f.GetName(), err))
+		if complete := paramCompletion(ctx, f); complete != nil {
+			if err := cmd.RegisterFlagCompletionFunc(f.GetName(), complete); err != nil {
+				panic(fmt.Errorf("failed to register flag completion func for flag '%s': %v", f.GetName(), err))
 			}
 		}
 	}()
 
 	// Look up type handler for scalar types (including net.IP which is []byte but treated as scalar)
-	if handler, _ := lookupHandler(f.GetType()); handler != nil {
+	if handler := paramHandler(f); handler != nil {
 		var defVal any
 		if f.hasDefaultValue() {
 			defVal = f.defaultValuePtr()
@@ -1382,8 +1583,7 @@ func connect(f Param, cmd *cobra.Command, posArgs []Param, ctx *processingContex
 
 	// Slice types — try native handler first, then JSON fallback
 	if f.GetKind() == reflect.Slice {
-		elemType := f.GetType().Elem()
-		sliceHandler := lookupSliceHandler(elemType)
+		sliceHandler := paramSliceHandler(f)
 
 		if sliceHandler != nil {
 			var defVal any
@@ -1420,7 +1620,7 @@ func connect(f Param, cmd *cobra.Command, posArgs []Param, ctx *processingContex
 	return fmt.Errorf("unsupported param type: %s", f.GetKind().String())
 }
 
-func readEnv(f Param) error {
+func readEnv(env envSource, f Param) error {
 	if f.GetEnv() == "" {
 		return nil
 	}
@@ -1429,7 +1629,22 @@ func readEnv(f Param) error {
 		return nil
 	}
 
-	envVal := os.Getenv(f.GetEnv())
+	if h := compositeHandler(f); h != nil && h.readEnv != nil {
+		ptr, given, err := h.readEnv(f.GetName(), f.GetEnv(), env)
+		if err != nil {
+			return err
+		}
+		if given != nil {
+			f.setValuePtr(ptr)
+			f.markSetFromEnv()
+			if pm, ok := f.(*paramMeta); ok {
+				pm.envElemFields = given
+			}
+		}
+		return nil
+	}
+
+	envVal, _ := env.lookupEnv(f.GetEnv())
 	if envVal == "" {
 		return nil
 	}
@@ -1443,9 +1658,24 @@ func readEnv(f Param) error {
 	return nil
 }
 
+// compositeHandler returns the slice or map handler for a param of a
+// composite type, or nil for scalars and JSON-fallback types.
+func compositeHandler(f Param) *typeHandler {
+	switch f.GetKind() {
+	case reflect.Slice:
+		if h, _ := lookupHandler(f.GetType()); h != nil {
+			return nil // scalar stored as a slice, e.g. net.IP
+		}
+		return paramSliceHandler(f)
+	case reflect.Map:
+		return lookupMapHandler(f.GetType())
+	}
+	return nil
+}
+
 func readFrom(f Param, strVal string) error {
 
-	ptr, err := parsePtr(f.GetName(), f.GetType(), f.GetKind(), strVal)
+	ptr, err := parseParamPtr(f, strVal)
 	if err != nil {
 		return err
 	}
@@ -1455,23 +1685,6 @@ func readFrom(f Param, strVal string) error {
 	return nil
 }
 
-// parseTimeString parses a time string trying multiple common formats
-func parseTimeString(s string) (time.Time, error) {
-	formats := []string{
-		time.RFC3339,
-		time.RFC3339Nano,
-		"2006-01-02",
-		"2006-01-02T15:04:05",
-		"2006-01-02 15:04:05",
-	}
-	for _, format := range formats {
-		if t, err := time.Parse(format, s); err == nil {
-			return t, nil
-		}
-	}
-	return time.Time{}, fmt.Errorf("unable to parse time: %s", s)
-}
-
 func parsePtr(
 	name string,
 	tpe reflect.Type,
@@ -1567,6 +1780,70 @@ func getBoaTags(field reflect.StructField) []string {
 	return results
 }
 
+// isRestArgsTag reports whether the struct tags mark a field as the rest-args
+// receiver, via `boa:"rest"` or `passthrough:"true"`.
+func isRestArgsTag(tags reflect.StructTag) bool {
+	if tags.Get("passthrough") == "true" {
+		return true
+	}
+	for _, t := range strings.Split(tags.Get("boa"), ",") {
+		if strings.TrimSpace(t) == "rest" {
+			return true
+		}
+	}
+	return false
+}
+
+// connectRestArgs appends the `[-- name...]` marker to the Use line and wraps
+// cmd.Args so that every positional validator and parser only sees the args
+// before `--`, while the tail is stored verbatim in the rest param. Runs after
+// all regular positionals are connected so the marker always comes last.
+func connectRestArgs(f Param, cmd *cobra.Command) {
+	cmd.Use += " [-- " + f.GetName() + "...]"
+
+	inner := cmd.Args
+	if inner == nil {
+		inner = wrapArgsValidator(legacyArgs)
+	}
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		before, tail := args, []string(nil)
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			before, tail = args[:dash], append([]string{}, args[dash:]...)
+		}
+		if err := inner(cmd, before); err != nil {
+			return err
+		}
+		if tail != nil {
+			f.setValuePtr(&tail)
+			f.markSetPositionally()
+		}
+		return nil
+	}
+}
+
+// legacyArgs is what cobra validates args with when cmd.Args is nil, which
+// it stops doing once connectRestArgs installs a validator: any args are
+// accepted, except that a root command with subcommands rejects unknown
+// subcommands.
+func legacyArgs(cmd *cobra.Command, args []string) error {
+	if !cmd.HasSubCommands() || cmd.HasParent() || len(args) == 0 {
+		return nil
+	}
+	var suggestions strings.Builder
+	if !cmd.DisableSuggestions {
+		if cmd.SuggestionsMinimumDistance <= 0 {
+			cmd.SuggestionsMinimumDistance = 2
+		}
+		if found := cmd.SuggestionsFor(args[0]); len(found) > 0 {
+			suggestions.WriteString("\n\nDid you mean this?\n")
+			for _, s := range found {
+				fmt.Fprintf(&suggestions, "\t%v\n", s)
+			}
+		}
+	}
+	return fmt.Errorf("unknown command %q for %q%s", args[0], cmd.CommandPath(), suggestions.String())
+}
+
 // positionalSkipError returns the canonical error for a param that is marked
 // both positional and "skipped from cobra" (either noflag or ignored). A
 // positional param must consume argv, so skipping its cobra binding leaves
@@ -1763,19 +2040,40 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		ValidArgs:     b.ValidArgs,
-	}
-
-	if b.RawArgs != nil {
-		cmd.SetArgs(b.RawArgs)
+		Example:       b.Example,
 	}
 
 	ctx := &processingContext{
-		Context:       context.Background(), // prepare to override later?
+		Context:       context.Background(), // replaced while running, see wrapRunContext
 		rootStructPtr: b.Params,
 		mirrorByPath:  map[fieldPath]Param{},
 		pathOrder:     []fieldPath{},
 		addrToPath:    map[unsafe.Pointer]fieldPath{},
 		reloadFactory: b.reloadFactory,
+		cmd:           cmd,
+		lookupEnvFunc: b.LookupEnv,
+		environFunc:   b.Environ,
+		fsys:          b.FS,
+	}
+
+	// Installed first so that hooks calling cmd.SetHelpFunc replace it
+	installHelpSections(cmd, ctx)
+	registerDescribe(cmd, ctx)
+
+	if b.ArgFiles {
+		args := b.RawArgs
+		if args == nil {
+			args = os.Args[1:]
+		}
+		expanded, err := expandArgFiles(b.FS, args)
+		if err != nil {
+			ctx.argFileErr = err
+			expanded = []string{}
+		}
+		ctx.expandedArgs = expanded
+		cmd.SetArgs(expanded)
+	} else if b.RawArgs != nil {
+		cmd.SetArgs(b.RawArgs)
 	}
 
 	// Preallocate nil struct pointer fields so traverse can discover their children.
@@ -1794,45 +2092,18 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 
 	syncMirrors(ctx)
 
-	// if b.params or any inner struct implements CfgStructInit, call it
-	if b.Params != nil {
-		err := traverse(ctx, b.Params, nil, func(innerParams any) error {
-			if toInit, ok := b.Params.(CfgStructInit); ok {
-				err := toInit.Init()
-				if err != nil {
-					return fmt.Errorf("error in CfgStructInit.Init(): %w", err)
-				}
-			}
-			// context-aware interface
-			if toInitCtx, ok := b.Params.(CfgStructInitCtx); ok {
-				hookCtx := newHookContext(ctx)
-				err := toInitCtx.InitCtx(hookCtx)
-				if err != nil {
-					return fmt.Errorf("error in CfgStructInitCtx.InitCtx(): %w", err)
-				}
-			}
-			return nil
-		})
-		if err != nil {
-			return nil, nil, err
-		}
-	}
-
-	// if we have a custom init function, call it
-	if b.InitFunc != nil {
-		err := b.InitFunc(b.Params, cmd)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error in InitFunc: %w", err)
-		}
-	}
-
-	// if we have a context-aware init function, call it
-	if b.InitFuncCtx != nil {
-		hookCtx := newHookContext(ctx)
-		err := b.InitFuncCtx(hookCtx, b.Params, cmd)
-		if err != nil {
-			return nil, nil, fmt.Errorf("error in InitFuncCtx: %w", err)
-		}
+	// Init hooks run through the command's own middleware; inherited
+	// middleware can't see them, as subcommands are built before their parent
+	ctx.middleware = b.Middleware
+	ctx.exitCodeMapper = b.ExitCodeMapper
+	ctx.cleanRunErrors = b.CleanRunErrors
+	ctx.errorRenderer = b.ErrorRenderer
+	initInv := &Invocation{Phase: PhaseInit, Cmd: cmd, Params: b.Params, Ctx: newHookContext(ctx)}
+	err := chainMiddleware(b.Middleware, func(*Invocation) error {
+		return b.runInitHooks(ctx, cmd)
+	})(initInv)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	syncMirrors(ctx)
@@ -1915,19 +2186,7 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 			}
 
 			setAlts := func(alts string) {
-				strVal := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(alts), "["), "]")
-				elements := strings.Split(strVal, ",")
-				for i, element := range elements {
-					elements[i] = strings.TrimSpace(element)
-				}
-				// Remove empty
-				nonEmpty := make([]string, 0)
-				for _, element := range elements {
-					if element != "" {
-						nonEmpty = append(nonEmpty, element)
-					}
-				}
-				param.SetAlternatives(nonEmpty)
+				param.SetAlternatives(splitAltsTag(alts))
 			}
 
 			if alts, ok := tags.Lookup("alts"); ok {
@@ -1937,6 +2196,39 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 				setAlts(alts)
 			}
 
+			if question, ok := tags.Lookup("prompt"); ok && param.GetPrompt() == "" {
+				param.SetPrompt(question)
+			}
+			if tags.Get("secret") == "true" {
+				param.SetSecret(true)
+			}
+			if sample, ok := tags.Lookup("example"); ok && param.GetExample() == "" {
+				param.SetExample(sample)
+			}
+			if spec, ok := tags.Lookup("complete"); ok && param.GetCompletion() == "" {
+				if _, _, err := parseCompletion(spec); err != nil {
+					return fmt.Errorf("invalid complete tag for param %s: %s", param.GetName(), err.Error())
+				}
+				param.SetCompletion(spec)
+			}
+
+			if tr, ok := tags.Lookup("transform"); ok && param.GetTransforms() == nil {
+				names := splitAltsTag(tr)
+				if !supportsTransforms(param.GetType()) {
+					return fmt.Errorf("invalid transform tag for param %s: transforms only apply to strings and string slices", param.GetName())
+				}
+				if err := checkTransforms(names); err != nil {
+					return fmt.Errorf("invalid transform tag for param %s: %s", param.GetName(), err.Error())
+				}
+				param.SetTransforms(names)
+			}
+
+			if param.GetAlternatives() == nil {
+				if names := enumNames(param.GetType()); names != nil {
+					param.SetAlternatives(names)
+				}
+			}
+
 			if strictAlts, ok := tags.Lookup("strict-alts"); ok {
 				param.SetStrictAlts(strictAlts == "true")
 			}
@@ -1944,10 +2236,28 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 				param.SetStrictAlts(strictAlts == "true")
 			}
 
+			// Time layout / zone come before the default, which is parsed with them
+			if layout, ok := tags.Lookup("layout"); ok && param.GetTimeLayout() == "" {
+				if pm, ok2 := param.(*paramMeta); ok2 && !pm.isTimeParam() {
+					return fmt.Errorf("invalid layout tag for param %s: only time.Time fields take a layout", param.GetName())
+				}
+				param.SetTimeLayout(layout)
+			}
+			if tz, ok := lookupTimeZoneTag(tags); ok && param.GetTimeZone() == nil {
+				if pm, ok2 := param.(*paramMeta); ok2 && !pm.isTimeParam() {
+					return fmt.Errorf("invalid tz tag for param %s: only time.Time fields take a time zone", param.GetName())
+				}
+				loc, err := time.LoadLocation(tz)
+				if err != nil {
+					return fmt.Errorf("invalid tz tag for param %s: unknown time zone %q", param.GetName(), tz)
+				}
+				param.SetTimeZone(loc)
+			}
+
 			if !param.hasDefaultValue() {
 				// Default values are used for injection. So we can't just overwrite them
 				if defaultPtr, ok := tags.Lookup("default"); ok {
-					ptr, err := parsePtr(param.GetName(), param.GetType(), param.GetKind(), defaultPtr)
+					ptr, err := parseParamPtr(param, defaultPtr)
 					if err != nil {
 						return fmt.Errorf("invalid default value for param %s: %s", param.GetName(), err.Error())
 					}
@@ -1958,7 +2268,7 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 			// Parse min/max/pattern validation tags
 			if pm, ok := param.(*paramMeta); ok {
 				if minStr, ok := tags.Lookup("min"); ok {
-					ptr, err := parseBoundTag(pm.boundKind(), minStr)
+					ptr, err := pm.parseBound(minStr)
 					if err != nil {
 						return fmt.Errorf("invalid min value for param %s: %s", param.GetName(), err.Error())
 					}
@@ -1967,7 +2277,7 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 					}
 				}
 				if maxStr, ok := tags.Lookup("max"); ok {
-					ptr, err := parseBoundTag(pm.boundKind(), maxStr)
+					ptr, err := pm.parseBound(maxStr)
 					if err != nil {
 						return fmt.Errorf("invalid max value for param %s: %s", param.GetName(), err.Error())
 					}
@@ -2076,6 +2386,18 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 		}
 
 		for _, param := range processed {
+			if param.IsRestArgs() {
+				if param.isPositional() {
+					return nil, nil, fmt.Errorf("param %s: rest args cannot also be positional", param.GetName())
+				}
+				if param.GetType() != reflect.TypeOf([]string{}) {
+					return nil, nil, fmt.Errorf("param %s: rest args must be a []string field, got %s", param.GetName(), param.GetType())
+				}
+				if ctx.restParam != nil {
+					return nil, nil, fmt.Errorf("param %s: only one rest args param is allowed per command, already have %s", param.GetName(), ctx.restParam.GetName())
+				}
+				ctx.restParam = param
+			}
 			if param.isPositional() {
 				// if the last positional is a slice, error out
 				if len(positional) >= 1 {
@@ -2127,6 +2449,10 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 			return nil
 		}, nil)
 
+		if err == nil && ctx.restParam != nil {
+			connectRestArgs(ctx.restParam, cmd)
+		}
+
 		// if b.Params implements CfgStructPostCreate, call it
 		if postCreate, ok := b.Params.(CfgStructPostCreate); ok {
 			if err := postCreate.PostCreate(); err != nil {
@@ -2157,13 +2483,24 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 		}
 	}
 
-	// Build ValidArgsFunction from per-positional-param Alternatives/AlternativesFunc
-	// and/or the user-provided ValidArgsFunc. Per-param completions are checked first
-	// for the current position; the user's ValidArgsFunc is used as fallback.
+	if err := b.addConfirmFlag(cmd, ctx, b.ParamEnrich); err != nil {
+		return nil, nil, err
+	}
+	if err := b.addHelpJSONFlag(cmd); err != nil {
+		return nil, nil, err
+	}
+	if err := b.addErrorFormatFlag(cmd); err != nil {
+		return nil, nil, err
+	}
+
+	// Build ValidArgsFunction from per-positional-param completions (see
+	// paramCompletion) and/or the user-provided ValidArgsFunc. Per-param
+	// completions are checked first for the current position; the user's
+	// ValidArgsFunc is used as fallback.
 	{
 		hasPositionalCompletion := false
-		for _, p := range positional {
-			if p.GetAlternatives() != nil || p.GetAlternativesFunc() != nil {
+		for i := range positional {
+			if paramCompletion(ctx, positional[i]) != nil {
 				hasPositionalCompletion = true
 				break
 			}
@@ -2172,15 +2509,8 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 			userValidArgsFunc := b.ValidArgsFunc
 			cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 				syncMirrors(ctx)
-				posIdx := len(args)
-				if posIdx < len(positional) {
-					p := positional[posIdx]
-					if p.GetAlternativesFunc() != nil {
-						return p.GetAlternativesFunc()(cmd, args, toComplete), cobra.ShellCompDirectiveDefault
-					}
-					if p.GetAlternatives() != nil {
-						return p.GetAlternatives(), cobra.ShellCompDirectiveDefault
-					}
+				if complete := positionalCompletion(ctx, positional, len(args)); complete != nil {
+					return complete(cmd, args, toComplete)
 				}
 				if userValidArgsFunc != nil {
 					return userValidArgsFunc(cmd, args, toComplete)
@@ -2267,9 +2597,9 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 						if filePath == "" {
 							continue
 						}
-						rawData, effective, err := loadConfigFileInto(filePath, entry.target, cmdOverride)
+						rawData, effective, err := loadConfigFileInto(ctx.fileSystem(), filePath, entry.target, cmdOverride)
 						if err != nil {
-							return NewUserInputError(fmt.Errorf("configfile %s: %w", entry.mirror.GetName(), err))
+							return withExitCode(fmt.Errorf("configfile %s: %w", entry.mirror.GetName(), err), ExitConfig)
 						}
 						configResults = append(configResults, configLoadResult{
 							target:     entry.target,
@@ -2366,6 +2696,14 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 
 			syncMirrors(ctx)
 
+			// Ask for whatever required values are still missing
+			if p := b.prompter(cmd); p != nil {
+				if err := promptForMissing(ctx, b.Params, b.Prompt, p); err != nil {
+					return err
+				}
+				syncMirrors(ctx)
+			}
+
 			if err = validate(ctx, b.Params); err != nil {
 				return err
 			}
@@ -2416,9 +2754,65 @@ func (b Cmd) toCobraBase() (*cobra.Command, *processingContext, error) {
 		return nil
 	}
 
+	// Per-entry map flags (--databases.primary.host=x) can't be registered
+	// up front, so they're rewritten into their base flag's pairs here.
+	args := b.RawArgs
+	if args == nil && len(os.Args) > 0 {
+		args = os.Args[1:]
+	}
+	if expanded, ok := expandEntryFlags(cmd, args); ok {
+		cmd.SetArgs(expanded)
+	}
+
 	return cmd, ctx, nil
 }
 
+// runInitHooks calls the Init hooks: the CfgStructInit interfaces, then
+// InitFunc and InitFuncCtx.
+func (b Cmd) runInitHooks(ctx *processingContext, cmd *cobra.Command) error {
+	// if b.params or any inner struct implements CfgStructInit, call it
+	if b.Params != nil {
+		err := traverse(ctx, b.Params, nil, func(innerParams any) error {
+			if toInit, ok := b.Params.(CfgStructInit); ok {
+				err := toInit.Init()
+				if err != nil {
+					return fmt.Errorf("error in CfgStructInit.Init(): %w", err)
+				}
+			}
+			// context-aware interface
+			if toInitCtx, ok := b.Params.(CfgStructInitCtx); ok {
+				hookCtx := newHookContext(ctx)
+				err := toInitCtx.InitCtx(hookCtx)
+				if err != nil {
+					return fmt.Errorf("error in CfgStructInitCtx.InitCtx(): %w", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// if we have a custom init function, call it
+	if b.InitFunc != nil {
+		err := b.InitFunc(b.Params, cmd)
+		if err != nil {
+			return fmt.Errorf("error in InitFunc: %w", err)
+		}
+	}
+
+	// if we have a context-aware init function, call it
+	if b.InitFuncCtx != nil {
+		hookCtx := newHookContext(ctx)
+		err := b.InitFuncCtx(hookCtx, b.Params, cmd)
+		if err != nil {
+			return fmt.Errorf("error in InitFuncCtx: %w", err)
+		}
+	}
+	return nil
+}
+
 // validateRunFuncs checks that at most one run function is set and returns an error if more than one is configured.
 func (b Cmd) validateRunFuncs() error {
 	runFuncCount := 0
@@ -2481,9 +2875,16 @@ func (b Cmd) toCobraImpl() *cobra.Command {
 			}
 			return err
 		}
-	} else if len(b.SubCmds) > 0 {
+	}
+	b.wrapPostExecute(cmd, ctx)
+	wrapConfirm(cmd, ctx)
+	wrapDeferred(cmd, ctx)
+	wrapMiddleware(cmd, ctx, b.Params)
+	wrapRunContext(cmd, ctx, b.runSignals(), b.Timeout)
+	if cmd.RunE == nil && len(b.SubCmds) > 0 {
 		// No RunFunc but has subcommands. Make the command runnable so cobra
 		// rejects unknown subcommands with an error instead of silently showing help.
+		ctx.groupOnly = true
 		cmd.RunE = func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
 		}
@@ -2497,6 +2898,8 @@ func (b Cmd) toCobraImpl() *cobra.Command {
 		}
 	}
 
+	reportArgFileErr(cmd, ctx)
+
 	return cmd
 }
 
@@ -2534,9 +2937,16 @@ func (b Cmd) toCobraImplE() (*cobra.Command, error) {
 			b.RunFuncCtx(hookCtx, cmd, args)
 			return nil
 		}
-	} else if len(b.SubCmds) > 0 {
+	}
+	b.wrapPostExecute(cmd, ctx)
+	wrapConfirm(cmd, ctx)
+	wrapDeferred(cmd, ctx)
+	wrapMiddleware(cmd, ctx, b.Params)
+	wrapRunContext(cmd, ctx, b.runSignals(), b.Timeout)
+	if cmd.RunE == nil && len(b.SubCmds) > 0 {
 		// No RunFunc but has subcommands. Make the command runnable so cobra
 		// rejects unknown subcommands with an error instead of silently showing help.
+		ctx.groupOnly = true
 		cmd.RunE = func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
 		}
@@ -2550,9 +2960,25 @@ func (b Cmd) toCobraImplE() (*cobra.Command, error) {
 		}
 	}
 
+	reportArgFileErr(cmd, ctx)
+
 	return cmd, nil
 }
 
+// reportArgFileErr makes a command whose argfiles couldn't be expanded fail
+// with that error, before flags or positional args are looked at.
+func reportArgFileErr(cmd *cobra.Command, ctx *processingContext) {
+	if ctx.argFileErr == nil {
+		return
+	}
+	err := newUserInputError(ctx.argFileErr)
+	cmd.Args = func(*cobra.Command, []string) error { return err }
+	if cmd.RunE == nil {
+		// cobra only checks Args for runnable commands
+		cmd.RunE = func(*cobra.Command, []string) error { return err }
+	}
+}
+
 // resolveFieldValue walks from the root params struct to the field at the given
 // declared-index path and returns an addressable reflect.Value for that field.
 // Returns (zero, false) if any intermediate pointer-to-struct is nil (which can
@@ -2724,19 +3150,25 @@ func runImpl(cmd *cobra.Command, handler resultHandler) {
 		}()
 	}
 
-	err := Execute(cmd)
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+	executedCmd, err := cmd.ExecuteC()
 	if err != nil {
+		// Errors from RunFuncE/RunFuncCtxE that aren't UserInputError are
+		// programming errors — panic so developers notice, unless they
+		// asked for an exit code or opted into CleanRunErrors.
+		var rfe *runFuncError
+		isRunErr := errors.As(err, &rfe)
+		clean := isRunErr && cleanRunErrorsFor(executedCmd, err)
+		panicking := isRunErr && !clean
+		printError(executedCmd, err, !clean)
 		if handler.Failure != nil {
 			handler.Failure(err)
 		} else {
-			// Errors from RunFuncE/RunFuncCtxE that aren't UserInputError are
-			// programming errors — panic so developers notice.
-			var rfe *runFuncError
-			if errors.As(err, &rfe) {
+			if panicking {
 				panic(rfe.Unwrap())
 			}
-			// Everything else: Execute() already printed usage + error.
-			osExit(1)
+			osExit(ExitCodeFor(executedCmd, err))
 			return // osExit may be mocked in tests, so we need to return explicitly
 		}
 	} else {
@@ -2747,8 +3179,8 @@ func runImpl(cmd *cobra.Command, handler resultHandler) {
 }
 
 func isSupportedType(t reflect.Type) bool {
-	// Exact type match (time.Time, time.Duration, net.IP, *url.URL)
-	if _, ok := exactTypeHandlers[t]; ok {
+	// Exact type match (time.Time, time.Duration, net.IP, *url.URL, pflag.Value, TextUnmarshaler)
+	if _, ok := exactHandler(t); ok {
 		return true
 	}
 	// Kind-based match (string, int, bool, float, etc.)
@@ -2775,7 +3207,7 @@ func isSupportedType(t reflect.Type) bool {
 // Special types (time.Time, time.Duration, net.IP, *url.URL) are returned as-is.
 func normalizeType(t reflect.Type) reflect.Type {
 	// Exact-match handlers have their own baseType (special types stay as-is)
-	if handler, ok := exactTypeHandlers[t]; ok {
+	if handler, ok := exactHandler(t); ok {
 		return handler.baseType
 	}
 
@@ -2783,7 +3215,8 @@ func normalizeType(t reflect.Type) reflect.Type {
 	if t.Kind() == reflect.Slice {
 		elem := t.Elem()
 		// Special slice element types stay as-is
-		if _, ok := sliceExactTypeHandlers[elem]; ok {
+		ensureHandlerRegistered(elem)
+		if _, ok := sliceExactHandler(elem); ok {
 			return t
 		}
 		// Normalize basic slice element types
@@ -2817,62 +3250,59 @@ func newParam(field *reflect.StructField, t reflect.Type) Param {
 	// required[int]{}, etc. regardless of whether the field was a type alias.
 	valueType = normalizeType(valueType)
 
-	// Pointer, map, and nested slice fields default to optional (nil = not set)
-	isRequired := !cfg.defaultOptional
-	if isPtr || valueType.Kind() == reflect.Map {
+	isRequired := defaultRequired(isPtr, valueType)
+	// Rest args default to optional — most wrappers accept an empty `--` tail
+	rest := isRestArgsTag(field.Tag)
+	if rest {
 		isRequired = false
 	}
-	// Nested slices ([][]T) default to optional — flat slices keep the global default
-	if valueType.Kind() == reflect.Slice && valueType.Elem().Kind() == reflect.Slice {
-		isRequired = false
+	if required, ok := requiredTag(*field); ok {
+		isRequired = required
 	}
 
-	if requiredTag, ok := field.Tag.Lookup("required"); ok {
-		switch requiredTag {
-		case "true":
-			isRequired = true
-		case "false":
-			isRequired = false
-		default:
-			panic(fmt.Errorf("invalid value for field %s's required tag: %s", field.Name, requiredTag))
-		}
+	return &paramMeta{
+		fieldType:       valueType,
+		isPointer:       isPtr,
+		defaultRequired: isRequired,
+		rest:            rest,
 	}
-	if requiredTag, ok := field.Tag.Lookup("req"); ok {
-		switch requiredTag {
-		case "true":
-			isRequired = true
-		case "false":
-			isRequired = false
-		default:
-			panic(fmt.Errorf("invalid value for field %s's required tag: %s", field.Name, requiredTag))
-		}
+}
+
+// defaultRequired reports whether a field without a required or optional
+// tag is required. Pointer, map, and nested slice fields default to optional
+// (nil = not set); everything else follows WithDefaultOptional.
+func defaultRequired(isPtr bool, valueType reflect.Type) bool {
+	if cfg.defaultOptional || isPtr || valueType.Kind() == reflect.Map {
+		return false
 	}
-	if optionalTag, ok := field.Tag.Lookup("optional"); ok {
-		switch optionalTag {
-		case "true":
-			isRequired = false
-		case "false":
-			isRequired = true
-		default:
-			panic(fmt.Errorf("invalid value for field %s's optional tag: %s", field.Name, optionalTag))
+	// Nested slices ([][]T) default to optional — flat slices keep the global default
+	return valueType.Kind() != reflect.Slice || valueType.Elem().Kind() != reflect.Slice
+}
+
+// requiredTag returns what the field's required / req / optional / opt tags
+// say, ok being false when it has none. Invalid values panic.
+func requiredTag(field reflect.StructField) (required, ok bool) {
+	for _, tag := range []string{"required", "req", "optional", "opt"} {
+		val, found := field.Tag.Lookup(tag)
+		if !found {
+			continue
 		}
-	}
-	if optionalTag, ok := field.Tag.Lookup("opt"); ok {
-		switch optionalTag {
+		optional := tag == "optional" || tag == "opt"
+		switch val {
 		case "true":
-			isRequired = false
+			required = !optional
 		case "false":
-			isRequired = true
+			required = optional
 		default:
-			panic(fmt.Errorf("invalid value for field %s's optional tag: %s", field.Name, optionalTag))
+			kind := "required"
+			if optional {
+				kind = "optional"
+			}
+			panic(fmt.Errorf("invalid value for field %s's %s tag: %s", field.Name, kind, val))
 		}
+		ok = true
 	}
-
-	return &paramMeta{
-		fieldType:       valueType,
-		isPointer:       isPtr,
-		defaultRequired: isRequired,
-	}
+	return required, ok
 }
 
 var timeType = reflect.TypeOf(time.Time{})
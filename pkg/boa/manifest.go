@@ -0,0 +1,254 @@
+package boa
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ManifestVersion is the version of the Manifest format. It only changes
+// when fields are removed or change meaning; new fields may be added within
+// a version, so consumers should ignore fields they don't know.
+const ManifestVersion = 1
+
+// helpJSONFlag is the name of the flag added by Cmd.HelpJSON.
+const helpJSONFlag = "help-json"
+
+// ManifestDoc is the machine-readable description of a command tree
+// returned by Manifest and printed by --help-json.
+type ManifestDoc struct {
+	ManifestVersion int             `json:"manifestVersion"`
+	Command         ManifestCommand `json:"command"`
+}
+
+// ManifestCommand describes one command and its available subcommands.
+type ManifestCommand struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Use     string   `json:"use"`
+	Short   string   `json:"short,omitempty"`
+	Long    string   `json:"long,omitempty"`
+	Example string   `json:"example,omitempty"`
+	Aliases []string `json:"aliases,omitempty"`
+	// Runnable is false for commands that only group subcommands.
+	Runnable bool `json:"runnable"`
+	// Positionals are the positional arguments in order, ending with the
+	// rest-args param (the args after "--") if there is one.
+	Positionals []ManifestParam `json:"positionals,omitempty"`
+	// Params are the flag, env and config-file params.
+	Params   []ManifestParam   `json:"params,omitempty"`
+	Config   *ManifestConfig   `json:"config,omitempty"`
+	Confirm  *ManifestConfirm  `json:"confirm,omitempty"`
+	Commands []ManifestCommand `json:"commands,omitempty"`
+}
+
+// ManifestParam describes one parameter. Default is omitted when the param
+// has none and for secrets; StrictAlternatives is only set together with
+// Alternatives.
+type ManifestParam struct {
+	Name               string   `json:"name"`
+	Short              string   `json:"short,omitempty"`
+	Env                string   `json:"env,omitempty"`
+	ConfigKey          string   `json:"configKey,omitempty"`
+	Type               string   `json:"type"`
+	Description        string   `json:"description,omitempty"`
+	Default            *string  `json:"default,omitempty"`
+	Required           bool     `json:"required"`
+	Conditional        bool     `json:"conditional,omitempty"`
+	Position           *int     `json:"position,omitempty"`
+	Variadic           bool     `json:"variadic,omitempty"`
+	RestArgs           bool     `json:"restArgs,omitempty"`
+	NoFlag             bool     `json:"noFlag,omitempty"`
+	ConfigFile         bool     `json:"configFile,omitempty"`
+	Secret             bool     `json:"secret,omitempty"`
+	Alternatives       []string `json:"alternatives,omitempty"`
+	StrictAlternatives *bool    `json:"strictAlternatives,omitempty"`
+	Min                string   `json:"min,omitempty"`
+	Max                string   `json:"max,omitempty"`
+	LengthBounds       bool     `json:"lengthBounds,omitempty"`
+	Pattern            string   `json:"pattern,omitempty"`
+	Example            string   `json:"example,omitempty"`
+}
+
+// ManifestConfig describes config-file loading: the flag that names the
+// file and the paths searched when it isn't given.
+type ManifestConfig struct {
+	Flag        string   `json:"flag,omitempty"`
+	SearchPaths []string `json:"searchPaths,omitempty"`
+}
+
+// ManifestConfirm describes how to skip a command's confirmation prompt.
+type ManifestConfirm struct {
+	Flag string `json:"flag"`
+	Env  string `json:"env,omitempty"`
+}
+
+// Manifest returns a versioned, machine-readable description of cmd and
+// its subcommands, for tools, wrapper generators and LLM agents. It is the
+// JSON form of Describe; see Cmd.HelpJSON to expose it as --help-json.
+func Manifest(cmd *cobra.Command) ManifestDoc {
+	return ManifestDoc{
+		ManifestVersion: ManifestVersion,
+		Command:         manifestCommand(Describe(cmd)),
+	}
+}
+
+func manifestCommand(info CommandInfo) ManifestCommand {
+	mc := ManifestCommand{
+		Name:     info.Name,
+		Path:     info.Path,
+		Use:      info.Use,
+		Short:    info.Short,
+		Long:     info.Long,
+		Example:  info.Example,
+		Aliases:  info.Aliases,
+		Runnable: info.Runnable,
+	}
+	var rest []ManifestParam
+	for _, p := range info.Params {
+		mp := manifestParam(p)
+		if p.RestArgs {
+			mp.Variadic = true
+			rest = append(rest, mp)
+			continue
+		}
+		if p.Positional {
+			mp.Variadic = strings.HasPrefix(p.Type, "[]")
+			mc.Positionals = append(mc.Positionals, mp)
+			continue
+		}
+		if p.ConfigFile {
+			mc.Config = &ManifestConfig{}
+			if !p.NoFlag {
+				mc.Config.Flag = p.Name
+			}
+		}
+		mc.Params = append(mc.Params, mp)
+	}
+	// Rest args come after "--", behind every positional
+	mc.Positionals = append(mc.Positionals, rest...)
+	for i := range mc.Positionals {
+		pos := i
+		mc.Positionals[i].Position = &pos
+	}
+	if len(info.ConfigSearchPaths) > 0 {
+		if mc.Config == nil {
+			mc.Config = &ManifestConfig{}
+		}
+		mc.Config.SearchPaths = info.ConfigSearchPaths
+	}
+	if info.Confirm {
+		mc.Confirm = &ManifestConfirm{Flag: info.ConfirmFlag, Env: info.ConfirmEnv}
+	}
+
+	for _, sub := range info.Commands {
+		mc.Commands = append(mc.Commands, manifestCommand(sub))
+	}
+	return mc
+}
+
+func manifestParam(p ParamInfo) ManifestParam {
+	mp := ManifestParam{
+		Name:         p.Name,
+		Env:          p.Env,
+		ConfigKey:    p.ConfigKey,
+		Type:         p.Type,
+		Description:  p.Description,
+		Required:     p.Required,
+		Conditional:  p.Conditional,
+		RestArgs:     p.RestArgs,
+		NoFlag:       p.NoFlag,
+		ConfigFile:   p.ConfigFile,
+		Secret:       p.Secret,
+		Alternatives: p.Alternatives,
+		Min:          p.Min,
+		Max:          p.Max,
+		LengthBounds: p.LengthBounds,
+		Pattern:      p.Pattern,
+		Example:      p.Example,
+	}
+	if !p.Positional && !p.RestArgs && !p.NoFlag {
+		mp.Short = p.Short
+	}
+	if p.HasDefault && !p.Secret {
+		def := p.Default
+		mp.Default = &def
+	}
+	if len(p.Alternatives) > 0 {
+		strict := p.StrictAlts
+		mp.StrictAlternatives = &strict
+	}
+	return mp
+}
+
+// helpJSONValue backs the --help-json flag. Setting it fails flag parsing
+// with pflag.ErrHelp, which makes cobra render help straight away, before
+// arg validation and any hooks; the help func then prints the manifest
+// instead (see writeHelpJSON).
+type helpJSONValue struct {
+	requested bool
+}
+
+func (v *helpJSONValue) String() string { return strconv.FormatBool(v.requested) }
+func (v *helpJSONValue) Type() string   { return "bool" }
+
+func (v *helpJSONValue) Set(s string) error {
+	requested, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	v.requested = requested
+	if requested {
+		return pflag.ErrHelp
+	}
+	return nil
+}
+
+// addHelpJSONFlag adds the hidden persistent --help-json flag for
+// Cmd.HelpJSON. It also wraps the command's help func, so the flag works
+// even when a PostCreate hook replaced it.
+func (b Cmd) addHelpJSONFlag(cmd *cobra.Command) error {
+	if !b.HelpJSON {
+		return nil
+	}
+	if cmd.Flags().Lookup(helpJSONFlag) != nil || cmd.PersistentFlags().Lookup(helpJSONFlag) != nil {
+		return fmt.Errorf("help json: flag --%s is already taken", helpJSONFlag)
+	}
+	f := cmd.PersistentFlags().VarPF(&helpJSONValue{}, helpJSONFlag, "", "print the command tree as JSON")
+	f.NoOptDefVal = "true"
+	f.Hidden = true
+
+	help := cmd.HelpFunc()
+	cmd.SetHelpFunc(func(c *cobra.Command, args []string) {
+		if !writeHelpJSON(c) {
+			help(c, args)
+		}
+	})
+	return nil
+}
+
+// writeHelpJSON prints the manifest of the whole tree c belongs to if
+// --help-json was given, reporting whether it did. Agents calling it on any
+// subcommand get the complete schema.
+func writeHelpJSON(c *cobra.Command) bool {
+	f := c.Flags().Lookup(helpJSONFlag)
+	if f == nil {
+		return false
+	}
+	v, ok := f.Value.(*helpJSONValue)
+	if !ok || !v.requested {
+		return false
+	}
+	v.requested = false
+
+	enc := json.NewEncoder(c.OutOrStdout())
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(Manifest(c.Root())); err != nil {
+		c.PrintErrln("Error:", err)
+	}
+	return true
+}
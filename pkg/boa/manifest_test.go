@@ -0,0 +1,170 @@
+package boa
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type manifestParams struct {
+	ConfigFile string   `configfile:"true" optional:"true"`
+	Region     string   `descr:"region" short:"r" env:"REGION" alts:"eu,us" default:"eu"`
+	Count      int      `descr:"count" min:"1" max:"10"`
+	Token      string   `env:"TOKEN" boa:"noflag" secret:"true" default:"hunter2"`
+	Target     string   `positional:"true" descr:"target"`
+	Files      []string `positional:"true" optional:"true"`
+	Extra      []string `boa:"rest"`
+}
+
+func manifestTree(runs *int) CmdT[NoParams] {
+	return CmdT[NoParams]{
+		Use:      "app",
+		Short:    "the app",
+		HelpJSON: true,
+		SubCmds: SubCmds(CmdT[manifestParams]{
+			Use:     "deploy",
+			Aliases: []string{"d"},
+			Confirm: "Sure?",
+			RunFunc: func(*manifestParams, *cobra.Command, []string) { *runs++ },
+		}),
+	}
+}
+
+func TestManifest(t *testing.T) {
+	var runs int
+	doc := Manifest(manifestTree(&runs).ToCobra())
+	if doc.ManifestVersion != ManifestVersion {
+		t.Errorf("expected version %d, got %d", ManifestVersion, doc.ManifestVersion)
+	}
+	root := doc.Command
+	if root.Path != "app" || root.Runnable || len(root.Params) != 0 || len(root.Commands) != 1 {
+		t.Fatalf("unexpected root: %+v", root)
+	}
+
+	deploy := root.Commands[0]
+	if !deploy.Runnable || !reflect.DeepEqual(deploy.Aliases, []string{"d"}) {
+		t.Errorf("unexpected deploy command: %+v", deploy)
+	}
+	if deploy.Confirm == nil || deploy.Confirm.Flag != "yes" || deploy.Confirm.Env != "YES" {
+		t.Errorf("unexpected confirm: %+v", deploy.Confirm)
+	}
+	if deploy.Config == nil || deploy.Config.Flag != "config-file" {
+		t.Errorf("unexpected config: %+v", deploy.Config)
+	}
+
+	var positionals []string
+	for i, p := range deploy.Positionals {
+		if p.Position == nil || *p.Position != i {
+			t.Errorf("expected %s at position %d, got %v", p.Name, i, p.Position)
+		}
+		positionals = append(positionals, p.Name)
+	}
+	if !reflect.DeepEqual(positionals, []string{"target", "files", "extra"}) {
+		t.Errorf("unexpected positionals %v", positionals)
+	}
+	if target := deploy.Positionals[0]; !target.Required || target.Variadic || target.Short != "" {
+		t.Errorf("unexpected target: %+v", target)
+	}
+	if files := deploy.Positionals[1]; files.Required || !files.Variadic {
+		t.Errorf("unexpected files: %+v", files)
+	}
+	if extra := deploy.Positionals[2]; !extra.RestArgs || !extra.Variadic {
+		t.Errorf("unexpected rest args: %+v", extra)
+	}
+
+	byName := map[string]ManifestParam{}
+	for _, p := range deploy.Params {
+		byName[p.Name] = p
+	}
+	region := byName["region"]
+	if region.Short != "r" || region.Env != "REGION" || region.ConfigKey != "Region" || region.Default == nil || *region.Default != "eu" ||
+		region.Required || region.StrictAlternatives == nil || !*region.StrictAlternatives {
+		t.Errorf("unexpected region: %+v", region)
+	}
+	if count := byName["count"]; !count.Required || count.Min != "1" || count.Max != "10" || count.Default != nil || count.StrictAlternatives != nil {
+		t.Errorf("unexpected count: %+v", count)
+	}
+	if token := byName["token"]; !token.Secret || !token.NoFlag || token.Default != nil || token.Short != "" {
+		t.Errorf("expected secret default to be left out: %+v", token)
+	}
+}
+
+func TestManifest_JSONKeys(t *testing.T) {
+	var runs int
+	out, err := json.Marshal(Manifest(manifestTree(&runs).ToCobra()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{`"manifestVersion":1`, `"configKey":"Region"`, `"strictAlternatives":true`, `"position":0`, `"required":false`} {
+		if !bytes.Contains(out, []byte(key)) {
+			t.Errorf("expected %s in manifest:\n%s", key, out)
+		}
+	}
+}
+
+func TestHelpJSON_Flag(t *testing.T) {
+	var runs int
+	cmd := manifestTree(&runs).ToCobra()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	// Required params and positionals are missing: --help-json must not
+	// validate or run anything
+	cmd.SetArgs([]string{"deploy", "--help-json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if runs != 0 {
+		t.Error("command should not run with --help-json")
+	}
+
+	var doc ManifestDoc
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("expected JSON output, got %v:\n%s", err, out.String())
+	}
+	hasDeploy := slices.ContainsFunc(doc.Command.Commands, func(c ManifestCommand) bool { return c.Path == "app deploy" })
+	if doc.Command.Path != "app" || !hasDeploy || doc.ManifestVersion != ManifestVersion {
+		t.Errorf("expected the manifest of the whole tree from a subcommand, got %+v", doc)
+	}
+}
+
+func TestHelpJSON_HiddenAndOptIn(t *testing.T) {
+	var runs int
+	help := renderHelp(t, manifestTree(&runs).ToCobra())
+	if strings.Contains(help, "help-json") {
+		t.Errorf("expected --help-json to be hidden:\n%s", help)
+	}
+
+	err := (CmdT[NoParams]{
+		Use:     "plain",
+		RunFunc: func(*NoParams, *cobra.Command, []string) {},
+	}).RunArgsE([]string{"--help-json"})
+	if err == nil || !strings.Contains(err.Error(), "unknown flag") {
+		t.Errorf("expected --help-json to be opt-in, got %v", err)
+	}
+}
+
+func TestHelpJSON_CustomHelpFunc(t *testing.T) {
+	cmd := (CmdT[NoParams]{
+		Use:      "app",
+		HelpJSON: true,
+		PostCreateFunc: func(p *NoParams, cmd *cobra.Command) error {
+			cmd.SetHelpFunc(func(c *cobra.Command, args []string) { c.Print("custom help") })
+			return nil
+		},
+		RunFunc: func(*NoParams, *cobra.Command, []string) {},
+	}).ToCobra()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--help-json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "{") {
+		t.Errorf("expected JSON despite the custom help func, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,307 @@
+package boa
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type testDBConfig struct {
+	Host     string `required:"true"`
+	Port     int    `default:"5432" min:"1" max:"65535"`
+	MaxConns int    `default:"10"`
+}
+
+func TestStructMap_PerEntryFlags(t *testing.T) {
+	type Params struct {
+		Databases map[string]testDBConfig `descr:"databases" optional:"true"`
+	}
+
+	var got map[string]testDBConfig
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Databases
+		},
+	}).RunArgsE([]string{
+		"--databases.primary.host=db1",
+		"--databases.primary.port", "6543",
+		"--databases.replica.host=db2",
+		"--databases.replica.max-conns=20",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]testDBConfig{
+		"primary": {Host: "db1", Port: 6543, MaxConns: 10},
+		"replica": {Host: "db2", Port: 5432, MaxConns: 20},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestStructMap_PerEntryFlagRewrite(t *testing.T) {
+	type dsn struct {
+		URL string
+	}
+	type Params struct {
+		Sources map[string]dsn `optional:"true"`
+		Rest    []string       `boa:"rest" optional:"true"`
+	}
+
+	var got map[string]dsn
+	var rest []string
+	root := Cmd{
+		Use: "app",
+		SubCmds: SubCmds(CmdT[Params]{
+			Use: "sync",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+				got, rest = p.Sources, p.Rest
+			},
+		}),
+	}
+	err := root.RunArgsE([]string{
+		"sync",
+		"--sources.a.url", `x?q=1,2&r="s"`,
+		"--", "--sources.b.url=untouched",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := map[string]dsn{"a": {URL: `x?q=1,2&r="s"`}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if want := []string{"--sources.b.url=untouched"}; !reflect.DeepEqual(rest, want) {
+		t.Errorf("expected args after -- to be left alone, got %v", rest)
+	}
+}
+
+func TestStructMap_BaseFlagPairsAndJSON(t *testing.T) {
+	type Params struct {
+		Databases map[string]testDBConfig `optional:"true"`
+	}
+
+	run := func(args ...string) map[string]testDBConfig {
+		t.Helper()
+		var got map[string]testDBConfig
+		err := (CmdT[Params]{
+			Use: "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+				got = p.Databases
+			},
+		}).RunArgsE(args)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return got
+	}
+
+	got := run("--databases", "primary.host=db1,eu.west.host=db2", "--databases.eu.west.port=1")
+	want := map[string]testDBConfig{
+		"primary": {Host: "db1", Port: 5432, MaxConns: 10},
+		"eu.west": {Host: "db2", Port: 1, MaxConns: 10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	got = run("--databases", `{"main":{"Host":"j"}}`)
+	if !reflect.DeepEqual(got, map[string]testDBConfig{"main": {Host: "j", Port: 5432, MaxConns: 10}}) {
+		t.Errorf("unexpected JSON result: %+v", got)
+	}
+}
+
+func TestStructMap_FlagErrors(t *testing.T) {
+	type Params struct {
+		Databases map[string]testDBConfig `optional:"true"`
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"unknown key", []string{"--databases.primary.nope=1"}, `unknown key "nope"`},
+		{"missing key", []string{"--databases", "primary=1"}, "expected <entry>.<field>=value"},
+		{"type", []string{"--databases.primary.port=x"}, "port"},
+		{"required", []string{"--databases.primary.port=1"}, `entry "primary": missing required field 'host'`},
+		{"max", []string{"--databases.a.host=h", "--databases.a.port=70000"}, `entry "a": field 'port': value 70000 exceeds max 65535`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (CmdT[Params]{
+				Use:     "test",
+				RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+			}).RunArgsE(tc.args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !IsUserInputError(err) {
+				t.Errorf("expected user input error, got %T: %v", err, err)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got: %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestStructMap_EnvVars(t *testing.T) {
+	type Params struct {
+		Databases map[string]testDBConfig `env:"DATABASES" optional:"true"`
+	}
+
+	t.Setenv("DATABASES_PRIMARY_HOST", "db1")
+	t.Setenv("DATABASES_PRIMARY_PORT", "6543")
+	t.Setenv("DATABASES_REPLICA_HOST", "db2")
+	t.Setenv("DATABASES_REPLICA_MAX_CONNS", "20")
+
+	var got map[string]testDBConfig
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Databases
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]testDBConfig{
+		"primary": {Host: "db1", Port: 6543, MaxConns: 10},
+		"replica": {Host: "db2", Port: 5432, MaxConns: 20},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestStructMap_EnvValidationAndCLIPriority(t *testing.T) {
+	type Params struct {
+		Databases map[string]testDBConfig `env:"DATABASES" optional:"true"`
+	}
+
+	t.Setenv("DATABASES_REPLICA_PORT", "1")
+
+	err := (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{})
+	if err == nil || !strings.Contains(err.Error(), `entry "replica": missing required field 'host'`) {
+		t.Fatalf("expected entry validation error from env, got %v", err)
+	}
+
+	var got map[string]testDBConfig
+	err = (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Databases
+		},
+	}).RunArgsE([]string{"--databases.cli.host=h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got["cli"].Host != "h" {
+		t.Errorf("expected CLI to win over env, got %+v", got)
+	}
+}
+
+func TestStructMap_ConfigFileEntries(t *testing.T) {
+	type Params struct {
+		ConfigFile string                  `configfile:"true" optional:"true"`
+		Databases  map[string]testDBConfig `optional:"true"`
+	}
+
+	path := writeTestConfigFile(t, `{"Databases":{"primary":{"Host":"db1"},"replica":{"Host":"db2","Port":1}}}`)
+	var got map[string]testDBConfig
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Databases
+		},
+	}).RunArgsE([]string{"--config-file", path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["primary"].Port != 5432 || got["primary"].MaxConns != 10 || got["replica"].Port != 1 {
+		t.Errorf("expected config entries with defaults filled, got %+v", got)
+	}
+
+	bad := writeTestConfigFile(t, `{"Databases":{"primary":{"Port":2}}}`)
+	err = (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--config-file", bad})
+	if err == nil || !strings.Contains(err.Error(), `entry "primary": missing required field 'host'`) {
+		t.Errorf("expected entry validation error for config value, got %v", err)
+	}
+}
+
+func TestStructMap_ExplicitZeroValues(t *testing.T) {
+	type backend struct {
+		Host string `json:"host" required:"true"`
+		TLS  bool   `json:"tls" default:"true"`
+		Port int    `json:"port" default:"80"`
+	}
+	type Params struct {
+		ConfigFile string             `configfile:"true" optional:"true"`
+		Backends   map[string]backend `optional:"true"`
+	}
+
+	run := func(args ...string) (map[string]backend, error) {
+		var got map[string]backend
+		err := (CmdT[Params]{
+			Use: "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+				got = p.Backends
+			},
+		}).RunArgsE(args)
+		return got, err
+	}
+	want := map[string]backend{"a": {Host: "", TLS: false, Port: 0}, "b": {Host: "b", TLS: true, Port: 80}}
+
+	path := writeTestConfigFile(t, `{"Backends":{"a":{"host":"","tls":false,"port":0},"b":{"host":"b"}}}`)
+	if got, err := run("--config-file", path); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("config: expected explicit zeros to be kept, got %+v, %v", got, err)
+	}
+	if got, err := run("--backends", "a.host=,a.tls=false,a.port=0,b.host=b"); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("cli: expected explicit zeros to be kept, got %+v, %v", got, err)
+	}
+}
+
+func TestStructMap_UntaggedFieldsFollowRequiredDefault(t *testing.T) {
+	type cache struct {
+		Addr string
+		TTL  *int
+	}
+	type Params struct {
+		Caches map[string]cache `optional:"true"`
+	}
+
+	err := (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--caches.local.ttl=5"})
+	if err == nil || !strings.Contains(err.Error(), `entry "local": missing required field 'addr'`) {
+		t.Errorf("expected an untagged entry field to be required, got %v", err)
+	}
+}
+
+func TestStructMap_HelpShowsKeys(t *testing.T) {
+	type Params struct {
+		Databases map[string]testDBConfig `descr:"database connections" optional:"true"`
+	}
+
+	usage := captureUsage(t, CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	})
+	if !strings.Contains(usage, "--databases entry.key=value") {
+		t.Errorf("expected entry.key=value placeholder in help:\n%s", usage)
+	}
+	if !strings.Contains(usage, "database connections (keys: host, port, max-conns) (per entry: --databases.<entry>.<key>=value)") {
+		t.Errorf("expected sub-keys and the per-entry form in help:\n%s", usage)
+	}
+}
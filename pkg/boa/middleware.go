@@ -0,0 +1,123 @@
+package boa
+
+import (
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// Phase is the part of a command's lifecycle a Handler runs.
+type Phase int
+
+const (
+	// PhaseInit runs the Init hooks while the command is built.
+	PhaseInit Phase = iota
+	// PhaseValidate resolves the params (defaults, env, config files,
+	// prompts), validates them and runs the PreValidate and PreExecute hooks.
+	PhaseValidate
+	// PhaseRun asks for confirmation, then runs the run function, the
+	// PostExecute hooks and the deferred funcs.
+	PhaseRun
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseInit:
+		return "init"
+	case PhaseValidate:
+		return "validate"
+	case PhaseRun:
+		return "run"
+	}
+	return "unknown"
+}
+
+// Invocation describes the phase a Handler runs.
+type Invocation struct {
+	Phase Phase
+	// Cmd is the command being executed. During PhaseInit it isn't
+	// attached to its parent yet.
+	Cmd *cobra.Command
+	// Args are the positional args; nil during PhaseInit.
+	Args []string
+	// Params is the command's params struct pointer, nil if it has none.
+	// Values are only resolved once PhaseValidate has succeeded.
+	Params any
+	Ctx    *HookContext
+}
+
+// Path is the command path, e.g. "app db migrate".
+func (inv *Invocation) Path() string {
+	return inv.Cmd.CommandPath()
+}
+
+// Handler runs one phase of a command.
+type Handler func(inv *Invocation) error
+
+// Middleware wraps a Handler with cross-cutting behavior: timing, audit
+// logs, tracing spans, turning panics into errors. It calls next to
+// continue, and may skip it or change its error.
+//
+// Middleware set on a command applies to the validate and run phases of
+// that command and of every boa subcommand below it, outermost first: the
+// root's middleware wraps a subcommand's own. The init phase only goes
+// through the command's own middleware, as subcommands are built before
+// their parent.
+//
+// Example:
+//
+//	func timing(next boa.Handler) boa.Handler {
+//	    return func(inv *boa.Invocation) error {
+//	        start := time.Now()
+//	        err := next(inv)
+//	        slog.Info("phase done", "cmd", inv.Path(), "phase", inv.Phase, "took", time.Since(start))
+//	        return err
+//	    }
+//	}
+type Middleware func(next Handler) Handler
+
+// chainMiddleware wraps h in mws, the first being outermost.
+func chainMiddleware(mws []Middleware, h Handler) Handler {
+	for _, mw := range slices.Backward(mws) {
+		h = mw(h)
+	}
+	return h
+}
+
+// middlewareFor returns the middleware that applies to cmd: that of each
+// boa ancestor from the root down, then its own.
+func middlewareFor(cmd *cobra.Command) []Middleware {
+	var mws []Middleware
+	for c := cmd; c != nil; c = c.Parent() {
+		if ctx := lookupDescribe(c); ctx != nil && len(ctx.middleware) > 0 {
+			mws = append(slices.Clone(ctx.middleware), mws...)
+		}
+	}
+	return mws
+}
+
+// wrapMiddleware runs the command's PreRunE as PhaseValidate and its RunE
+// as PhaseRun through the middleware that applies to it.
+func wrapMiddleware(cmd *cobra.Command, ctx *processingContext, params any) {
+	if cmd.RunE == nil {
+		return
+	}
+	wrap := func(phase Phase, fn func(*cobra.Command, []string) error) func(*cobra.Command, []string) error {
+		return func(c *cobra.Command, args []string) error {
+			mws := middlewareFor(c)
+			if len(mws) == 0 {
+				return fn(c, args)
+			}
+			inv := &Invocation{Phase: phase, Cmd: c, Args: args, Params: params, Ctx: newHookContext(ctx)}
+			return chainMiddleware(mws, func(inv *Invocation) error {
+				return fn(inv.Cmd, inv.Args)
+			})(inv)
+		}
+	}
+	preRun := cmd.PreRunE
+	if preRun == nil {
+		preRun = func(*cobra.Command, []string) error { return nil }
+	}
+	cmd.PreRunE = wrap(PhaseValidate, preRun)
+	cmd.RunE = wrap(PhaseRun, cmd.RunE)
+}
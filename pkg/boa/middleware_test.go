@@ -0,0 +1,154 @@
+package boa
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// recording returns a middleware logging each phase it wraps.
+func recording(name string, log *[]string) Middleware {
+	return func(next Handler) Handler {
+		return func(inv *Invocation) error {
+			*log = append(*log, fmt.Sprintf("%s>%s %s", name, inv.Phase, inv.Path()))
+			err := next(inv)
+			*log = append(*log, fmt.Sprintf("%s<%s", name, inv.Phase))
+			return err
+		}
+	}
+}
+
+func TestMiddleware_Inherited(t *testing.T) {
+	type subParams struct {
+		Name string `default:"x"`
+	}
+	var log []string
+	var seenParams any
+	capture := func(next Handler) Handler {
+		return func(inv *Invocation) error {
+			if inv.Phase == PhaseRun {
+				seenParams = inv.Params
+			}
+			return next(inv)
+		}
+	}
+	root := (CmdT[NoParams]{
+		Use:        "app",
+		Middleware: []Middleware{recording("a", &log), recording("b", &log)},
+		SubCmds: SubCmds(CmdT[subParams]{
+			Use:        "sub",
+			Middleware: []Middleware{recording("c", &log), capture},
+			RunFunc: func(p *subParams, _ *cobra.Command, _ []string) {
+				log = append(log, "run "+p.Name)
+			},
+		}),
+	}).ToCobra()
+	root.SetArgs([]string{"sub"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"c>init sub", "c<init", "a>init app", "b>init app", "b<init", "a<init",
+		"a>validate app sub", "b>validate app sub", "c>validate app sub", "c<validate", "b<validate", "a<validate",
+		"a>run app sub", "b>run app sub", "c>run app sub", "run x", "c<run", "b<run", "a<run",
+	}
+	if !reflect.DeepEqual(log, want) {
+		t.Errorf("expected\n%v\ngot\n%v", want, log)
+	}
+	if p, ok := seenParams.(*subParams); !ok || p.Name != "x" {
+		t.Errorf("expected the resolved params, got %#v", seenParams)
+	}
+}
+
+func TestMiddleware_PanicToError(t *testing.T) {
+	recoverPanics := func(next Handler) Handler {
+		return func(inv *Invocation) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = fmt.Errorf("%s: panic: %v", inv.Path(), r)
+				}
+			}()
+			return next(inv)
+		}
+	}
+	deferred := false
+	err := (CmdT[NoParams]{
+		Use:        "app",
+		Middleware: []Middleware{recoverPanics},
+		PreExecuteFuncCtx: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+			ctx.Defer(func() error { deferred = true; return nil })
+			return nil
+		},
+		RunFunc: func(*NoParams, *cobra.Command, []string) { panic("oops") },
+	}).RunArgsE(nil)
+	if err == nil || err.Error() != "app: panic: oops" {
+		t.Errorf("expected the panic as an error, got %v", err)
+	}
+	if !deferred {
+		t.Error("expected deferred funcs to run before the middleware recovers")
+	}
+}
+
+func TestMiddleware_Errors(t *testing.T) {
+	type params struct {
+		Port int `min:"1"`
+	}
+	var phases []string
+	var validateErr error
+	observe := func(next Handler) Handler {
+		return func(inv *Invocation) error {
+			phases = append(phases, inv.Phase.String())
+			err := next(inv)
+			if inv.Phase == PhaseValidate {
+				validateErr = err
+			}
+			return err
+		}
+	}
+	err := (CmdT[params]{
+		Use:        "app",
+		Middleware: []Middleware{observe},
+		RunFunc:    func(*params, *cobra.Command, []string) { t.Error("should not run") },
+	}).RunArgsE([]string{"--port", "0"})
+	if err == nil || validateErr != err {
+		t.Errorf("expected the middleware to see the validation error, got %v / %v", validateErr, err)
+	}
+	if !reflect.DeepEqual(phases, []string{"init", "validate"}) {
+		t.Errorf("unexpected phases %v", phases)
+	}
+
+	// Middleware can stop a phase, including init
+	denied := errors.New("denied")
+	deny := func(next Handler) Handler {
+		return func(inv *Invocation) error { return denied }
+	}
+	_, err = (CmdT[NoParams]{
+		Use:        "app",
+		Middleware: []Middleware{deny},
+		RunFunc:    func(*NoParams, *cobra.Command, []string) {},
+	}).ToCobraE()
+	if !errors.Is(err, denied) {
+		t.Errorf("expected the init phase to fail, got %v", err)
+	}
+}
+
+func TestMiddleware_NotInheritedByPlainCobra(t *testing.T) {
+	var log []string
+	root := (CmdT[NoParams]{
+		Use:        "app",
+		Middleware: []Middleware{recording("a", &log)},
+	}).ToCobra()
+	ran := false
+	root.AddCommand(&cobra.Command{Use: "plain", Run: func(*cobra.Command, []string) { ran = true }})
+	root.SetArgs([]string{"plain"})
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !ran || strings.Join(log, ",") != "a>init app,a<init" {
+		t.Errorf("expected plain cobra subcommands to run without middleware, got %v", log)
+	}
+}
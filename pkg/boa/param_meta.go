@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"math/big"
 	"reflect"
+	"slices"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -50,11 +53,17 @@ type paramMeta struct {
 	// State
 	setByEnv        bool
 	setByConfig     bool
+	setByPrompt     bool
 	setPositionally bool
 	injected        bool
 	valuePtr        any            // cobra flag pointer (e.g., *string from StringP)
 	parent          *cobra.Command
 
+	// envElemFields / configElemFields record which element fields the env
+	// vars or the config file gave a slice or map of structs.
+	envElemFields    elemFields
+	configElemFields elemFields
+
 	// Validation
 	customValidator func(any) error
 	// minVal / maxVal hold the bound as a typed pointer. The concrete type
@@ -63,6 +72,8 @@ type paramMeta struct {
 	//   - unsigned int field → *uint64
 	//   - float field        → *float64
 	//   - string/slice/map   → *int (length bound)
+	//   - big.Int/big.Float  → *big.Float
+	//   - time.Time          → *timeBoundValue
 	// nil means "no bound". The typed storage keeps int64 bounds lossless
 	// past 2^53, which is the whole point of going through an any here
 	// instead of always-float64.
@@ -70,6 +81,33 @@ type paramMeta struct {
 	maxVal  any
 	pattern string // regex pattern for string validation
 
+	// timeLayout / timeLoc change how a time.Time or []time.Time param is
+	// parsed and rendered in help. Set via the `layout` / `tz` tags or
+	// SetTimeLayout / SetTimeZone; empty / nil mean the defaults.
+	timeLayout string
+	timeLoc    *time.Location
+
+	// prompt is the question asked for this param when it is missing and
+	// prompting is on (see CmdT.Prompt); a non-empty prompt also opts the
+	// param into prompting on its own. secret asks for it without echo.
+	// Set via the `prompt` / `secret` tags or SetPrompt / SetSecret.
+	prompt string
+	secret bool
+
+	// example is a sample value shown in the Examples section of help.
+	// Set via the `example` tag or SetExample.
+	example string
+
+	// completion selects shell completion for the value when there are no
+	// alternatives: "file", "file:<exts>", "dir" or "none". Set via the
+	// `complete` tag or SetCompletion.
+	completion string
+
+	// transforms names the registered TransformFuncs run over the value,
+	// in order, before validation. Set via the `transform` tag or
+	// SetTransforms.
+	transforms []string
+
 	// noFlag indicates the field should not be registered as a CLI flag,
 	// but is still populated from env vars and config files. Set via the
 	// `boa:"noflag"` tag (alias `boa:"nocli"`).
@@ -97,6 +135,12 @@ type paramMeta struct {
 	// path to a config file that's unmarshaled into the enclosing struct.
 	// Set either via the tag or programmatically via SetConfigFile(true).
 	isConfigFile bool
+
+	// rest marks this []string parameter as the receiver of every argument
+	// after the `--` terminator. Mirrors `boa:"rest"` / `passthrough:"true"`.
+	// A rest param is never bound as a flag or read from env; it counts as
+	// set on the CLI only when `--` was actually present.
+	rest bool
 }
 
 var _ Param = &paramMeta{}
@@ -288,7 +332,7 @@ func (f *paramMeta) HasValue() bool {
 // --- CLI/Env state ---
 
 func (f *paramMeta) wasSetOnCli() bool {
-	if f.positional {
+	if f.positional || f.rest {
 		return f.wasSetPositionally()
 	}
 	if f.parent == nil {
@@ -354,13 +398,25 @@ func (f *paramMeta) IsIgnored() bool        { return f.ignored }
 func (f *paramMeta) SetIgnored(val bool)    { f.ignored = val }
 func (f *paramMeta) IsConfigFile() bool     { return f.isConfigFile }
 func (f *paramMeta) SetConfigFile(val bool) { f.isConfigFile = val }
+func (f *paramMeta) IsRestArgs() bool       { return f.rest }
+
+// SetRestArgs marks the parameter as the rest-args receiver. Like the tag,
+// turning it on makes the parameter optional; call SetRequired afterwards
+// to require a `--` tail.
+func (f *paramMeta) SetRestArgs(val bool) {
+	f.rest = val
+	if val {
+		f.defaultRequired = false
+	}
+}
 
 // --- min / max / pattern ---
 
 // boundKind classifies a field type for min/max purposes. It collapses the
-// reflect.Kind zoo into the four shapes a bound actually has: signed int,
-// unsigned int, float, or length (string/slice/map). unsupportedBound means
-// min/max are meaningless on this field.
+// reflect.Kind zoo into the shapes a bound actually has: signed int,
+// unsigned int, float, length (string/slice/map), arbitrary precision
+// (big.Int/big.Float), or time (time.Time). unsupportedBound means min/max are meaningless on
+// this field.
 type boundKind int
 
 const (
@@ -369,11 +425,19 @@ const (
 	unsignedIntBound
 	floatBound
 	lengthBound
+	bigBound
+	timeBound
 )
 
 // boundKindOf returns the boundKind for a reflect.Type. Uses Kind() so type
 // aliases (e.g., `type Port int`) work transparently.
 func boundKindOf(t reflect.Type) boundKind {
+	switch t {
+	case bigIntType, bigFloatType:
+		return bigBound
+	case timeType:
+		return timeBound
+	}
 	switch t.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return signedIntBound
@@ -390,6 +454,39 @@ func boundKindOf(t reflect.Type) boundKind {
 // boundKind returns this param's boundKind.
 func (f *paramMeta) boundKind() boundKind { return boundKindOf(f.fieldType) }
 
+// parseBound parses a `min:"..."` / `max:"..."` tag value for this param.
+// Unit types (ByteSize, Quantity, Percent) take bounds in their own syntax,
+// e.g. `min:"1MiB"`, durations take `min:"1s"`, and times take a time in the
+// param's layout or a now-expression; everything else goes through
+// parseBoundTag.
+func (f *paramMeta) parseBound(s string) (any, error) {
+	switch f.fieldType {
+	case durationType:
+		return parseDurationBound(s)
+	case timeType:
+		return parseTimeBound(s, f.timeFormat())
+	}
+	if unitBoundTypes[f.fieldType] {
+		if h, ok := exactHandler(f.fieldType); ok {
+			ptr, err := h.parse(f.name, s)
+			if err != nil {
+				return nil, err
+			}
+			return coerceBound(ptr, f.boundKind())
+		}
+	}
+	return parseBoundTag(f.boundKind(), s)
+}
+
+// formatBound renders a value or bound in min/max error messages. Unit
+// types and durations print in their human form ("1MiB", "85%", "1m30s").
+func (f *paramMeta) formatBound(v any) string {
+	if unitBoundTypes[f.fieldType] || f.fieldType == durationType {
+		return fmt.Sprint(reflect.ValueOf(v).Convert(f.fieldType).Interface())
+	}
+	return fmt.Sprint(v)
+}
+
 // supportsPattern reports whether this param's underlying type is one the
 // pattern validator will act on (strings only).
 func (f *paramMeta) supportsPattern() bool {
@@ -414,6 +511,36 @@ func coerceBound(raw any, bk boundKind) (any, error) {
 		rv = rv.Elem()
 	}
 	switch bk {
+	case timeBound:
+		switch {
+		case rv.Type() == timeType:
+			return &timeBoundValue{at: rv.Interface().(time.Time)}, nil
+		case rv.Type() == reflect.TypeOf(timeBoundValue{}):
+			b := rv.Interface().(timeBoundValue)
+			return &b, nil
+		case rv.Kind() == reflect.String:
+			return parseTimeBound(rv.String(), timeFormat{})
+		}
+	case bigBound:
+		out := new(big.Float).SetPrec(bigBoundPrec)
+		switch {
+		case rv.Type() == bigIntType:
+			i := rv.Interface().(big.Int)
+			return out.SetInt(&i), nil
+		case rv.Type() == bigFloatType:
+			f := rv.Interface().(big.Float)
+			return out.Set(&f), nil
+		}
+		switch rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return out.SetInt64(rv.Int()), nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return out.SetUint64(rv.Uint()), nil
+		case reflect.Float32, reflect.Float64:
+			return out.SetFloat64(rv.Float()), nil
+		case reflect.String:
+			return parseBoundTag(bk, rv.String())
+		}
 	case signedIntBound:
 		switch rv.Kind() {
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -480,8 +607,9 @@ func coerceBound(raw any, bk boundKind) (any, error) {
 }
 
 // GetMin returns a copy of the current lower bound, or nil if none is set.
-// The concrete type is one of *int64 / *uint64 / *float64 / *int depending on
-// the field kind.
+// The concrete type is one of *int64 / *uint64 / *float64 / *int / *big.Float
+// / *time.Time depending on the field kind. A relative time bound
+// (`now-1h`) is resolved against the current time.
 func (f *paramMeta) GetMin() any { return copyBound(f.minVal) }
 
 // GetMax returns a copy of the current upper bound. See GetMin for the
@@ -517,6 +645,17 @@ func copyBound(b any) any {
 		}
 		out := *v
 		return &out
+	case *big.Float:
+		if v == nil {
+			return nil
+		}
+		return new(big.Float).Copy(v)
+	case *timeBoundValue:
+		if v == nil {
+			return nil
+		}
+		out := v.resolve(time.Now())
+		return &out
 	}
 	return nil
 }
@@ -569,6 +708,87 @@ func (f *paramMeta) SetPattern(pat string) {
 	f.pattern = pat
 }
 
+// isTimeParam reports whether this param holds a time.Time or []time.Time.
+func (f *paramMeta) isTimeParam() bool {
+	return f.fieldType == timeType || (f.fieldType.Kind() == reflect.Slice && f.fieldType.Elem() == timeType)
+}
+
+func (f *paramMeta) timeFormat() timeFormat {
+	return timeFormat{layout: f.timeLayout, loc: f.timeLoc}
+}
+
+func (f *paramMeta) GetTimeLayout() string { return f.timeLayout }
+
+// SetTimeLayout sets the time.Parse layout used to parse this param's values
+// and render its default. Pass the empty string to restore the defaults.
+// Panics if the field is not a time.Time or []time.Time.
+func (f *paramMeta) SetTimeLayout(layout string) {
+	if layout != "" && !f.isTimeParam() {
+		panic(fmt.Errorf("boa: SetTimeLayout on %q: type %s is not a time.Time — layout is only meaningful on times", f.name, f.fieldType))
+	}
+	f.timeLayout = layout
+}
+
+func (f *paramMeta) GetTimeZone() *time.Location { return f.timeLoc }
+
+// SetTimeZone sets the location used for values without an explicit offset
+// and for rendering. nil restores the default (UTC). Panics if the field is
+// not a time.Time or []time.Time.
+func (f *paramMeta) SetTimeZone(loc *time.Location) {
+	if loc != nil && !f.isTimeParam() {
+		panic(fmt.Errorf("boa: SetTimeZone on %q: type %s is not a time.Time — a time zone is only meaningful on times", f.name, f.fieldType))
+	}
+	f.timeLoc = loc
+}
+
+func (f *paramMeta) GetTransforms() []string { return slices.Clone(f.transforms) }
+
+// SetTransforms sets the transforms (by registered name) run over the value
+// before validation. Pass nil to clear. Panics on an unknown name or if the
+// field is not a string or []string.
+func (f *paramMeta) SetTransforms(names []string) {
+	if len(names) > 0 && !supportsTransforms(f.fieldType) {
+		panic(fmt.Errorf("boa: SetTransforms on %q: type %s is not a string — transforms only apply to strings and string slices", f.name, f.fieldType))
+	}
+	if err := checkTransforms(names); err != nil {
+		panic(fmt.Errorf("boa: SetTransforms on %q: %w", f.name, err))
+	}
+	f.transforms = slices.Clone(names)
+}
+
+func (f *paramMeta) GetPrompt() string        { return f.prompt }
+func (f *paramMeta) SetPrompt(question string) { f.prompt = question }
+func (f *paramMeta) IsSecret() bool            { return f.secret }
+func (f *paramMeta) SetSecret(secret bool)     { f.secret = secret }
+
+func (f *paramMeta) GetExample() string       { return f.example }
+func (f *paramMeta) SetExample(sample string) { f.example = sample }
+
+func (f *paramMeta) GetCompletion() string { return f.completion }
+
+// SetCompletion sets the shell completion spec. Pass the empty string to
+// clear. Panics on a malformed spec.
+func (f *paramMeta) SetCompletion(spec string) {
+	if spec != "" {
+		if _, _, err := parseCompletion(spec); err != nil {
+			panic(fmt.Errorf("boa: SetCompletion on %q: %w", f.name, err))
+		}
+	}
+	f.completion = spec
+}
+
+// promptMessage is the question asked for this param: the prompt, else the
+// description, else the name.
+func (f *paramMeta) promptMessage() string {
+	switch {
+	case f.prompt != "":
+		return f.prompt
+	case f.descr != "":
+		return f.descr
+	}
+	return f.name
+}
+
 // --- exported description / positional / required convenience ---
 
 func (f *paramMeta) GetDescription() string      { return f.descr }
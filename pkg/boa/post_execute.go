@@ -0,0 +1,174 @@
+package boa
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/spf13/cobra"
+)
+
+// CfgStructPostExecute is an interface that parameter structs can implement
+// to perform logic after the run function, whether it succeeded or not.
+// runErr is the run function's error, or nil.
+type CfgStructPostExecute interface {
+	PostExecute(runErr error) error
+}
+
+// CfgStructPostExecuteCtx is an interface that parameter structs can implement
+// to perform logic after the run function with access to HookContext.
+type CfgStructPostExecuteCtx interface {
+	PostExecuteCtx(ctx *HookContext, runErr error) error
+}
+
+// Defer registers fn to run when the command finishes executing: after the
+// run function and the PostExecute hooks, and also when validation, a hook
+// or the run function fails or panics. Deferred funcs run in LIFO order,
+// like Go's defer, and their errors are joined into the command's error.
+//
+// Use it from any hook to release what that hook acquired:
+//
+//	PreExecuteFuncCtx: func(ctx *boa.HookContext, p *Params, cmd *cobra.Command, args []string) error {
+//	    db, err := sql.Open("postgres", p.DSN)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    ctx.Defer(db.Close)
+//	    p.db = db
+//	    return nil
+//	},
+//
+// Funcs deferred from InitFunc or PostCreateFunc only run if the command
+// is executed.
+func (c *HookContext) Defer(fn func() error) {
+	c.ctx.deferred = append(c.ctx.deferred, fn)
+}
+
+// runDeferred runs and clears the funcs registered with HookContext.Defer,
+// newest first, joining their errors.
+func (ctx *processingContext) runDeferred() error {
+	var errs []error
+	for len(ctx.deferred) > 0 {
+		fn := ctx.deferred[len(ctx.deferred)-1]
+		ctx.deferred = ctx.deferred[:len(ctx.deferred)-1]
+		if err := fn(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// joinErrors is errors.Join, except that a single error is returned as is,
+// so callers can still compare against it directly.
+func joinErrors(errs ...error) error {
+	errs = slices.DeleteFunc(errs, func(err error) bool { return err == nil })
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// wrapPostExecute runs the PostExecute hooks after the run function,
+// including when it returns an error or panics. They run in the reverse
+// order of the PreExecute hooks: PostExecuteFuncCtx, PostExecuteFunc, then
+// the params structs' interfaces, innermost first. Every hook runs even if
+// an earlier one fails.
+func (b Cmd) wrapPostExecute(cmd *cobra.Command, ctx *processingContext) {
+	if cmd.RunE == nil {
+		return
+	}
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) (err error) {
+		defer func() {
+			r := recover()
+			runErr := err
+			var rfe *runFuncError
+			if errors.As(runErr, &rfe) {
+				// The user's own error, not boa's "panic in Run()" marker
+				runErr = rfe.Err
+			}
+			if r != nil {
+				runErr = fmt.Errorf("panic: %v", r)
+			}
+			postErr := b.postExecute(ctx, cmd, args, runErr)
+			if r != nil {
+				panic(r)
+			}
+			err = joinErrors(err, postErr)
+		}()
+		return run(cmd, args)
+	}
+}
+
+func (b Cmd) postExecute(ctx *processingContext, cmd *cobra.Command, args []string, runErr error) error {
+	var errs []error
+	if b.PostExecuteFuncCtx != nil {
+		if err := b.PostExecuteFuncCtx(newHookContext(ctx), b.Params, cmd, args, runErr); err != nil {
+			errs = append(errs, fmt.Errorf("error in PostExecuteFuncCtx: %w", err))
+		}
+	}
+	if b.PostExecuteFunc != nil {
+		if err := b.PostExecuteFunc(b.Params, cmd, args, runErr); err != nil {
+			errs = append(errs, fmt.Errorf("error in PostExecuteFunc: %w", err))
+		}
+	}
+
+	if b.Params == nil {
+		return joinErrors(errs...)
+	}
+	var inner []any
+	_ = traverse(ctx, b.Params, nil, func(innerParams any) error {
+		inner = append(inner, innerParams)
+		return nil
+	})
+	for _, innerParams := range slices.Backward(inner) {
+		if s, ok := innerParams.(CfgStructPostExecuteCtx); ok {
+			if err := s.PostExecuteCtx(newHookContext(ctx), runErr); err != nil {
+				errs = append(errs, fmt.Errorf("error in PostExecuteCtx: %w", err))
+			}
+		}
+		if s, ok := innerParams.(CfgStructPostExecute); ok {
+			if err := s.PostExecute(runErr); err != nil {
+				errs = append(errs, fmt.Errorf("error in PostExecute: %w", err))
+			}
+		}
+	}
+	return joinErrors(errs...)
+}
+
+// wrapDeferred runs the funcs registered with HookContext.Defer once the
+// command is done: after a failed PreRunE (value sourcing, validation and
+// the PreValidate / PreExecute hooks), or after RunE, including when it
+// panics.
+func wrapDeferred(cmd *cobra.Command, ctx *processingContext) {
+	if cmd.RunE == nil {
+		return
+	}
+	cleanup := func(r any, err *error) {
+		deferErr := ctx.runDeferred()
+		if r != nil {
+			panic(r)
+		}
+		*err = joinErrors(*err, deferErr)
+	}
+
+	preRun := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) (err error) {
+		defer func() {
+			if r := recover(); r != nil || err != nil {
+				cleanup(r, &err)
+			}
+		}()
+		if preRun != nil {
+			return preRun(cmd, args)
+		}
+		return nil
+	}
+	run := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) (err error) {
+		defer func() {
+			cleanup(recover(), &err)
+		}()
+		return run(cmd, args)
+	}
+}
@@ -0,0 +1,146 @@
+package boa
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type postExecInner struct {
+	Level string `optional:"true"`
+	log   *[]string
+}
+
+func (p *postExecInner) PostExecute(runErr error) error {
+	*p.log = append(*p.log, "inner")
+	return nil
+}
+
+type postExecParams struct {
+	Name  string `optional:"true"`
+	Inner postExecInner
+	log   []string
+}
+
+func (p *postExecParams) PostExecuteCtx(ctx *HookContext, runErr error) error {
+	p.log = append(p.log, "outer")
+	return nil
+}
+
+func TestPostExecute_Order(t *testing.T) {
+	params := &postExecParams{}
+	params.Inner.log = &params.log
+	var gotRunErr error
+	runErr := errors.New("boom")
+	err := (CmdT[postExecParams]{
+		Use:    "app",
+		Params: params,
+		PreExecuteFuncCtx: func(ctx *HookContext, p *postExecParams, _ *cobra.Command, _ []string) error {
+			ctx.Defer(func() error { p.log = append(p.log, "defer 1"); return nil })
+			ctx.Defer(func() error { p.log = append(p.log, "defer 2"); return nil })
+			return nil
+		},
+		RunFuncE: func(p *postExecParams, _ *cobra.Command, _ []string) error {
+			p.log = append(p.log, "run")
+			return runErr
+		},
+		PostExecuteFuncCtx: func(_ *HookContext, p *postExecParams, _ *cobra.Command, _ []string, err error) error {
+			p.log = append(p.log, "post ctx")
+			return nil
+		},
+		PostExecuteFunc: func(p *postExecParams, _ *cobra.Command, _ []string, err error) error {
+			gotRunErr = err
+			p.log = append(p.log, "post")
+			return nil
+		},
+	}).RunArgsE(nil)
+	if err != runErr {
+		t.Errorf("expected the run error as is, got %v", err)
+	}
+	if gotRunErr != runErr {
+		t.Errorf("expected PostExecuteFunc to receive the run error, got %v", gotRunErr)
+	}
+	want := []string{"run", "post ctx", "post", "inner", "outer", "defer 2", "defer 1"}
+	if !reflect.DeepEqual(params.log, want) {
+		t.Errorf("expected %v, got %v", want, params.log)
+	}
+}
+
+func TestPostExecute_ErrorsJoined(t *testing.T) {
+	closeErr := errors.New("close failed")
+	postErr := errors.New("flush failed")
+	err := (CmdT[NoParams]{
+		Use: "app",
+		PreExecuteFuncCtx: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+			ctx.Defer(func() error { return closeErr })
+			return nil
+		},
+		RunFunc: func(*NoParams, *cobra.Command, []string) {},
+		PostExecuteFunc: func(_ *NoParams, _ *cobra.Command, _ []string, runErr error) error {
+			if runErr != nil {
+				t.Errorf("expected no run error, got %v", runErr)
+			}
+			return postErr
+		},
+	}).RunArgsE(nil)
+	if !errors.Is(err, closeErr) || !errors.Is(err, postErr) || !strings.Contains(err.Error(), "error in PostExecuteFunc") {
+		t.Errorf("expected both errors, got %v", err)
+	}
+}
+
+func TestDefer_ValidationFailure(t *testing.T) {
+	type params struct {
+		Port int `min:"1"`
+	}
+	var deferred, postExecuted bool
+	err := (CmdT[params]{
+		Use: "app",
+		InitFuncCtx: func(ctx *HookContext, _ *params, _ *cobra.Command) error {
+			ctx.Defer(func() error { deferred = true; return nil })
+			return nil
+		},
+		RunFunc: func(*params, *cobra.Command, []string) { t.Error("should not run") },
+		PostExecuteFunc: func(*params, *cobra.Command, []string, error) error {
+			postExecuted = true
+			return nil
+		},
+	}).RunArgsE([]string{"--port", "0"})
+	if err == nil {
+		t.Fatal("expected a validation error")
+	}
+	if !deferred {
+		t.Error("expected deferred funcs to run after a validation failure")
+	}
+	if postExecuted {
+		t.Error("PostExecute hooks only run after the run function")
+	}
+}
+
+func TestDefer_Panic(t *testing.T) {
+	var log []string
+	func() {
+		defer func() {
+			if r := recover(); r != "kaboom" {
+				t.Errorf("expected the panic to propagate, got %v", r)
+			}
+		}()
+		_ = (CmdT[NoParams]{
+			Use: "app",
+			PreExecuteFuncCtx: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+				ctx.Defer(func() error { log = append(log, "defer"); return nil })
+				return nil
+			},
+			RunFunc: func(*NoParams, *cobra.Command, []string) { panic("kaboom") },
+			PostExecuteFunc: func(_ *NoParams, _ *cobra.Command, _ []string, runErr error) error {
+				log = append(log, "post: "+runErr.Error())
+				return nil
+			},
+		}).RunArgsE(nil)
+	}()
+	if want := []string{"post: panic: kaboom", "defer"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("expected %v, got %v", want, log)
+	}
+}
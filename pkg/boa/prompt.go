@@ -0,0 +1,202 @@
+package boa
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Prompter asks the user for the value of a missing required parameter.
+// See CmdT.Prompt. Implementations return the answer as typed; boa parses
+// and validates it, and asks again with PromptRequest.Error set if it is
+// rejected.
+type Prompter interface {
+	Prompt(req PromptRequest) (string, error)
+}
+
+// PromptFunc adapts an ordinary function to the Prompter interface.
+type PromptFunc func(req PromptRequest) (string, error)
+
+// Prompt calls f(req).
+func (f PromptFunc) Prompt(req PromptRequest) (string, error) { return f(req) }
+
+// PromptRequest describes one question to the user.
+type PromptRequest struct {
+	// Param is the parameter being asked for.
+	Param Param
+	// Message is the question: the `prompt` tag, else the description,
+	// else the parameter name.
+	Message string
+	// Choices are the allowed values (the param's alternatives), if any.
+	Choices []string
+	// Secret asks for input without echo (the `secret` tag).
+	Secret bool
+	// Error is why the previous answer was rejected, or nil on the first
+	// attempt.
+	Error error
+}
+
+// maxPromptAttempts bounds how often a param is asked for before the last
+// validation error is returned, so a scripted prompter can't loop forever.
+const maxPromptAttempts = 5
+
+var errEmptyAnswer = errors.New("a value is required")
+
+// errSkipPrompt, returned by a Prompter, leaves the param unset so that
+// validation reports it as missing. Used by reloads, which never prompt.
+var errSkipPrompt = errors.New("prompt skipped")
+
+// NewLinePrompter returns the default Prompter: it writes each question to
+// out and reads one line from in. Secret params are read without echo when
+// in is a terminal.
+func NewLinePrompter(in io.Reader, out io.Writer) Prompter {
+	p := &linePrompter{in: bufio.NewReader(in), out: out}
+	if f, ok := in.(*os.File); ok {
+		p.file = f
+	}
+	return p
+}
+
+type linePrompter struct {
+	in   *bufio.Reader
+	file *os.File // set when in is a file, for echo control
+	out  io.Writer
+}
+
+func (p *linePrompter) Prompt(req PromptRequest) (string, error) {
+	if req.Error != nil {
+		fmt.Fprintf(p.out, "  %s\n", req.Error)
+	}
+	msg := req.Message
+	if len(req.Choices) > 0 {
+		msg += " [" + strings.Join(req.Choices, "/") + "]"
+	}
+	fmt.Fprint(p.out, msg+": ")
+
+	if req.Secret && p.file != nil {
+		if restore, ok := disableEcho(p.file); ok {
+			defer func() {
+				restore()
+				fmt.Fprintln(p.out)
+			}()
+		}
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// prompter returns the Prompter to use for a command: the configured one,
+// or a line prompter on the command's stdin/stderr when stdin is a
+// terminal. nil means don't prompt.
+func (b *Cmd) prompter(cmd *cobra.Command) Prompter {
+	if b.Prompter != nil {
+		return b.Prompter
+	}
+	if in, interactive := stdinOf(cmd); interactive {
+		return NewLinePrompter(in, cmd.ErrOrStderr())
+	}
+	return nil
+}
+
+// stdinOf returns the command's input (os.Stdin unless set with SetIn) and
+// whether it is an interactive terminal. Input that isn't a terminal file,
+// like a buffer passed to SetIn, is never prompted on unless wrapped with
+// InteractiveInput.
+func stdinOf(cmd *cobra.Command) (io.Reader, bool) {
+	switch in := cmd.InOrStdin().(type) {
+	case interactiveInput:
+		return in.Reader, true
+	case *os.File:
+		return in, isTerminal(in)
+	default:
+		return in, false
+	}
+}
+
+type interactiveInput struct{ io.Reader }
+
+// InteractiveInput marks r as an interactive terminal for prompts and
+// confirmations when set as a command's input with cmd.SetIn, e.g. to
+// script the answers in tests. Without it, input that isn't a terminal
+// makes boa fail instead of prompting.
+func InteractiveInput(r io.Reader) io.Reader {
+	return interactiveInput{r}
+}
+
+// promptForMissing asks for every enabled, required param that still has no
+// value. With all unset, only params with a `prompt` tag are asked for.
+func promptForMissing(ctx *processingContext, structPtr any, all bool, p Prompter) error {
+	err := traverse(ctx, structPtr, func(param Param, _ string, _ reflect.StructTag) error {
+		pm, ok := param.(*paramMeta)
+		if !ok || !pm.IsEnabled() || pm.IsIgnored() || pm.IsRestArgs() || !pm.IsRequired() || HasValue(pm) {
+			return nil
+		}
+		if !all && pm.prompt == "" {
+			return nil
+		}
+		return promptParam(ctx, pm, p)
+	}, nil)
+	return newUserInputError(err)
+}
+
+// promptParam asks for pm until an answer passes validation.
+func promptParam(env envSource, pm *paramMeta, p Prompter) error {
+	req := PromptRequest{
+		Param:   pm,
+		Message: pm.promptMessage(),
+		Choices: pm.GetAlternatives(),
+		Secret:  pm.secret,
+	}
+	for attempt := 1; ; attempt++ {
+		answer, err := p.Prompt(req)
+		if errors.Is(err, errSkipPrompt) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("prompt for param '%s': %w", pm.GetName(), err)
+		}
+		err = acceptAnswer(env, pm, answer)
+		if err == nil {
+			return nil
+		}
+		if attempt == maxPromptAttempts {
+			return err
+		}
+		req.Error = err
+	}
+}
+
+// acceptAnswer validates answer on a copy of pm, so transforms and
+// conversions run exactly once on the real param (in validate), then stores
+// it.
+func acceptAnswer(env envSource, pm *paramMeta, answer string) error {
+	if strings.TrimSpace(answer) == "" {
+		return errEmptyAnswer
+	}
+	trialPtr, err := parseParamPtr(pm, answer)
+	if err != nil {
+		return err
+	}
+	trial := *pm
+	trial.setValuePtr(trialPtr)
+	trial.setByPrompt = true
+	if err := validateParam(env, &trial); err != nil {
+		return err
+	}
+	ptr, err := parseParamPtr(pm, answer)
+	if err != nil {
+		return err
+	}
+	pm.setValuePtr(ptr)
+	pm.setByPrompt = true
+	return nil
+}
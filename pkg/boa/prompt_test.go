@@ -0,0 +1,274 @@
+package boa
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// scriptedPrompter answers questions from a fixed list and records them.
+type scriptedPrompter struct {
+	answers []string
+	asked   []PromptRequest
+}
+
+func (s *scriptedPrompter) Prompt(req PromptRequest) (string, error) {
+	s.asked = append(s.asked, req)
+	if len(s.answers) == 0 {
+		return "", errors.New("no more answers")
+	}
+	answer := s.answers[0]
+	s.answers = s.answers[1:]
+	return answer, nil
+}
+
+func TestPrompt_FillsMissingRequired(t *testing.T) {
+	type Params struct {
+		Name  string `descr:"Your name"`
+		Port  int    `prompt:"Which port?"`
+		Token string `secret:"true"`
+		Level string `alts:"debug,info"`
+		Given string
+		Opt   string `optional:"true"`
+	}
+
+	p := &scriptedPrompter{answers: []string{"alice", "8080", "s3cr3t", "info"}}
+	var got Params
+	err := (CmdT[Params]{
+		Use:      "test",
+		Prompt:   true,
+		Prompter: p,
+		RunFunc: func(params *Params, cmd *cobra.Command, args []string) {
+			got = *params
+		},
+	}).RunArgsE([]string{"--given", "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Params{Name: "alice", Port: 8080, Token: "s3cr3t", Level: "info", Given: "x"}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	if len(p.asked) != 4 {
+		t.Fatalf("expected 4 questions, got %d", len(p.asked))
+	}
+	if p.asked[0].Message != "Your name" || p.asked[1].Message != "Which port?" || p.asked[2].Message != "token" {
+		t.Errorf("unexpected messages: %q, %q, %q", p.asked[0].Message, p.asked[1].Message, p.asked[2].Message)
+	}
+	if p.asked[0].Secret || !p.asked[2].Secret {
+		t.Errorf("expected only token to be secret")
+	}
+	if !reflect.DeepEqual(p.asked[3].Choices, []string{"debug", "info"}) {
+		t.Errorf("expected alts as choices, got %v", p.asked[3].Choices)
+	}
+}
+
+func TestPrompt_RetriesInvalidAnswers(t *testing.T) {
+	type Params struct {
+		Level string `alts:"debug,info"`
+		Port  int    `min:"1"`
+	}
+
+	p := &scriptedPrompter{answers: []string{"", "loud", "info", "abc", "0", "80"}}
+	var got Params
+	err := (CmdT[Params]{
+		Use:      "test",
+		Prompt:   true,
+		Prompter: p,
+		RunFunc: func(params *Params, cmd *cobra.Command, args []string) {
+			got = *params
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Level != "info" || got.Port != 80 {
+		t.Errorf("unexpected values: %+v", got)
+	}
+	if len(p.asked) != 6 {
+		t.Fatalf("expected 6 questions, got %d", len(p.asked))
+	}
+	if p.asked[0].Error != nil {
+		t.Errorf("first attempt should have no error, got %v", p.asked[0].Error)
+	}
+	for _, i := range []int{1, 2, 4, 5} {
+		if p.asked[i].Error == nil {
+			t.Errorf("attempt %d should carry the previous rejection", i)
+		}
+	}
+	if !strings.Contains(p.asked[2].Error.Error(), "loud") {
+		t.Errorf("expected alts error to mention the answer, got %v", p.asked[2].Error)
+	}
+}
+
+func TestPrompt_GivesUpAfterMaxAttempts(t *testing.T) {
+	type Params struct {
+		Level string `alts:"debug,info"`
+	}
+
+	answers := make([]string, maxPromptAttempts+1)
+	for i := range answers {
+		answers[i] = "nope"
+	}
+	p := &scriptedPrompter{answers: answers}
+	err := (CmdT[Params]{
+		Use:      "test",
+		Prompt:   true,
+		Prompter: p,
+		RunFunc:  func(params *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !IsUserInputError(err) {
+		t.Errorf("expected user input error, got %v", err)
+	}
+	if len(p.asked) != maxPromptAttempts {
+		t.Errorf("expected %d attempts, got %d", maxPromptAttempts, len(p.asked))
+	}
+}
+
+func TestPrompt_TagOptsInWithoutCmdPrompt(t *testing.T) {
+	type Params struct {
+		Name  string `prompt:"Name?"`
+		Other string `optional:"true"`
+	}
+
+	p := &scriptedPrompter{answers: []string{"bob"}}
+	var got Params
+	err := (CmdT[Params]{
+		Use:      "test",
+		Prompter: p,
+		RunFunc: func(params *Params, cmd *cobra.Command, args []string) {
+			got = *params
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "bob" || len(p.asked) != 1 {
+		t.Errorf("expected one prompt for name, got %+v after %d prompts", got, len(p.asked))
+	}
+}
+
+func TestPrompt_OffByDefault(t *testing.T) {
+	type Params struct {
+		Name string
+	}
+
+	p := &scriptedPrompter{answers: []string{"bob"}}
+	err := (CmdT[Params]{
+		Use:      "test",
+		Prompter: p,
+		RunFunc:  func(params *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{})
+	if err == nil || !strings.Contains(err.Error(), "missing required param") {
+		t.Fatalf("expected missing required param error, got %v", err)
+	}
+	if len(p.asked) != 0 {
+		t.Errorf("expected no prompts, got %d", len(p.asked))
+	}
+}
+
+func TestPrompt_SkipsValuesFromOtherSources(t *testing.T) {
+	type Params struct {
+		FromEnv string `env:"PROMPT_TEST_ENV"`
+		FromDef string `default:"def"`
+		FromCli string
+	}
+	t.Setenv("PROMPT_TEST_ENV", "env")
+
+	p := &scriptedPrompter{}
+	err := (CmdT[Params]{
+		Use:      "test",
+		Prompt:   true,
+		Prompter: p,
+		RunFunc:  func(params *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--from-cli", "cli"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(p.asked) != 0 {
+		t.Errorf("expected no prompts, got %d", len(p.asked))
+	}
+}
+
+func TestPrompt_PrompterErrorIsUserInputError(t *testing.T) {
+	type Params struct {
+		Name string
+	}
+
+	boom := errors.New("stdin closed")
+	err := (CmdT[Params]{
+		Use:    "test",
+		Prompt: true,
+		Prompter: PromptFunc(func(PromptRequest) (string, error) {
+			return "", boom
+		}),
+		RunFunc: func(params *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected prompter error, got %v", err)
+	}
+	if !IsUserInputError(err) {
+		t.Errorf("expected user input error, got %v", err)
+	}
+}
+
+func TestPrompt_Programmatic(t *testing.T) {
+	type Params struct {
+		Password string
+	}
+
+	p := &scriptedPrompter{answers: []string{"hunter2"}}
+	var got string
+	err := (CmdT[Params]{
+		Use:      "test",
+		Prompter: p,
+		InitFuncCtx: func(ctx *HookContext, params *Params, cmd *cobra.Command) error {
+			pw := GetParamT(ctx, &params.Password)
+			pw.SetPrompt("Password")
+			pw.SetSecret(true)
+			return nil
+		},
+		RunFunc: func(params *Params, cmd *cobra.Command, args []string) {
+			got = params.Password
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "hunter2" || len(p.asked) != 1 || p.asked[0].Message != "Password" || !p.asked[0].Secret {
+		t.Errorf("unexpected result %q with requests %+v", got, p.asked)
+	}
+}
+
+func TestLinePrompter(t *testing.T) {
+	in := strings.NewReader("loud\ninfo\r\n")
+	var out bytes.Buffer
+	p := NewLinePrompter(in, &out)
+
+	req := PromptRequest{Message: "Level", Choices: []string{"debug", "info"}}
+	first, err := p.Prompt(req)
+	if err != nil || first != "loud" {
+		t.Fatalf("expected 'loud', got %q (%v)", first, err)
+	}
+	req.Error = errors.New("invalid value 'loud'")
+	second, err := p.Prompt(req)
+	if err != nil || second != "info" {
+		t.Fatalf("expected 'info', got %q (%v)", second, err)
+	}
+	want := "Level [debug/info]: " + "  invalid value 'loud'\nLevel [debug/info]: "
+	if out.String() != want {
+		t.Errorf("expected output %q, got %q", want, out.String())
+	}
+
+	if _, err := p.Prompt(req); err == nil {
+		t.Error("expected error at end of input")
+	}
+}
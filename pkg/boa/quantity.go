@@ -0,0 +1,357 @@
+package boa
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a number of bytes that parses and prints with unit suffixes.
+// Both IEC (KiB, MiB, GiB, ..., or Ki, Mi, Gi) and SI (kB, MB, GB, ..., or
+// k, M, G) suffixes are accepted, case-insensitively; a bare number is
+// bytes. Fractions are fine as long as the result is a whole number of bytes:
+//
+//	--max-body 10MiB  --cache 1.5G  --chunk 4096
+//
+// Defaults in --help, config dumps and String() use the shortest exact
+// form, preferring IEC units ("10MiB", "1.5GB", "512B").
+type ByteSize int64
+
+// Common byte sizes, for use in code: `if p.MaxBody > 10*boa.MiB`.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000
+	MB ByteSize = 1000 * KB
+	GB ByteSize = 1000 * MB
+	TB ByteSize = 1000 * GB
+	PB ByteSize = 1000 * TB
+	EB ByteSize = 1000 * PB
+
+	KiB ByteSize = 1 << 10
+	MiB ByteSize = 1 << 20
+	GiB ByteSize = 1 << 30
+	TiB ByteSize = 1 << 40
+	PiB ByteSize = 1 << 50
+	EiB ByteSize = 1 << 60
+)
+
+type byteUnit struct {
+	suffix string
+	size   ByteSize
+}
+
+// byteUnits is ordered largest first, IEC before SI at each magnitude, which
+// is the preference order for formatting.
+var byteUnits = []byteUnit{
+	{"EiB", EiB}, {"EB", EB},
+	{"PiB", PiB}, {"PB", PB},
+	{"TiB", TiB}, {"TB", TB},
+	{"GiB", GiB}, {"GB", GB},
+	{"MiB", MiB}, {"MB", MB},
+	{"KiB", KiB}, {"kB", KB},
+}
+
+// byteSuffixes maps every accepted (lowercased) suffix to its size.
+var byteSuffixes = map[string]ByteSize{
+	"": Byte, "b": Byte, "byte": Byte, "bytes": Byte,
+	"k": KB, "kb": KB, "m": MB, "mb": MB, "g": GB, "gb": GB,
+	"t": TB, "tb": TB, "p": PB, "pb": PB, "e": EB, "eb": EB,
+	"ki": KiB, "kib": KiB, "mi": MiB, "mib": MiB, "gi": GiB, "gib": GiB,
+	"ti": TiB, "tib": TiB, "pi": PiB, "pib": PiB, "ei": EiB, "eib": EiB,
+}
+
+// ParseByteSize parses a human byte size such as "10MiB", "1.5G" or "4096".
+func ParseByteSize(s string) (ByteSize, error) {
+	num, suffix := splitNumberSuffix(s)
+	unit, ok := byteSuffixes[strings.ToLower(suffix)]
+	if num == "" || !ok {
+		return 0, fmt.Errorf("invalid byte size %q (expected e.g. 512, 10MiB or 1.5GB)", s)
+	}
+	if n, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if n < 0 {
+			return 0, fmt.Errorf("invalid byte size %q: must not be negative", s)
+		}
+		if n > math.MaxInt64/int64(unit) {
+			return 0, fmt.Errorf("invalid byte size %q: too large", s)
+		}
+		return ByteSize(n) * unit, nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q (expected e.g. 512, 10MiB or 1.5GB)", s)
+	}
+	if f < 0 {
+		return 0, fmt.Errorf("invalid byte size %q: must not be negative", s)
+	}
+	total := f * float64(unit)
+	if total >= math.MaxInt64 {
+		return 0, fmt.Errorf("invalid byte size %q: too large", s)
+	}
+	if total != math.Trunc(total) {
+		return 0, fmt.Errorf("invalid byte size %q: not a whole number of bytes", s)
+	}
+	return ByteSize(total), nil
+}
+
+// String formats b with the largest unit that represents it exactly with at
+// most two decimals, e.g. "10MiB", "1.5GB" or "1.5KiB" (for 1536).
+func (b ByteSize) String() string {
+	if b == 0 {
+		return "0B"
+	}
+	n := int64(b)
+	neg := n < 0
+	if neg {
+		n = -n
+	}
+	out := strconv.FormatInt(n, 10) + "B"
+	for _, u := range byteUnits {
+		size := uint64(u.size)
+		if uint64(n) < size {
+			continue
+		}
+		// (n % size) * 100 can overflow for the E units, so use 128-bit math
+		hi, lo := bits.Mul64(uint64(n)%size, 100)
+		frac, rem := bits.Div64(hi, lo, size)
+		if rem != 0 {
+			continue
+		}
+		whole := uint64(n) / size
+		out = strconv.FormatUint(whole, 10)
+		if frac != 0 {
+			out += strings.TrimRight(fmt.Sprintf(".%02d", frac), "0")
+		}
+		out += u.suffix
+		break
+	}
+	if neg {
+		return "-" + out
+	}
+	return out
+}
+
+// Set implements pflag.Value.
+func (b *ByteSize) Set(s string) error {
+	v, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// Type implements pflag.Value.
+func (b *ByteSize) Type() string { return "bytes" }
+
+// MarshalText renders the human form, so config dumps stay readable.
+func (b ByteSize) MarshalText() ([]byte, error) { return []byte(b.String()), nil }
+
+// UnmarshalText parses the human form.
+func (b *ByteSize) UnmarshalText(text []byte) error { return b.Set(string(text)) }
+
+// UnmarshalJSON accepts a human string ("10MiB") or a plain number of bytes.
+func (b *ByteSize) UnmarshalJSON(data []byte) error {
+	return unmarshalQuantityJSON(data, b.Set)
+}
+
+// Quantity is a dimensionless number with optional SI or IEC suffix, like
+// Kubernetes resource quantities: "1.5k", "250m", "2M", "4Gi". SI prefixes
+// are case-sensitive (m is milli, M is mega); "K" is accepted for kilo.
+type Quantity float64
+
+var siPrefixes = []struct {
+	suffix string
+	factor float64
+}{
+	{"E", 1e18}, {"P", 1e15}, {"T", 1e12}, {"G", 1e9}, {"M", 1e6}, {"k", 1e3},
+	{"", 1}, {"m", 1e-3}, {"u", 1e-6}, {"n", 1e-9},
+}
+
+var iecPrefixes = []struct {
+	suffix string
+	factor float64
+}{
+	{"Ei", 1 << 60}, {"Pi", 1 << 50}, {"Ti", 1 << 40}, {"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10},
+}
+
+// ParseQuantity parses a number with an optional SI or IEC suffix.
+func ParseQuantity(s string) (Quantity, error) {
+	num, suffix := splitNumberSuffix(s)
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || num == "" {
+		return 0, fmt.Errorf("invalid quantity %q (expected e.g. 250m, 1.5k or 4Gi)", s)
+	}
+	switch suffix {
+	case "K":
+		return Quantity(f * 1e3), nil
+	case "µ":
+		return Quantity(f * 1e-6), nil
+	}
+	for _, p := range iecPrefixes {
+		if suffix == p.suffix {
+			return Quantity(f * p.factor), nil
+		}
+	}
+	for _, p := range siPrefixes {
+		if suffix == p.suffix {
+			return Quantity(f * p.factor), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid quantity %q: unknown suffix %q", s, suffix)
+}
+
+// String formats q with whichever SI or IEC prefix gives the shortest form.
+func (q Quantity) String() string {
+	v := float64(q)
+	if v == 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	best := formatScaled(v, 1, "")
+	consider := func(factor float64, suffix string) {
+		if math.Abs(v)/factor < 1 {
+			return
+		}
+		if s := formatScaled(v, factor, suffix); len(s) < len(best) {
+			best = s
+		}
+	}
+	for _, p := range iecPrefixes {
+		consider(p.factor, p.suffix)
+	}
+	for _, p := range siPrefixes {
+		consider(p.factor, p.suffix)
+	}
+	return best
+}
+
+// formatScaled prints v/factor with up to 12 significant digits (enough to
+// hide float noise like 100.00000000000001) followed by suffix.
+func formatScaled(v, factor float64, suffix string) string {
+	return strconv.FormatFloat(v/factor, 'g', 12, 64) + suffix
+}
+
+// Set implements pflag.Value.
+func (q *Quantity) Set(s string) error {
+	v, err := ParseQuantity(s)
+	if err != nil {
+		return err
+	}
+	*q = v
+	return nil
+}
+
+// Type implements pflag.Value.
+func (q *Quantity) Type() string { return "quantity" }
+
+// MarshalText renders the human form.
+func (q Quantity) MarshalText() ([]byte, error) { return []byte(q.String()), nil }
+
+// UnmarshalText parses the human form.
+func (q *Quantity) UnmarshalText(text []byte) error { return q.Set(string(text)) }
+
+// UnmarshalJSON accepts a human string ("1.5k") or a plain number.
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	return unmarshalQuantityJSON(data, q.Set)
+}
+
+// Percent is a percentage, stored in percent points: "85%" (or "85") is
+// Percent(85). Use Fraction for the 0..1 form.
+type Percent float64
+
+// ParsePercent parses "85%", "85" or "12.5%".
+func ParsePercent(s string) (Percent, error) {
+	num := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || num == "" {
+		return 0, fmt.Errorf("invalid percentage %q (expected e.g. 85%% or 12.5%%)", s)
+	}
+	return Percent(f), nil
+}
+
+// Fraction returns p as a fraction, e.g. 0.85 for 85%.
+func (p Percent) Fraction() float64 { return float64(p) / 100 }
+
+// String formats p as "85%".
+func (p Percent) String() string {
+	return strconv.FormatFloat(float64(p), 'g', 12, 64) + "%"
+}
+
+// Set implements pflag.Value.
+func (p *Percent) Set(s string) error {
+	v, err := ParsePercent(s)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// Type implements pflag.Value.
+func (p *Percent) Type() string { return "percent" }
+
+// MarshalText renders the human form.
+func (p Percent) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+// UnmarshalText parses the human form.
+func (p *Percent) UnmarshalText(text []byte) error { return p.Set(string(text)) }
+
+// UnmarshalJSON accepts a human string ("85%") or a plain number.
+func (p *Percent) UnmarshalJSON(data []byte) error {
+	return unmarshalQuantityJSON(data, p.Set)
+}
+
+// unmarshalQuantityJSON decodes a JSON string or number and feeds it to set.
+func unmarshalQuantityJSON(data []byte, set func(string) error) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return set(s)
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	return set(n.String())
+}
+
+// splitNumberSuffix splits "1.5GiB" into "1.5" and "GiB". Whitespace between
+// the two is allowed ("10 MiB").
+func splitNumberSuffix(s string) (num, suffix string) {
+	s = strings.TrimSpace(s)
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' || s[i] == '-' || s[i] == '+' ||
+		(i > 0 && (s[i] == 'e' || s[i] == 'E') && i+1 < len(s) && (s[i+1] >= '0' && s[i+1] <= '9' || s[i+1] == '-' || s[i+1] == '+'))) {
+		i++
+	}
+	return s[:i], strings.TrimSpace(s[i:])
+}
+
+var (
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+	quantityType = reflect.TypeOf(Quantity(0))
+	percentType  = reflect.TypeOf(Percent(0))
+)
+
+// unitBoundTypes are types whose min/max tags are written in the same unit
+// syntax as their values (`min:"1MiB"`), parsed with the type's handler.
+var unitBoundTypes = map[reflect.Type]bool{
+	byteSizeType: true,
+	quantityType: true,
+	percentType:  true,
+}
+
+// registerQuantityTypes registers ByteSize, Quantity and Percent. They are
+// pflag.Values, so this is what lazy registration would do anyway; doing it
+// up front keeps them in the catalogue from the start.
+func registerQuantityTypes() {
+	for t := range unitBoundTypes {
+		registerInterfaceType(t)
+	}
+}
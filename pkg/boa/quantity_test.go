@@ -0,0 +1,283 @@
+package boa
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestByteSize_ParseAndString(t *testing.T) {
+	cases := []struct {
+		in   string
+		want ByteSize
+		str  string
+	}{
+		{"0", 0, "0B"},
+		{"512", 512, "512B"},
+		{"10MiB", 10 * MiB, "10MiB"},
+		{"10mib", 10 * MiB, "10MiB"},
+		{"10Mi", 10 * MiB, "10MiB"},
+		{"1.5G", 1500 * MB, "1.5GB"},
+		{"1.5GiB", 1536 * MiB, "1.5GiB"},
+		{"2 kB", 2000, "2kB"},
+		{"1536", 1536, "1.5KiB"},
+		{"1025", 1025, "1025B"},
+		{"4TB", 4 * TB, "4TB"},
+		{"7EiB", 7 * EiB, "7EiB"},
+	}
+	for _, tc := range cases {
+		got, err := ParseByteSize(tc.in)
+		if err != nil {
+			t.Errorf("ParseByteSize(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseByteSize(%q) = %d, want %d", tc.in, got, tc.want)
+		}
+		if got.String() != tc.str {
+			t.Errorf("ByteSize(%d).String() = %q, want %q", got, got.String(), tc.str)
+		}
+		if back, err := ParseByteSize(got.String()); err != nil || back != got {
+			t.Errorf("round-trip of %q failed: %v, %v", got.String(), back, err)
+		}
+	}
+
+	for _, bad := range []string{"", "MiB", "10XB", "-1", "0.1KiB", "9EiB"} {
+		if _, err := ParseByteSize(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestQuantity_ParseAndString(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Quantity
+		str  string
+	}{
+		{"1500", 1500, "1500"},
+		{"1.5k", 1500, "1500"},
+		{"1.5K", 1500, "1500"},
+		{"25000", 25000, "25k"},
+		{"250m", 0.25, "0.25"},
+		{"2M", 2e6, "2M"},
+		{"4Gi", 4 << 30, "4Gi"},
+		{"3u", 3e-6, "3u"},
+		{"42", 42, "42"},
+	}
+	for _, tc := range cases {
+		got, err := ParseQuantity(tc.in)
+		if err != nil {
+			t.Errorf("ParseQuantity(%q): %v", tc.in, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParseQuantity(%q) = %v, want %v", tc.in, float64(got), float64(tc.want))
+		}
+		if got.String() != tc.str {
+			t.Errorf("Quantity(%v).String() = %q, want %q", float64(got), got.String(), tc.str)
+		}
+	}
+	if _, err := ParseQuantity("5X"); err == nil || !strings.Contains(err.Error(), `unknown suffix "X"`) {
+		t.Errorf("expected unknown suffix error, got %v", err)
+	}
+}
+
+func TestPercent_ParseAndString(t *testing.T) {
+	for in, want := range map[string]Percent{"85%": 85, "85": 85, "12.5%": 12.5, " 0.5 % ": 0.5} {
+		got, err := ParsePercent(in)
+		if err != nil || got != want {
+			t.Errorf("ParsePercent(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if p := Percent(85); p.String() != "85%" || p.Fraction() != 0.85 {
+		t.Errorf("unexpected formatting: %s / %v", p, p.Fraction())
+	}
+	if _, err := ParsePercent("lots"); err == nil {
+		t.Error("expected error for non-numeric percentage")
+	}
+}
+
+func TestQuantityTypes_Flags(t *testing.T) {
+	type Params struct {
+		MaxBody   ByteSize   `default:"10MiB" descr:"max request body"`
+		Cache     ByteSize   `optional:"true"`
+		Cpu       Quantity   `default:"500m" descr:"cpu"`
+		Threshold Percent    `default:"85%" descr:"threshold"`
+		Limit     *ByteSize  `optional:"true"`
+		Chunks    []ByteSize `optional:"true"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--cache", "1.5G", "--limit", "2GiB", "--chunks", "4KiB,1MB"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Params{MaxBody: 10 * MiB, Cache: 1500 * MB, Cpu: 0.5, Threshold: 85, Chunks: []ByteSize{4 * KiB, MB}}
+	if got.Limit == nil || *got.Limit != 2*GiB {
+		t.Errorf("unexpected limit: %v", got.Limit)
+	}
+	got.Limit = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	err = (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--cache", "lots"})
+	if err == nil || !IsUserInputError(err) || !strings.Contains(err.Error(), `invalid byte size "lots"`) {
+		t.Errorf("expected byte size user input error, got %v", err)
+	}
+}
+
+func TestQuantityTypes_EnvVars(t *testing.T) {
+	type Params struct {
+		MaxBody   ByteSize `env:"MAX_BODY" optional:"true"`
+		Threshold Percent  `env:"THRESHOLD" optional:"true"`
+	}
+
+	t.Setenv("MAX_BODY", "64KiB")
+	t.Setenv("THRESHOLD", "99.5%")
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.MaxBody != 64*KiB || got.Threshold != 99.5 {
+		t.Errorf("unexpected values: %+v", got)
+	}
+}
+
+func TestQuantityTypes_HelpShowsHumanDefaults(t *testing.T) {
+	type Params struct {
+		MaxBody   ByteSize `default:"10485760" descr:"max request body"`
+		Cpu       Quantity `default:"0.5" descr:"cpu"`
+		Threshold Percent  `default:"85" descr:"threshold"`
+	}
+
+	usage := captureUsage(t, CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	})
+	for _, want := range []string{`(default "10MiB")`, `(default "0.5")`, `(default "85%")`} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("expected %s in help:\n%s", want, usage)
+		}
+	}
+}
+
+func TestQuantityTypes_MinMaxInUnits(t *testing.T) {
+	type Params struct {
+		MaxBody   ByteSize `optional:"true" min:"1MiB" max:"2GiB"`
+		Threshold Percent  `optional:"true" min:"1%" max:"100%"`
+		Cpu       Quantity `optional:"true" max:"4"`
+	}
+
+	run := func(args ...string) error {
+		return (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(args)
+	}
+
+	if err := run("--max-body", "1GiB", "--threshold", "50%", "--cpu", "3500m"); err != nil {
+		t.Errorf("expected values within bounds to pass, got %v", err)
+	}
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--max-body", "512KiB"}, "value 512KiB is below min 1MiB"},
+		{[]string{"--max-body", "3GB"}, "value 3GB exceeds max 2GiB"},
+		{[]string{"--threshold", "120%"}, "value 120% exceeds max 100%"},
+		{[]string{"--cpu", "4500m"}, "value 4.5 exceeds max 4"},
+	}
+	for _, tc := range cases {
+		err := run(tc.args...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tc.args, tc.want, err)
+		}
+	}
+
+	type Bad struct {
+		MaxBody ByteSize `min:"1 furlong"`
+	}
+	_, err := (CmdT[Bad]{Use: "test"}).ToCobraE()
+	if err == nil || !strings.Contains(err.Error(), "invalid min value") {
+		t.Errorf("expected invalid min tag error, got %v", err)
+	}
+}
+
+func TestQuantityTypes_ConfigAndDumpRoundTrip(t *testing.T) {
+	type Params struct {
+		ConfigFile string   `configfile:"true" optional:"true"`
+		MaxBody    ByteSize `optional:"true"`
+		Cpu        Quantity `optional:"true"`
+		Threshold  Percent  `optional:"true"`
+	}
+
+	// Human strings and plain numbers both load.
+	path := writeTestConfigFile(t, `{"MaxBody":"10MiB","Cpu":2,"Threshold":"85%"}`)
+	var dumped []byte
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+			var err error
+			if dumped, err = ctx.DumpBytes(".json", nil); err != nil {
+				t.Fatalf("DumpBytes: %v", err)
+			}
+		},
+	}).RunArgsE([]string{"--config-file", path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.MaxBody != 10*MiB || got.Cpu != 2 || got.Threshold != 85 {
+		t.Errorf("unexpected config values: %+v", got)
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(dumped, &tree); err != nil {
+		t.Fatalf("parse dump: %v", err)
+	}
+	if tree["MaxBody"] != "10MiB" || tree["Cpu"] != "2" || tree["Threshold"] != "85%" {
+		t.Errorf("expected human strings in dump, got %s", dumped)
+	}
+
+	dumpPath := filepath.Join(t.TempDir(), "dump.json")
+	if err := os.WriteFile(dumpPath, dumped, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var reloaded Params
+	err = (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			reloaded = *p
+		},
+	}).RunArgsE([]string{"--config-file", dumpPath})
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	reloaded.ConfigFile, got.ConfigFile = "", ""
+	if reloaded != got {
+		t.Errorf("round-trip mismatch: %+v vs %+v", reloaded, got)
+	}
+}
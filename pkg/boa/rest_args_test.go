@@ -0,0 +1,356 @@
+package boa
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// --- boa:"rest" / passthrough:"true" ---
+
+func TestRestArgs_CapturesArgsAfterDash(t *testing.T) {
+	type Params struct {
+		Verbose bool     `descr:"verbose output" optional:"true"`
+		Command []string `descr:"command to run" boa:"rest"`
+	}
+
+	var got []string
+	err := (CmdT[Params]{
+		Use: "exec",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Command
+		},
+	}).RunArgsE([]string{"--verbose", "--", "kubectl", "get", "pods", "--all-namespaces"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"kubectl", "get", "pods", "--all-namespaces"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestRestArgs_PassthroughTagAlias(t *testing.T) {
+	type Params struct {
+		Tail []string `passthrough:"true"`
+	}
+
+	var got []string
+	err := (CmdT[Params]{
+		Use: "exec",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Tail
+		},
+	}).RunArgsE([]string{"--", "a", "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("expected [a b], got %v", got)
+	}
+}
+
+func TestRestArgs_CoexistsWithPositionals(t *testing.T) {
+	type Params struct {
+		Target  string   `positional:"true"`
+		Extra   []string `positional:"true" optional:"true"`
+		Command []string `boa:"rest"`
+	}
+
+	var target string
+	var extra, command []string
+	err := (CmdT[Params]{
+		Use: "exec",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			target, extra, command = p.Target, p.Extra, p.Command
+		},
+	}).RunArgsE([]string{"pod-1", "x", "y", "--", "sh", "-c", "echo hi"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "pod-1" {
+		t.Errorf("expected target pod-1, got %q", target)
+	}
+	if !reflect.DeepEqual(extra, []string{"x", "y"}) {
+		t.Errorf("expected extra [x y], got %v", extra)
+	}
+	if !reflect.DeepEqual(command, []string{"sh", "-c", "echo hi"}) {
+		t.Errorf("expected command [sh -c echo hi], got %v", command)
+	}
+}
+
+func TestRestArgs_PositionalCountIgnoresTail(t *testing.T) {
+	type Params struct {
+		Target  string   `positional:"true"`
+		Command []string `boa:"rest"`
+	}
+
+	// Only one positional is declared; the tail must not count towards it.
+	err := (CmdT[Params]{
+		Use:     "exec",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"pod-1", "--", "a", "b", "c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A missing required positional is still reported, even with a tail.
+	err = (CmdT[Params]{
+		Use:     "exec",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--", "a"})
+	if err == nil || !IsUserInputError(err) {
+		t.Fatalf("expected user input error for missing positional, got %v", err)
+	}
+}
+
+func TestRestArgs_NoDashLeavesFieldUnset(t *testing.T) {
+	type Params struct {
+		Command []string `boa:"rest"`
+	}
+
+	wasRun := false
+	err := (CmdT[Params]{
+		Use: "exec",
+		RunFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command, args []string) {
+			wasRun = true
+			if ctx.HasValue(&p.Command) {
+				t.Error("expected rest field to be unset without --")
+			}
+			if p.Command != nil {
+				t.Errorf("expected nil rest field, got %v", p.Command)
+			}
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !wasRun {
+		t.Fatal("run func was not called")
+	}
+}
+
+func TestRestArgs_EmptyTailCountsAsSet(t *testing.T) {
+	type Params struct {
+		Command []string `boa:"rest"`
+	}
+
+	err := (CmdT[Params]{
+		Use: "exec",
+		RunFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command, args []string) {
+			if !ctx.HasValue(&p.Command) {
+				t.Error("expected rest field to be set when -- is present")
+			}
+			if len(p.Command) != 0 {
+				t.Errorf("expected empty rest field, got %v", p.Command)
+			}
+		},
+	}).RunArgsE([]string{"--"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRestArgs_RequiredMissing(t *testing.T) {
+	type Params struct {
+		Command []string `boa:"rest" required:"true"`
+	}
+
+	err := (CmdT[Params]{
+		Use:     "exec",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{})
+	if err == nil || !strings.Contains(err.Error(), "missing required param 'command'") {
+		t.Fatalf("expected missing required param error, got %v", err)
+	}
+}
+
+func TestRestArgs_MinMaxLength(t *testing.T) {
+	type Params struct {
+		Command []string `boa:"rest" min:"1" max:"2"`
+	}
+
+	run := func(args ...string) error {
+		return (CmdT[Params]{
+			Use:     "exec",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(args)
+	}
+
+	if err := run("--", "a"); err != nil {
+		t.Errorf("expected 1 arg to pass, got %v", err)
+	}
+	if err := run("--"); err == nil {
+		t.Error("expected min length violation for empty tail")
+	}
+	if err := run(); err == nil || !strings.Contains(err.Error(), "invalid value for param 'command'") {
+		t.Errorf("expected min length violation without --, got %v", err)
+	}
+	if err := run("--", "a", "b", "c"); err == nil {
+		t.Error("expected max length violation for 3 args")
+	}
+}
+
+func TestRestArgs_UseLine(t *testing.T) {
+	type Params struct {
+		Command []string `boa:"rest"`
+		Target  string   `positional:"true"`
+	}
+
+	cmd, err := (CmdT[Params]{
+		Use:     "exec",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).ToCobraE()
+	if err != nil {
+		t.Fatalf("ToCobraE failed: %v", err)
+	}
+	if cmd.Use != "exec <target> [-- command...]" {
+		t.Errorf("unexpected Use line: %q", cmd.Use)
+	}
+}
+
+func TestRestArgs_NotAFlagOrEnv(t *testing.T) {
+	type Params struct {
+		Config  string   `optional:"true"`
+		Command []string `boa:"rest"`
+	}
+
+	t.Setenv("COMMAND", "from-env")
+
+	var command []string
+	var config string
+	err := (CmdT[Params]{
+		Use:         "exec",
+		ParamEnrich: ParamEnricherCombine(ParamEnricherDefault, ParamEnricherEnv),
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			command, config = p.Command, p.Config
+		},
+	}).RunArgsE([]string{"-c", "x.json"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if command != nil {
+		t.Errorf("rest field must not read env, got %v", command)
+	}
+	// The rest field must not steal the -c short flag from --config.
+	if config != "x.json" {
+		t.Errorf("expected config x.json, got %q", config)
+	}
+
+	err = (CmdT[Params]{
+		Use:     "exec",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--command", "x"})
+	if err == nil {
+		t.Error("expected unknown flag error for --command")
+	}
+}
+
+func TestRestArgs_SetupErrors(t *testing.T) {
+	t.Run("wrong type", func(t *testing.T) {
+		type Params struct {
+			Command string `boa:"rest"`
+		}
+		_, err := (CmdT[Params]{Use: "exec"}).ToCobraE()
+		if err == nil || !strings.Contains(err.Error(), "[]string") {
+			t.Errorf("expected []string type error, got %v", err)
+		}
+	})
+	t.Run("two rest fields", func(t *testing.T) {
+		type Params struct {
+			A []string `boa:"rest"`
+			B []string `passthrough:"true"`
+		}
+		_, err := (CmdT[Params]{Use: "exec"}).ToCobraE()
+		if err == nil || !strings.Contains(err.Error(), "only one rest") {
+			t.Errorf("expected duplicate rest error, got %v", err)
+		}
+	})
+	t.Run("rest and positional", func(t *testing.T) {
+		type Params struct {
+			A []string `boa:"rest" positional:"true"`
+		}
+		_, err := (CmdT[Params]{Use: "exec"}).ToCobraE()
+		if err == nil || !strings.Contains(err.Error(), "positional") {
+			t.Errorf("expected rest+positional error, got %v", err)
+		}
+	})
+}
+
+func TestRestArgs_Programmatic(t *testing.T) {
+	type Params struct {
+		Command []string `optional:"true"`
+	}
+
+	var got []string
+	err := (CmdT[Params]{
+		Use: "exec",
+		InitFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command) error {
+			GetParamT(ctx, &p.Command).SetRestArgs(true)
+			return nil
+		},
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Command
+		},
+	}).RunArgsE([]string{"--", "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("expected [x], got %v", got)
+	}
+}
+
+func TestRestArgs_ProgrammaticIsOptional(t *testing.T) {
+	type Params struct {
+		Command []string
+	}
+
+	err := (CmdT[Params]{
+		Use: "exec",
+		InitFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command) error {
+			GetParamT(ctx, &p.Command).SetRestArgs(true)
+			return nil
+		},
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("expected SetRestArgs to make the param optional, got %v", err)
+	}
+}
+
+func TestRestArgs_NilArgsKeepsLegacyValidation(t *testing.T) {
+	newRoot := func() (*cobra.Command, *paramMeta) {
+		root := &cobra.Command{Use: "app", RunE: func(*cobra.Command, []string) error { return nil }}
+		root.AddCommand(&cobra.Command{Use: "deploy", Run: func(*cobra.Command, []string) {}})
+		root.SetOut(io.Discard)
+		root.SetErr(io.Discard)
+		rest := &paramMeta{name: "command", fieldType: reflect.TypeOf([]string{})}
+		connectRestArgs(rest, root)
+		return root, rest
+	}
+
+	// A root command with subcommands still rejects unknown ones
+	root, _ := newRoot()
+	root.SetArgs([]string{"deplyo"})
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), `unknown command "deplyo" for "app"`) {
+		t.Fatalf("expected an unknown command error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Did you mean this?\n\tdeploy") {
+		t.Errorf("expected a suggestion, got %v", err)
+	}
+
+	// Args after -- are never taken for subcommands
+	root, rest := newRoot()
+	root.SetArgs([]string{"--", "deplyo"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := rest.valuePtr.(*[]string); got == nil || !reflect.DeepEqual(*got, []string{"deplyo"}) {
+		t.Errorf("expected the tail [deplyo], got %v", rest.valuePtr)
+	}
+}
@@ -0,0 +1,153 @@
+package boa
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ErrInterrupted is the cause (see context.Cause) of a command context
+// cancelled because the process received one of Cmd.Signals.
+var ErrInterrupted = errors.New("interrupted")
+
+// ErrTimeout is the cause (see context.Cause) of a command context
+// cancelled because Cmd.Timeout expired.
+var ErrTimeout = errors.New("timed out")
+
+// interruptExitCode is the exit code when a second signal arrives while the
+// command is still shutting down, following the shell's 128+SIGINT.
+const interruptExitCode = 130
+
+// defaultSignals cancel the command context when Cmd.Signals is nil and
+// the run function takes a HookContext.
+var defaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// runSignals returns the signals that cancel the command context: Signals
+// if set, otherwise defaultSignals for run functions taking a HookContext,
+// which can watch the context. Other commands keep Go's default handling,
+// so Ctrl-C stops them straight away.
+func (b Cmd) runSignals() []os.Signal {
+	if b.Signals != nil {
+		return b.Signals
+	}
+	if b.RunFuncCtx != nil || b.RunFuncCtxE != nil {
+		return defaultSignals
+	}
+	return nil
+}
+
+// wrapRunContext gives the command a context for the duration of its run,
+// from PreRunE (so PreValidate and PreExecute hooks see it too) until RunE
+// returns. The context derives from cmd.Context(), so ExecuteContext's
+// parent is honored, and is cancelled on the first of signals or after
+// timeout. It is exposed through HookContext.Context and cmd.Context().
+func wrapRunContext(cmd *cobra.Command, ctx *processingContext, signals []os.Signal, timeout time.Duration) {
+	if cmd.RunE == nil {
+		return
+	}
+	var stop func()
+	start := func(c *cobra.Command) {
+		if stop == nil {
+			stop = startRunContext(c, ctx, signals, timeout)
+		}
+	}
+	end := func() {
+		if stop != nil {
+			stop()
+			stop = nil
+		}
+	}
+
+	preRun := cmd.PreRunE
+	cmd.PreRunE = func(c *cobra.Command, args []string) error {
+		start(c)
+		if preRun != nil {
+			if err := preRun(c, args); err != nil {
+				end()
+				return err
+			}
+		}
+		return nil
+	}
+	run := cmd.RunE
+	cmd.RunE = func(c *cobra.Command, args []string) error {
+		start(c)
+		defer end()
+		return stopError(ctx.Context, run(c, args))
+	}
+}
+
+// stopError labels err, returned by a run function after runCtx was
+// cancelled by a signal or the timeout, with that cause, so a run function
+// returning ctx.Err() reports ErrInterrupted or ErrTimeout rather than a
+// bare context.Canceled or context.DeadlineExceeded.
+func stopError(runCtx context.Context, err error) error {
+	if err == nil || runCtx.Err() == nil {
+		return err
+	}
+	cause := context.Cause(runCtx)
+	if cause != ErrInterrupted && cause != ErrTimeout || errors.Is(err, cause) {
+		return err
+	}
+	if !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", cause, err)
+}
+
+// startRunContext installs the run context on ctx and c, returning the
+// func that tears it down and restores the previous contexts.
+func startRunContext(c *cobra.Command, ctx *processingContext, signals []os.Signal, timeout time.Duration) func() {
+	parent := c.Context()
+	if parent == nil {
+		parent = context.Background()
+	}
+	prevCtx := ctx.Context
+
+	runCtx, cancel := context.WithCancelCause(parent)
+	var cancelTimeout context.CancelFunc = func() {}
+	if timeout > 0 {
+		runCtx, cancelTimeout = context.WithTimeoutCause(runCtx, timeout, ErrTimeout)
+	}
+
+	done := make(chan struct{})
+	var sigs chan os.Signal
+	if len(signals) > 0 {
+		sigs = make(chan os.Signal, 1)
+		signal.Notify(sigs, signals...)
+		go func() {
+			select {
+			case <-sigs:
+				cancel(ErrInterrupted)
+			case <-done:
+				return
+			}
+			// The command didn't stop in time for the user: a second
+			// signal exits without waiting for it.
+			select {
+			case <-sigs:
+				osExit(interruptExitCode)
+			case <-done:
+			}
+		}()
+	}
+
+	ctx.Context = runCtx
+	c.SetContext(runCtx)
+	return func() {
+		if sigs != nil {
+			signal.Stop(sigs)
+		}
+		close(done)
+		cancelTimeout()
+		cancel(nil)
+		ctx.Context = prevCtx
+		c.SetContext(parent)
+	}
+}
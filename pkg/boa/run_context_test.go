@@ -0,0 +1,215 @@
+//go:build unix
+
+package boa
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRunContext_Signal(t *testing.T) {
+	exits := make(chan int, 1)
+	oldOsExit := osExit
+	osExit = func(code int) { exits <- code }
+	defer func() { osExit = oldOsExit }()
+
+	var preExecuteCtx context.Context
+	err := (CmdT[NoParams]{
+		Use:     "app",
+		Signals: []os.Signal{syscall.SIGUSR1},
+		PreExecuteFuncCtx: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+			preExecuteCtx = ctx.Context()
+			return nil
+		},
+		RunFuncCtxE: func(ctx *HookContext, _ *NoParams, cmd *cobra.Command, _ []string) error {
+			if ctx.Context() != preExecuteCtx || cmd.Context() != preExecuteCtx {
+				t.Error("expected hooks, the run func and cobra to share the run context")
+			}
+			if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case <-ctx.Context().Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected the signal to cancel the context")
+			}
+			if cause := context.Cause(ctx.Context()); !errors.Is(cause, ErrInterrupted) {
+				t.Errorf("expected ErrInterrupted as the cause, got %v", cause)
+			}
+
+			// A second signal doesn't wait for the command
+			if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case code := <-exits:
+				if code != 130 {
+					t.Errorf("expected exit code 130, got %d", code)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected a second signal to exit")
+			}
+			return context.Cause(ctx.Context())
+		},
+	}).RunArgsE(nil)
+	if !errors.Is(err, ErrInterrupted) {
+		t.Errorf("expected ErrInterrupted, got %v", err)
+	}
+	if preExecuteCtx.Err() == nil {
+		t.Error("expected the run context to be cancelled after the run")
+	}
+}
+
+func TestRunContext_Timeout(t *testing.T) {
+	err := (CmdT[NoParams]{
+		Use:     "app",
+		Timeout: 10 * time.Millisecond,
+		RunFuncCtxE: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+			<-ctx.Context().Done()
+			return ctx.Context().Err()
+		},
+	}).RunArgsE(nil)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, ErrTimeout) {
+		t.Errorf("expected a deadline error caused by the timeout, got %v", err)
+	}
+
+	// Run exits instead of panicking on the timeout
+	exits := make(chan int, 1)
+	oldOsExit := osExit
+	osExit = func(code int) { exits <- code }
+	defer func() { osExit = oldOsExit }()
+	(CmdT[NoParams]{
+		Use:     "app",
+		Timeout: 10 * time.Millisecond,
+		RunFuncCtxE: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+			<-ctx.Context().Done()
+			return ctx.Context().Err()
+		},
+	}).RunArgs(nil)
+	if code := <-exits; code != ExitTimeout {
+		t.Errorf("expected exit code %d after the timeout, got %d", ExitTimeout, code)
+	}
+}
+
+func TestRunContext_SignalReturningCtxErr(t *testing.T) {
+	exits := make(chan int, 1)
+	oldOsExit := osExit
+	osExit = func(code int) { exits <- code }
+	defer func() { osExit = oldOsExit }()
+
+	cmd := CmdT[NoParams]{
+		Use:     "app",
+		Signals: []os.Signal{syscall.SIGUSR1},
+		RunFuncCtxE: func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+			if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case <-ctx.Context().Done():
+			case <-time.After(5 * time.Second):
+				t.Fatal("expected the signal to cancel the context")
+			}
+			return ctx.Context().Err()
+		},
+	}
+	err := cmd.RunArgsE(nil)
+	if !errors.Is(err, ErrInterrupted) || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancellation caused by the signal, got %v", err)
+	}
+
+	// Run exits with 130 instead of panicking
+	cmd.RunArgs(nil)
+	select {
+	case code := <-exits:
+		if code != 130 {
+			t.Errorf("expected exit code 130, got %d", code)
+		}
+	default:
+		t.Error("expected Run to exit")
+	}
+}
+
+func TestRunContext_Parent(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+	defer cancel()
+
+	run := func(ctx *HookContext, _ *NoParams, _ *cobra.Command, _ []string) error {
+		if ctx.Context().Value(key{}) != "v" {
+			t.Error("expected the run context to derive from the parent")
+		}
+		cancel()
+		<-ctx.Context().Done()
+		return ctx.Context().Err()
+	}
+	err := (CmdT[NoParams]{Use: "app", RunFuncCtxE: run}).RunArgsContextE(parent, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the parent's cancellation, got %v", err)
+	}
+
+	// Subcommands get the parent given to ExecuteContext too
+	parent, cancel = context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+	defer cancel()
+	root := (CmdT[NoParams]{
+		Use:     "app",
+		SubCmds: SubCmds(CmdT[NoParams]{Use: "sub", RunFuncCtxE: run}),
+	}).ToCobra()
+	root.SetArgs([]string{"sub"})
+	if err := ExecuteContext(parent, root); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the parent's cancellation in a subcommand, got %v", err)
+	}
+}
+
+func TestRunContext_OutsideRun(t *testing.T) {
+	var ctx *HookContext
+	err := (CmdT[NoParams]{
+		Use:     "app",
+		Signals: []os.Signal{},
+		InitFuncCtx: func(c *HookContext, _ *NoParams, _ *cobra.Command) error {
+			ctx = c
+			if c.Context() == nil {
+				t.Error("expected a context before the run")
+			}
+			return nil
+		},
+		RunFunc: func(*NoParams, *cobra.Command, []string) {},
+	}).RunArgsE(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.Context().Err() != nil {
+		t.Error("expected the context to be restored after the run")
+	}
+}
+
+func TestRunContext_PlainRunFuncKeepsDefaultSignals(t *testing.T) {
+	if os.Getenv("BOA_TEST_SIGNAL_CHILD") == "1" {
+		(CmdT[NoParams]{
+			Use: "app",
+			RunFunc: func(*NoParams, *cobra.Command, []string) {
+				_ = syscall.Kill(os.Getpid(), syscall.SIGINT)
+				time.Sleep(5 * time.Second)
+			},
+		}).RunArgs(nil)
+		os.Exit(0)
+	}
+
+	// Go's default handling kills the process, so check it in a child
+	child := exec.Command(os.Args[0], "-test.run=^TestRunContext_PlainRunFuncKeepsDefaultSignals$")
+	child.Env = append(os.Environ(), "BOA_TEST_SIGNAL_CHILD=1")
+	err := child.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("expected the child to be killed by SIGINT, got %v", err)
+	}
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); !ok || !ws.Signaled() || ws.Signal() != syscall.SIGINT {
+		t.Errorf("expected the child to be killed by SIGINT, got %v", err)
+	}
+}
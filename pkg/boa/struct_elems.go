@@ -0,0 +1,957 @@
+package boa
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// structElemField describes one field of a struct used as the element type of
+// a composite parameter (e.g. `Servers []ServerConfig`). Elements don't get
+// mirrors of their own — the schema carries everything needed to parse a
+// field from a `key=value` pair or an env var and to validate it afterwards.
+type structElemField struct {
+	index    int          // field index within the element struct
+	key      string       // kebab-case key used on the CLI, e.g. "max-conns"
+	goName   string       // Go field name, accepted case-insensitively as a key
+	typ      reflect.Type // the field's declared type
+	valType  reflect.Type // normalized value type (elem type for pointer fields)
+	isPtr    bool
+	handler  *typeHandler // nil for fields only settable via JSON (nested structs etc.)
+	defVal   any          // parsed default (*valType) or nil
+	required *bool        // from the required / optional tags, nil when untagged
+	// meta carries min/max/pattern/alts in the same representation a
+	// top-level param uses, so validateMinMaxPattern can be reused as-is.
+	meta *paramMeta
+}
+
+// structElemSchema is the parsed, cached view of an element struct type.
+type structElemSchema struct {
+	typ    reflect.Type
+	fields []*structElemField
+}
+
+var (
+	structElemSchemas   = map[reflect.Type]*structElemSchema{}
+	structElemSchemasMu sync.Mutex
+)
+
+// isStructElemType reports whether t is a plain struct that should be treated
+// as a structured element (rather than a registered scalar like time.Time).
+func isStructElemType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !isSupportedType(t)
+}
+
+// structElemSchemaOf builds (or returns the cached) schema for a struct
+// element type. Invalid tags on element fields are programming errors and
+// panic, mirroring how newParam treats malformed required/optional tags.
+func structElemSchemaOf(t reflect.Type) *structElemSchema {
+	structElemSchemasMu.Lock()
+	defer structElemSchemasMu.Unlock()
+	if s, ok := structElemSchemas[t]; ok {
+		return s
+	}
+
+	s := &structElemSchema{typ: t}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if isBoaIgnored(sf) {
+			continue
+		}
+		f := &structElemField{
+			index:   i,
+			key:     camelToKebabCase(sf.Name),
+			goName:  sf.Name,
+			typ:     sf.Type,
+			valType: sf.Type,
+		}
+		if name, ok := sf.Tag.Lookup("name"); ok {
+			f.key = name
+		} else if name, ok := sf.Tag.Lookup("long"); ok {
+			f.key = name
+		}
+		if sf.Type.Kind() == reflect.Pointer && sf.Type != urlPtrType {
+			f.isPtr = true
+			f.valType = sf.Type.Elem()
+		}
+		f.valType = normalizeType(f.valType)
+		if h, _ := lookupHandler(f.valType); h != nil {
+			f.handler = h
+		} else if f.valType.Kind() == reflect.Slice && !isStructElemType(f.valType.Elem()) {
+			f.handler = lookupSliceHandler(f.valType.Elem())
+		}
+		if required, ok := requiredTag(sf); ok {
+			f.required = &required
+		}
+
+		f.meta = &paramMeta{name: f.key, fieldType: f.valType}
+		if f.handler != nil {
+			if def, ok := sf.Tag.Lookup("default"); ok {
+				ptr, err := parsePtr(f.key, f.valType, f.valType.Kind(), def)
+				if err != nil {
+					panic(fmt.Errorf("invalid default value for field %s.%s: %s", t.Name(), sf.Name, err.Error()))
+				}
+				f.defVal = ptr
+			}
+		}
+		if minStr, ok := sf.Tag.Lookup("min"); ok {
+			ptr, err := f.meta.parseBound(minStr)
+			if err != nil {
+				panic(fmt.Errorf("invalid min value for field %s.%s: %s", t.Name(), sf.Name, err.Error()))
+			}
+			f.meta.minVal = ptr
+		}
+		if maxStr, ok := sf.Tag.Lookup("max"); ok {
+			ptr, err := f.meta.parseBound(maxStr)
+			if err != nil {
+				panic(fmt.Errorf("invalid max value for field %s.%s: %s", t.Name(), sf.Name, err.Error()))
+			}
+			f.meta.maxVal = ptr
+		}
+		f.meta.pattern = sf.Tag.Get("pattern")
+		for _, tag := range []string{"alts", "alternatives"} {
+			if alts, ok := sf.Tag.Lookup(tag); ok {
+				f.meta.alternatives = splitAltsTag(alts)
+			}
+		}
+		if f.meta.alternatives == nil {
+			f.meta.alternatives = enumNames(f.valType)
+		}
+		for _, tag := range []string{"strict-alts", "strict"} {
+			if strict, ok := sf.Tag.Lookup(tag); ok {
+				f.meta.SetStrictAlts(strict == "true")
+			}
+		}
+		s.fields = append(s.fields, f)
+	}
+	structElemSchemas[t] = s
+	return s
+}
+
+// splitAltsTag parses an `alts:"a,b,c"` tag value (optionally bracketed)
+// into its trimmed, non-empty elements.
+func splitAltsTag(alts string) []string {
+	strVal := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(alts), "["), "]")
+	nonEmpty := make([]string, 0)
+	for _, element := range strings.Split(strVal, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			nonEmpty = append(nonEmpty, element)
+		}
+	}
+	return nonEmpty
+}
+
+// keys returns the CLI keys of every field settable via key=value, in
+// declaration order. Used for help text and error messages.
+func (s *structElemSchema) keys() []string {
+	keys := make([]string, 0, len(s.fields))
+	for _, f := range s.fields {
+		if f.handler != nil {
+			keys = append(keys, f.key)
+		}
+	}
+	return keys
+}
+
+// field resolves a user-supplied key. The kebab-case key (or `name` tag)
+// matches exactly; the Go field name and the key itself also match
+// case-insensitively so `Host=...` and `HOST=...` work too.
+func (s *structElemSchema) field(key string) *structElemField {
+	for _, f := range s.fields {
+		if f.key == key {
+			return f
+		}
+	}
+	for _, f := range s.fields {
+		if strings.EqualFold(f.key, key) || strings.EqualFold(f.goName, key) {
+			return f
+		}
+	}
+	return nil
+}
+
+// newElem allocates an element with every field default applied. Returns
+// an addressable value of the element type.
+func (s *structElemSchema) newElem() reflect.Value {
+	elem := reflect.New(s.typ).Elem()
+	for _, f := range s.fields {
+		if f.defVal != nil {
+			f.assign(elem, f.defVal)
+		}
+	}
+	return elem
+}
+
+// fillDefaults applies field defaults to an element that didn't go through
+// newElem (e.g. one unmarshaled from a config file). Fields in given keep
+// their value even when it is zero; with no key information (given is nil)
+// every zero-valued field is taken as missing.
+func (s *structElemSchema) fillDefaults(elem reflect.Value, given map[int]bool) {
+	for _, f := range s.fields {
+		if f.defVal == nil {
+			continue
+		}
+		if given != nil && given[f.index] || given == nil && !elem.Field(f.index).IsZero() {
+			continue
+		}
+		f.assign(elem, f.defVal)
+	}
+}
+
+// elemFields records which fields each element of a slice or map of structs
+// was given by its source, keyed by element label (the index for slices, the
+// entry name for maps). It is how an explicit `port=0` or `"TLS": false` is
+// told apart from a field that was left out. A nil elemFields means the
+// source is unknown, e.g. a tag default.
+type elemFields map[string]map[int]bool
+
+// add marks field index of the element label as given.
+func (g elemFields) add(label string, index int) {
+	if g[label] == nil {
+		g[label] = map[int]bool{}
+	}
+	g[label][index] = true
+}
+
+// jsonGiven returns the fields a JSON object sets, matching keys the way
+// encoding/json does: by json tag name or Go field name, case-insensitively.
+func (s *structElemSchema) jsonGiven(raw []byte) map[int]bool {
+	var keys map[string]any
+	_ = json.Unmarshal(raw, &keys)
+	return s.keysGiven(keys, "json")
+}
+
+// keysGiven returns the fields present in keys, a decoded object keyed by
+// the tag's names (or Go field names when tag is empty).
+func (s *structElemSchema) keysGiven(keys map[string]any, tag string) map[int]bool {
+	given := map[int]bool{}
+	for _, f := range s.fields {
+		if _, ok := configKeyLookup(keys, fieldRawKey(s.typ.Field(f.index), tag)); ok {
+			given[f.index] = true
+		}
+	}
+	return given
+}
+
+// configElemFields reads which element fields a config file gave from its
+// canonicalized key tree, for slice and map of struct fields of type t.
+// Returns nil for other types or when the tree doesn't have the expected
+// shape.
+func configElemFields(t reflect.Type, tree any) elemFields {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t.Kind() == reflect.Slice && isStructElemType(t.Elem()):
+		elems, ok := tree.([]any)
+		if !ok {
+			return nil
+		}
+		s := structElemSchemaOf(t.Elem())
+		given := elemFields{}
+		for i, e := range elems {
+			given[strconv.Itoa(i)] = s.keysGiven(asKeyMap(e), "")
+		}
+		return given
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && isStructElemType(t.Elem()):
+		entries := asKeyMap(tree)
+		if entries == nil {
+			return nil
+		}
+		s := structElemSchemaOf(t.Elem())
+		given := elemFields{}
+		for k, e := range entries {
+			given[k] = s.keysGiven(asKeyMap(e), "")
+		}
+		return given
+	}
+	return nil
+}
+
+// givenElemFields returns which element fields the source that set param
+// gave, or nil when that isn't known.
+func givenElemFields(param Param) elemFields {
+	pm, ok := param.(*paramMeta)
+	if !ok {
+		return nil
+	}
+	switch {
+	case pm.wasSetOnCli():
+		if pm.parent == nil {
+			return nil
+		}
+		if flag := pm.parent.Flags().Lookup(pm.name); flag != nil {
+			if v, ok := flag.Value.(interface{ givenFields() elemFields }); ok {
+				return v.givenFields()
+			}
+		}
+		return nil
+	case pm.wasSetByEnv():
+		return pm.envElemFields
+	case pm.setByConfig:
+		return pm.configElemFields
+	}
+	return nil
+}
+
+// isRequired reports whether the field must be set. Untagged fields get the
+// default a top-level field of the same type would (see defaultRequired),
+// except that fields only settable via JSON default to optional. It is
+// resolved on use rather than cached in the schema, so WithDefaultOptional
+// applies however early the schema was built.
+func (f *structElemField) isRequired() bool {
+	if f.required != nil {
+		return *f.required
+	}
+	return f.handler != nil && defaultRequired(f.isPtr, f.valType)
+}
+
+// assign stores a parsed *valType pointer into the element field, converting
+// across type aliases and allocating for pointer fields.
+func (f *structElemField) assign(elem reflect.Value, ptr any) {
+	v := reflect.ValueOf(ptr).Elem()
+	target := elem.Field(f.index)
+	if f.isPtr {
+		p := reflect.New(f.typ.Elem())
+		p.Elem().Set(v.Convert(f.typ.Elem()))
+		target.Set(p)
+		return
+	}
+	target.Set(v.Convert(f.typ))
+}
+
+// set parses raw through the field's type handler and stores it.
+func (f *structElemField) set(elem reflect.Value, paramName, raw string) error {
+	if f.handler == nil {
+		return fmt.Errorf("field '%s' cannot be set with key=value syntax, use JSON instead", f.key)
+	}
+	ptr, err := f.handler.parse(paramName+"."+f.key, raw)
+	if err != nil {
+		return err
+	}
+	f.assign(elem, ptr)
+	return nil
+}
+
+// parseKV parses one `key=value,key=value` element. Pairs are split with csv
+// rules, so values containing commas can be quoted.
+func (s *structElemSchema) parseKV(paramName, strVal string) (reflect.Value, map[int]bool, error) {
+	elem := s.newElem()
+	given := map[int]bool{}
+	pairs, err := readAsCSV(strVal)
+	if err != nil {
+		return reflect.Value{}, nil, fmt.Errorf("invalid value for param %s: %w", paramName, err)
+	}
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return reflect.Value{}, nil, fmt.Errorf("invalid value for param %s: %q (expected key=value, keys: %s)", paramName, pair, strings.Join(s.keys(), ", "))
+		}
+		key := strings.TrimSpace(kv[0])
+		f := s.field(key)
+		if f == nil {
+			return reflect.Value{}, nil, fmt.Errorf("invalid value for param %s: unknown key %q (keys: %s)", paramName, key, strings.Join(s.keys(), ", "))
+		}
+		if err := f.set(elem, paramName, kv[1]); err != nil {
+			return reflect.Value{}, nil, err
+		}
+		given[f.index] = true
+	}
+	return elem, given, nil
+}
+
+// parseElems parses one CLI/env value into elements, along with the fields
+// each element was given. A JSON array or object is decoded over
+// default-filled elements; anything else is a single key=value element.
+func (s *structElemSchema) parseElems(paramName, strVal string) ([]reflect.Value, []map[int]bool, error) {
+	trimmed := strings.TrimSpace(strVal)
+	switch {
+	case strings.HasPrefix(trimmed, "["):
+		var raws []json.RawMessage
+		if err := json.Unmarshal([]byte(trimmed), &raws); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON for param %s: %w", paramName, err)
+		}
+		elems := make([]reflect.Value, 0, len(raws))
+		given := make([]map[int]bool, 0, len(raws))
+		for _, raw := range raws {
+			elem := s.newElem()
+			if err := json.Unmarshal(raw, elem.Addr().Interface()); err != nil {
+				return nil, nil, fmt.Errorf("invalid JSON for param %s: %w", paramName, err)
+			}
+			elems = append(elems, elem)
+			given = append(given, s.jsonGiven(raw))
+		}
+		return elems, given, nil
+	case strings.HasPrefix(trimmed, "{"):
+		elem := s.newElem()
+		if err := json.Unmarshal([]byte(trimmed), elem.Addr().Interface()); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON for param %s: %w", paramName, err)
+		}
+		return []reflect.Value{elem}, []map[int]bool{s.jsonGiven([]byte(trimmed))}, nil
+	case trimmed == "":
+		return nil, nil, nil
+	default:
+		elem, given, err := s.parseKV(paramName, strVal)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []reflect.Value{elem}, []map[int]bool{given}, nil
+	}
+}
+
+// readEnvElem fills an element from `<prefix><FIELD>` env vars. Returns
+// the fields that had a var, empty when none was present.
+func (s *structElemSchema) readEnvElem(src envSource, paramName, prefix string, elem reflect.Value) (map[int]bool, error) {
+	given := map[int]bool{}
+	for _, f := range s.fields {
+		if f.handler == nil {
+			continue
+		}
+		envName := prefix + kebabCaseToUpperSnakeCase(f.key)
+		raw, ok := src.lookupEnv(envName)
+		if !ok {
+			continue
+		}
+		given[f.index] = true
+		if err := f.set(elem, paramName, raw); err != nil {
+			return given, fmt.Errorf("env %s: %w", envName, err)
+		}
+	}
+	return given, nil
+}
+
+// envIndex is one element index found among `<PREFIX>_<n>_<FIELD>` env
+// vars, with the first var that names it.
+type envIndex struct {
+	n       int
+	envName string
+}
+
+// envIndexes returns every element index that has a `<prefix><n>_<FIELD>`
+// var in environ for a known field, sorted. Collecting them all up front
+// (rather than reading until the first missing index) lets gaps and
+// orphaned indexes be reported instead of silently dropped.
+func (s *structElemSchema) envIndexes(prefix string, environ []string) []envIndex {
+	suffixes := map[string]bool{}
+	for _, f := range s.fields {
+		if f.handler != nil {
+			suffixes[kebabCaseToUpperSnakeCase(f.key)] = true
+		}
+	}
+
+	// Sort for deterministic error reporting.
+	environ = slices.Clone(environ)
+	slices.Sort(environ)
+
+	seen := map[int]bool{}
+	var indexes []envIndex
+	for _, kv := range environ {
+		envName, _, _ := strings.Cut(kv, "=")
+		rest, ok := strings.CutPrefix(envName, prefix)
+		if !ok {
+			continue
+		}
+		num, field, ok := strings.Cut(rest, "_")
+		if !ok || !suffixes[field] {
+			continue
+		}
+		n, err := strconv.Atoi(num)
+		if err != nil || n < 0 || strconv.Itoa(n) != num || seen[n] {
+			continue
+		}
+		seen[n] = true
+		indexes = append(indexes, envIndex{n: n, envName: envName})
+	}
+	slices.SortFunc(indexes, func(a, b envIndex) int { return a.n - b.n })
+	return indexes
+}
+
+// validateElem runs required / alts / min / max / pattern checks for every
+// field of one element. label identifies the element in error messages
+// (e.g. "element 2" or `entry "primary"`). A required field counts as
+// missing when it is zero, has no default and is not in given, so an
+// explicit zero passes.
+func (s *structElemSchema) validateElem(label string, elem reflect.Value, given map[int]bool) error {
+	for _, f := range s.fields {
+		fv := elem.Field(f.index)
+		if f.isPtr {
+			if fv.IsNil() {
+				if f.isRequired() {
+					return fmt.Errorf("%s: missing required field '%s'", label, f.key)
+				}
+				continue
+			}
+			fv = fv.Elem()
+		} else if f.isRequired() && f.defVal == nil && fv.IsZero() && !given[f.index] {
+			return fmt.Errorf("%s: missing required field '%s'", label, f.key)
+		}
+		ptr := reflect.New(f.valType)
+		ptr.Elem().Set(fv.Convert(f.valType))
+		if alts := f.meta.alternatives; alts != nil && f.meta.GetStrictAlts() && !fv.IsZero() {
+			if strVal := altString(ptr); !slices.Contains(alts, strVal) {
+				return fmt.Errorf("%s: field '%s': '%s' is not in the list of allowed values: %v", label, f.key, strVal, alts)
+			}
+		}
+		if err := validateMinMaxPattern(f.meta, ptr.Interface()); err != nil {
+			return fmt.Errorf("%s: field '%s': %s", label, f.key, err.Error())
+		}
+	}
+	return nil
+}
+
+// structSliceValue is the pflag.Value behind a slice-of-struct flag. Each
+// occurrence of the flag appends one element (`--server host=a,port=80`) or,
+// for JSON input, every element of the array. The first occurrence replaces
+// any default, matching pflag's own slice flags.
+type structSliceValue struct {
+	value     reflect.Value // *[]Elem
+	schema    *structElemSchema
+	paramName string
+	changed   bool
+	given     elemFields
+}
+
+func (v *structSliceValue) Set(val string) error {
+	elems, given, err := v.schema.parseElems(v.paramName, val)
+	if err != nil {
+		return err
+	}
+	slice := v.value.Elem()
+	if !v.changed {
+		slice.Set(reflect.MakeSlice(slice.Type(), 0, len(elems)))
+		v.given = elemFields{}
+	}
+	for i, elem := range elems {
+		v.given[strconv.Itoa(slice.Len())] = given[i]
+		slice.Set(reflect.Append(slice, elem))
+	}
+	v.changed = true
+	return nil
+}
+
+func (v *structSliceValue) givenFields() elemFields { return v.given }
+
+func (v *structSliceValue) Type() string { return "key=value" }
+
+func (v *structSliceValue) String() string {
+	if v.value.IsNil() || v.value.Elem().Len() == 0 {
+		return ""
+	}
+	b, err := json.Marshal(v.value.Elem().Interface())
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+var (
+	structSliceHandlers   = map[reflect.Type]*typeHandler{}
+	structSliceHandlersMu sync.Mutex
+)
+
+// structSliceHandler returns the (cached) handler for []Elem where Elem is a
+// plain struct. Elements come from repeated `--flag key=value,...` flags,
+// JSON, or indexed env vars (`SERVERS_0_HOST`, `SERVERS_1_PORT`, ...).
+func structSliceHandler(elemType reflect.Type) *typeHandler {
+	structSliceHandlersMu.Lock()
+	defer structSliceHandlersMu.Unlock()
+	if h, ok := structSliceHandlers[elemType]; ok {
+		return h
+	}
+
+	schema := structElemSchemaOf(elemType)
+	sliceType := reflect.SliceOf(elemType)
+	parseGiven := func(name, strVal string) (any, elemFields, error) {
+		v := &structSliceValue{value: reflect.New(sliceType), schema: schema, paramName: name}
+		if err := v.Set(strVal); err != nil {
+			return nil, nil, err
+		}
+		return v.value.Interface(), v.given, nil
+	}
+	parse := func(name, strVal string) (any, error) {
+		ptr, _, err := parseGiven(name, strVal)
+		return ptr, err
+	}
+
+	h := &typeHandler{
+		baseType: sliceType,
+		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
+			storage := reflect.New(sliceType)
+			if defaultVal != nil {
+				if dv := reflect.ValueOf(defaultVal); dv.Kind() == reflect.Pointer && !dv.IsNil() && dv.Elem().Type() == sliceType {
+					storage.Elem().Set(dv.Elem())
+				}
+			}
+			if keys := schema.keys(); len(keys) > 0 {
+				descr = fmt.Sprintf("%s (keys: %s)", descr, strings.Join(keys, ", "))
+			}
+			cmd.Flags().VarP(&structSliceValue{value: storage, schema: schema, paramName: name}, name, short, descr)
+			return storage.Interface()
+		},
+		parse: parse,
+		readEnv: func(name, env string, src envSource) (any, elemFields, error) {
+			if raw, ok := src.lookupEnv(env); ok && raw != "" {
+				return parseGiven(name, raw)
+			}
+			environ, err := src.environ()
+			if err != nil {
+				return nil, nil, fmt.Errorf("env %s_*: %w", env, err)
+			}
+			ptr := reflect.New(sliceType)
+			given := elemFields{}
+			for i, idx := range schema.envIndexes(env+"_", environ) {
+				if idx.n != i {
+					return nil, nil, fmt.Errorf("env %s: element %d has no env vars, indexes must count up from 0", idx.envName, i)
+				}
+				elem := schema.newElem()
+				fields, err := schema.readEnvElem(src, name, env+"_"+strconv.Itoa(i)+"_", elem)
+				if err != nil {
+					return nil, nil, err
+				}
+				given[strconv.Itoa(i)] = fields
+				ptr.Elem().Set(reflect.Append(ptr.Elem(), elem))
+			}
+			if ptr.Elem().Len() == 0 {
+				return nil, nil, nil
+			}
+			return ptr.Interface(), given, nil
+		},
+		validateElems: func(name string, val any, fillDefaults bool, given elemFields) error {
+			slice := reflect.ValueOf(val)
+			if slice.Kind() != reflect.Pointer || slice.IsNil() {
+				return nil
+			}
+			slice = slice.Elem()
+			for i := 0; i < slice.Len(); i++ {
+				elem := slice.Index(i)
+				fields := given[strconv.Itoa(i)]
+				if fillDefaults {
+					schema.fillDefaults(elem, fields)
+				}
+				if err := schema.validateElem("element "+strconv.Itoa(i), elem, fields); err != nil {
+					return fmt.Errorf("invalid value for param '%s': %s", name, err.Error())
+				}
+			}
+			return nil
+		},
+	}
+	structSliceHandlers[elemType] = h
+	return h
+}
+
+// structMapValue is the pflag.Value behind a map-of-struct flag. The flag
+// takes `entry.field=value` pairs (`--databases primary.host=x`) or a JSON
+// object of entries. The per-entry form `--databases.primary.host=x` is
+// rewritten into a pair for this flag before cobra parses the args (see
+// expandEntryFlags).
+type structMapValue struct {
+	value     reflect.Value // *map[string]Elem
+	schema    *structElemSchema
+	paramName string
+	changed   bool
+	given     elemFields
+}
+
+func (v *structMapValue) givenFields() elemFields { return v.given }
+
+func (v *structMapValue) Set(val string) error {
+	m := v.value.Elem()
+	if !v.changed || m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+		v.given = elemFields{}
+	}
+	v.changed = true
+
+	trimmed := strings.TrimSpace(val)
+	if strings.HasPrefix(trimmed, "{") {
+		parsed, given, err := v.schema.parseEntries(v.paramName, m.Type(), trimmed)
+		if err != nil {
+			return err
+		}
+		iter := parsed.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), iter.Value())
+			v.given[iter.Key().String()] = given[iter.Key().String()]
+		}
+		return nil
+	}
+
+	pairs, err := readAsCSV(val)
+	if err != nil {
+		return fmt.Errorf("invalid value for param %s: %w", v.paramName, err)
+	}
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid value for param %s: %q (expected <entry>.<field>=value)", v.paramName, pair)
+		}
+		if err := v.setPath(m, strings.TrimSpace(kv[0]), kv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setPath sets `<entry>.<field>` to raw, creating the entry (with element
+// defaults) on first use. Field keys never contain dots, so the entry name
+// is everything before the last one.
+func (v *structMapValue) setPath(m reflect.Value, path, raw string) error {
+	idx := strings.LastIndex(path, ".")
+	if idx <= 0 || idx == len(path)-1 {
+		return fmt.Errorf("invalid value for param %s: %q (expected <entry>.<field>=value)", v.paramName, path)
+	}
+	entry, key := path[:idx], path[idx+1:]
+	f := v.schema.field(key)
+	if f == nil {
+		return fmt.Errorf("invalid value for param %s: unknown key %q (keys: %s)", v.paramName, key, strings.Join(v.schema.keys(), ", "))
+	}
+	elem := v.schema.newElem()
+	if existing := m.MapIndex(reflect.ValueOf(entry)); existing.IsValid() {
+		elem.Set(existing)
+	}
+	if err := f.set(elem, v.paramName+"."+entry, raw); err != nil {
+		return err
+	}
+	m.SetMapIndex(reflect.ValueOf(entry), elem)
+	v.given.add(entry, f.index)
+	return nil
+}
+
+func (v *structMapValue) Type() string { return "entry.key=value" }
+
+func (v *structMapValue) String() string {
+	if v.value.IsNil() || v.value.Elem().Len() == 0 {
+		return ""
+	}
+	b, err := json.Marshal(v.value.Elem().Interface())
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// expandEntryFlags rewrites per-entry map flags in args into a pair for
+// their base flag, so `--databases.primary.host=x` (or `... x`) becomes
+// `--databases=primary.host=x`. Entry names aren't known until the args
+// are, so there is no flag pflag could match the dotted form against.
+// Only flags of the command args resolve to are rewritten, and nothing
+// after `--`. ok reports whether anything changed.
+func expandEntryFlags(root *cobra.Command, args []string) (expanded []string, ok bool) {
+	target, _, err := root.Find(args)
+	if err != nil || target == nil {
+		target = root
+	}
+	expanded = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			expanded = append(expanded, args[i:]...)
+			break
+		}
+		name, val, hasVal := strings.Cut(strings.TrimPrefix(arg, "--"), "=")
+		base, path, dotted := strings.Cut(name, ".")
+		if !strings.HasPrefix(arg, "--") || !dotted || path == "" || !isStructMapFlag(target, base) {
+			expanded = append(expanded, arg)
+			continue
+		}
+		if !hasVal {
+			if i+1 == len(args) {
+				expanded = append(expanded, arg)
+				continue
+			}
+			i++
+			val = args[i]
+		}
+		expanded = append(expanded, "--"+base+"="+csvQuote(path+"="+val))
+		ok = true
+	}
+	return expanded, ok
+}
+
+// isStructMapFlag reports whether cmd has a map-of-struct flag called name.
+func isStructMapFlag(cmd *cobra.Command, name string) bool {
+	f := cmd.Flags().Lookup(name)
+	if f == nil {
+		return false
+	}
+	_, ok := f.Value.(*structMapValue)
+	return ok
+}
+
+// csvQuote quotes field for readAsCSV when it would otherwise be split or
+// misread, e.g. a value containing commas.
+func csvQuote(field string) string {
+	if !strings.ContainsAny(field, ",\"\r\n") {
+		return field
+	}
+	return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+}
+
+// parseEntries decodes a JSON object of entries, each over a default-filled
+// element, along with the fields each entry was given.
+func (s *structElemSchema) parseEntries(paramName string, mapType reflect.Type, strVal string) (reflect.Value, elemFields, error) {
+	var raws map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(strVal), &raws); err != nil {
+		return reflect.Value{}, nil, fmt.Errorf("invalid JSON for param %s: %w", paramName, err)
+	}
+	m := reflect.MakeMapWithSize(mapType, len(raws))
+	given := elemFields{}
+	for k, raw := range raws {
+		elem := s.newElem()
+		if err := json.Unmarshal(raw, elem.Addr().Interface()); err != nil {
+			return reflect.Value{}, nil, fmt.Errorf("invalid JSON for param %s entry %q: %w", paramName, k, err)
+		}
+		m.SetMapIndex(reflect.ValueOf(k), elem)
+		given[k] = s.jsonGiven(raw)
+	}
+	return m, given, nil
+}
+
+var (
+	structMapHandlers   = map[reflect.Type]*typeHandler{}
+	structMapHandlersMu sync.Mutex
+)
+
+// structMapHandler returns the (cached) handler for map[string]Elem where
+// Elem is a plain struct. Entries come from `--flag entry.field=value`,
+// `--flag.entry.field=value`, JSON, or `<ENV>_<ENTRY>_<FIELD>` env vars.
+func structMapHandler(mapType reflect.Type) *typeHandler {
+	structMapHandlersMu.Lock()
+	defer structMapHandlersMu.Unlock()
+	if h, ok := structMapHandlers[mapType]; ok {
+		return h
+	}
+
+	schema := structElemSchemaOf(mapType.Elem())
+	parseGiven := func(name, strVal string) (any, elemFields, error) {
+		v := &structMapValue{value: reflect.New(mapType), schema: schema, paramName: name}
+		if err := v.Set(strVal); err != nil {
+			return nil, nil, err
+		}
+		return v.value.Interface(), v.given, nil
+	}
+	parse := func(name, strVal string) (any, error) {
+		ptr, _, err := parseGiven(name, strVal)
+		return ptr, err
+	}
+
+	h := &typeHandler{
+		baseType: mapType,
+		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
+			storage := reflect.New(mapType)
+			if defaultVal != nil {
+				if dv := reflect.ValueOf(defaultVal); dv.Kind() == reflect.Pointer && !dv.IsNil() && dv.Elem().Type() == mapType {
+					storage.Elem().Set(dv.Elem())
+				}
+			}
+			if keys := schema.keys(); len(keys) > 0 {
+				descr = fmt.Sprintf("%s (keys: %s) (per entry: --%s.<entry>.<key>=value)", descr, strings.Join(keys, ", "), name)
+			}
+			cmd.Flags().VarP(&structMapValue{value: storage, schema: schema, paramName: name}, name, short, descr)
+			return storage.Interface()
+		},
+		parse: parse,
+		readEnv: func(name, env string, src envSource) (any, elemFields, error) {
+			if raw, ok := src.lookupEnv(env); ok && raw != "" {
+				return parseGiven(name, raw)
+			}
+			environ, err := src.environ()
+			if err != nil {
+				return nil, nil, fmt.Errorf("env %s_*: %w", env, err)
+			}
+			return schema.readEnvEntries(name, env+"_", mapType, environ)
+		},
+		validateElems: func(name string, val any, fillDefaults bool, given elemFields) error {
+			m := reflect.ValueOf(val)
+			if m.Kind() != reflect.Pointer || m.IsNil() || m.Elem().IsNil() {
+				return nil
+			}
+			m = m.Elem()
+			keys := make([]string, 0, m.Len())
+			for _, k := range m.MapKeys() {
+				keys = append(keys, k.String())
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				kv := reflect.ValueOf(k).Convert(m.Type().Key())
+				elem := reflect.New(m.Type().Elem()).Elem()
+				elem.Set(m.MapIndex(kv))
+				if fillDefaults {
+					schema.fillDefaults(elem, given[k])
+					m.SetMapIndex(kv, elem)
+				}
+				if err := schema.validateElem(fmt.Sprintf("entry %q", k), elem, given[k]); err != nil {
+					return fmt.Errorf("invalid value for param '%s': %s", name, err.Error())
+				}
+			}
+			return nil
+		},
+	}
+	structMapHandlers[mapType] = h
+	return h
+}
+
+// readEnvEntries builds map entries from `<prefix><ENTRY>_<FIELD>` vars in
+// environ, along with the fields each entry was given. Field suffixes are
+// matched longest first so MAX_CONNS isn't mistaken for CONNS; entry names
+// are lowercased.
+func (s *structElemSchema) readEnvEntries(paramName, prefix string, mapType reflect.Type, environ []string) (any, elemFields, error) {
+	type suffix struct {
+		env   string
+		field *structElemField
+	}
+	var suffixes []suffix
+	for _, f := range s.fields {
+		if f.handler != nil {
+			suffixes = append(suffixes, suffix{"_" + kebabCaseToUpperSnakeCase(f.key), f})
+		}
+	}
+	slices.SortFunc(suffixes, func(a, b suffix) int { return len(b.env) - len(a.env) })
+
+	// Sort for deterministic error reporting.
+	environ = slices.Clone(environ)
+	slices.Sort(environ)
+
+	m := reflect.MakeMap(mapType)
+	given := elemFields{}
+	for _, kv := range environ {
+		envName, raw, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+		rest, ok := strings.CutPrefix(envName, prefix)
+		if !ok {
+			continue
+		}
+		for _, sfx := range suffixes {
+			entryPart, ok := strings.CutSuffix(rest, sfx.env)
+			if !ok || entryPart == "" {
+				continue
+			}
+			entry := strings.ToLower(entryPart)
+			ev := reflect.ValueOf(entry)
+			elem := s.newElem()
+			if existing := m.MapIndex(ev); existing.IsValid() {
+				elem.Set(existing)
+			}
+			if err := sfx.field.set(elem, paramName+"."+entry, raw); err != nil {
+				return nil, nil, fmt.Errorf("env %s: %w", envName, err)
+			}
+			m.SetMapIndex(ev, elem)
+			given.add(entry, sfx.field.index)
+			break
+		}
+	}
+	if m.Len() == 0 {
+		return nil, nil, nil
+	}
+	ptr := reflect.New(mapType)
+	ptr.Elem().Set(m)
+	return ptr.Interface(), given, nil
+}
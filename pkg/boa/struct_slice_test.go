@@ -0,0 +1,394 @@
+package boa
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type testServerConfig struct {
+	Host    string        `required:"true" pattern:"^[a-z0-9.-]+$"`
+	Port    int           `default:"80" min:"1" max:"65535"`
+	Proto   string        `default:"http" alts:"http,https"`
+	Timeout time.Duration `default:"5s"`
+	Weight  *int
+}
+
+func TestStructSlice_RepeatedFlags(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `descr:"backend servers" optional:"true"`
+	}
+
+	var got []testServerConfig
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Servers
+		},
+	}).RunArgsE([]string{"--servers", "host=a,port=8080", "--servers", "host=b,proto=https,timeout=1m,weight=3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 servers, got %d: %+v", len(got), got)
+	}
+	if got[0].Host != "a" || got[0].Port != 8080 || got[0].Proto != "http" || got[0].Timeout != 5*time.Second || got[0].Weight != nil {
+		t.Errorf("unexpected first server: %+v", got[0])
+	}
+	if got[1].Host != "b" || got[1].Port != 80 || got[1].Proto != "https" || got[1].Timeout != time.Minute {
+		t.Errorf("unexpected second server: %+v", got[1])
+	}
+	if got[1].Weight == nil || *got[1].Weight != 3 {
+		t.Errorf("expected weight 3, got %v", got[1].Weight)
+	}
+}
+
+func TestStructSlice_KeyMatching(t *testing.T) {
+	type Item struct {
+		MaxConns int    `optional:"true"`
+		Label    string `name:"lbl" optional:"true"`
+	}
+	type Params struct {
+		Items []Item `optional:"true"`
+	}
+
+	var got []Item
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Items
+		},
+	}).RunArgsE([]string{"--items", `max-conns=3,"lbl=a,b"`, "--items", "MaxConns=4,LBL=c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Item{{MaxConns: 3, Label: "a,b"}, {MaxConns: 4, Label: "c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestStructSlice_JSONStillWorks(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `optional:"true"`
+	}
+
+	var got []testServerConfig
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Servers
+		},
+	}).RunArgsE([]string{"--servers", `[{"Host":"a"},{"Host":"b","Port":81}]`, "--servers", `{"Host":"c"}`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 servers, got %+v", got)
+	}
+	// JSON elements are decoded over defaults
+	if got[0].Port != 80 || got[1].Port != 81 || got[2].Host != "c" {
+		t.Errorf("unexpected servers: %+v", got)
+	}
+}
+
+func TestStructSlice_UnknownKey(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `optional:"true"`
+	}
+
+	err := (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--servers", "host=a,nope=1"})
+	if err == nil || !IsUserInputError(err) {
+		t.Fatalf("expected user input error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `unknown key "nope"`) || !strings.Contains(err.Error(), "host, port, proto, timeout, weight") {
+		t.Errorf("expected unknown-key error listing keys, got: %v", err)
+	}
+}
+
+func TestStructSlice_ElementValidation(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `optional:"true"`
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"min", []string{"--servers", "host=a", "--servers", "host=b,port=0"}, "element 1: field 'port': value 0 is below min 1"},
+		{"pattern", []string{"--servers", "host=Bad_Host"}, "element 0: field 'host': value \"Bad_Host\" does not match pattern"},
+		{"alts", []string{"--servers", "host=a,proto=ftp"}, "element 0: field 'proto': 'ftp' is not in the list of allowed values"},
+		{"required", []string{"--servers", "port=1"}, "element 0: missing required field 'host'"},
+		{"type", []string{"--servers", "host=a,port=x"}, "port"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (CmdT[Params]{
+				Use:     "test",
+				RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+			}).RunArgsE(tc.args)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !IsUserInputError(err) {
+				t.Errorf("expected user input error, got %T: %v", err, err)
+			}
+			if !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("expected error containing %q, got: %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestStructSlice_IndexedEnvVars(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `env:"SERVERS" optional:"true"`
+	}
+
+	t.Setenv("SERVERS_0_HOST", "a")
+	t.Setenv("SERVERS_0_PORT", "8080")
+	t.Setenv("SERVERS_1_HOST", "b")
+	t.Setenv("SERVERS_1_TIMEOUT", "2s")
+
+	var got []testServerConfig
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Servers
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 servers, got %+v", got)
+	}
+	if got[0].Host != "a" || got[0].Port != 8080 || got[1].Host != "b" || got[1].Port != 80 || got[1].Timeout != 2*time.Second {
+		t.Errorf("unexpected servers: %+v", got)
+	}
+}
+
+func TestStructSlice_IndexedEnvVarGaps(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `env:"SERVERS" optional:"true"`
+	}
+	run := func() error {
+		return (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE([]string{})
+	}
+
+	t.Run("gap", func(t *testing.T) {
+		t.Setenv("SERVERS_0_HOST", "a")
+		t.Setenv("SERVERS_2_HOST", "c")
+		err := run()
+		if err == nil || !strings.Contains(err.Error(), "env SERVERS_2_HOST: element 1 has no env vars") {
+			t.Fatalf("expected gap error, got %v", err)
+		}
+	})
+
+	t.Run("orphan", func(t *testing.T) {
+		t.Setenv("SERVERS_1_HOST", "b")
+		err := run()
+		if err == nil || !strings.Contains(err.Error(), "env SERVERS_1_HOST: element 0 has no env vars") {
+			t.Fatalf("expected orphan error, got %v", err)
+		}
+	})
+}
+
+func TestStructSlice_EnvValidationAndCLIPriority(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `env:"SERVERS" optional:"true"`
+	}
+
+	t.Setenv("SERVERS_0_HOST", "a")
+	t.Setenv("SERVERS_0_PORT", "99999")
+
+	err := (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{})
+	if err == nil || !strings.Contains(err.Error(), "element 0: field 'port'") {
+		t.Fatalf("expected element validation error from env, got %v", err)
+	}
+
+	var got []testServerConfig
+	err = (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Servers
+		},
+	}).RunArgsE([]string{"--servers", "host=cli"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Host != "cli" {
+		t.Errorf("expected CLI to win over env, got %+v", got)
+	}
+}
+
+func TestStructSlice_PlainEnvVarJSON(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `env:"SERVERS" optional:"true"`
+	}
+
+	t.Setenv("SERVERS", `[{"Host":"j"}]`)
+
+	var got []testServerConfig
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Servers
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Host != "j" || got[0].Port != 80 {
+		t.Errorf("unexpected servers: %+v", got)
+	}
+}
+
+func TestStructSlice_ConfigFileElementsValidated(t *testing.T) {
+	type Params struct {
+		ConfigFile string             `configfile:"true" optional:"true"`
+		Servers    []testServerConfig `optional:"true"`
+	}
+
+	path := writeTestConfigFile(t, `{"Servers":[{"Host":"a"},{"Host":"b","Port":443,"Proto":"https"}]}`)
+	var got []testServerConfig
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Servers
+		},
+	}).RunArgsE([]string{"--config-file", path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Port != 80 || got[0].Proto != "http" || got[1].Port != 443 {
+		t.Errorf("expected config elements with defaults filled, got %+v", got)
+	}
+
+	bad := writeTestConfigFile(t, `{"Servers":[{"Host":"a","Proto":"gopher"}]}`)
+	err = (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--config-file", bad})
+	if err == nil || !strings.Contains(err.Error(), "element 0: field 'proto'") {
+		t.Errorf("expected element validation error for config value, got %v", err)
+	}
+}
+
+func TestStructSlice_ExplicitZeroValues(t *testing.T) {
+	type listener struct {
+		Name string `required:"true"`
+		TLS  bool   `default:"true"`
+		Port int    `default:"80"`
+	}
+	type Params struct {
+		ConfigFile string     `configfile:"true" optional:"true"`
+		Listeners  []listener `env:"LISTENERS" optional:"true"`
+	}
+
+	run := func(args ...string) ([]listener, error) {
+		var got []listener
+		err := (CmdT[Params]{
+			Use: "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+				got = p.Listeners
+			},
+		}).RunArgsE(args)
+		return got, err
+	}
+	want := []listener{{Name: "", TLS: false, Port: 0}, {Name: "b", TLS: true, Port: 80}}
+
+	path := writeTestConfigFile(t, `{"Listeners":[{"Name":"","TLS":false,"Port":0},{"Name":"b"}]}`)
+	if got, err := run("--config-file", path); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("config: expected explicit zeros to be kept, got %+v, %v", got, err)
+	}
+	if got, err := run("--listeners", "name=,tls=false,port=0", "--listeners", `{"Name":"b"}`); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("cli: expected explicit zeros to be kept, got %+v, %v", got, err)
+	}
+	if _, err := run("--listeners", "tls=false"); err == nil || !strings.Contains(err.Error(), "element 0: missing required field 'name'") {
+		t.Errorf("expected a left-out required field to still be missing, got %v", err)
+	}
+
+	t.Setenv("LISTENERS_0_NAME", "")
+	t.Setenv("LISTENERS_0_TLS", "false")
+	t.Setenv("LISTENERS_0_PORT", "0")
+	t.Setenv("LISTENERS_1_NAME", "b")
+	if got, err := run(); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("env: expected explicit zeros to be kept, got %+v, %v", got, err)
+	}
+}
+
+func TestStructSlice_UntaggedFieldsFollowRequiredDefault(t *testing.T) {
+	defer resetGlobalConfig()
+
+	type upstream struct {
+		Host   string
+		Weight *int
+		Tags   []string `optional:"true"`
+	}
+	type Params struct {
+		Upstreams []upstream `optional:"true"`
+	}
+	run := func(args ...string) error {
+		return (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(args)
+	}
+
+	if err := run("--upstreams", "weight=1"); err == nil || !strings.Contains(err.Error(), "element 0: missing required field 'host'") {
+		t.Errorf("expected an untagged string field to be required, got %v", err)
+	}
+	if err := run("--upstreams", "host=a"); err != nil {
+		t.Errorf("expected pointer and optional fields to be optional, got %v", err)
+	}
+
+	Init(WithDefaultOptional())
+	if err := run("--upstreams", "weight=1"); err != nil {
+		t.Errorf("expected WithDefaultOptional to make untagged fields optional, got %v", err)
+	}
+}
+
+func TestStructSlice_HelpShowsKeys(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `descr:"backend servers" optional:"true"`
+	}
+
+	usage := captureUsage(t, CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	})
+	if !strings.Contains(usage, "--servers key=value") {
+		t.Errorf("expected key=value placeholder in help:\n%s", usage)
+	}
+	if !strings.Contains(usage, "backend servers (keys: host, port, proto, timeout, weight)") {
+		t.Errorf("expected sub-keys in help:\n%s", usage)
+	}
+}
+
+func TestStructSlice_LengthBounds(t *testing.T) {
+	type Params struct {
+		Servers []testServerConfig `min:"2"`
+	}
+
+	err := (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--servers", "host=a"})
+	if err == nil || !strings.Contains(err.Error(), "length 1 is below min 2") {
+		t.Errorf("expected slice length error, got %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package boa
+
+import "os"
+
+// isTerminal reports whether f is a character device, which is the best
+// guess available here.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// disableEcho is not supported on this platform; secret input is echoed.
+func disableEcho(*os.File) (restore func(), ok bool) {
+	return nil, false
+}
@@ -0,0 +1,247 @@
+package boa
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	pflagValueType      = reflect.TypeOf((*pflag.Value)(nil)).Elem()
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// exactHandler returns the exact-type handler for t, registering one on the
+// fly when t implements pflag.Value or encoding.TextUnmarshaler (through
+// its pointer). This lets types like slog.Level or netip.Addr work without
+// an explicit RegisterType call.
+func exactHandler(t reflect.Type) (*typeHandler, bool) {
+	ensureHandlerRegistered(t)
+	return registeredHandler(t)
+}
+
+// registeredHandler returns the exact-type handler registered for t,
+// without registering one.
+func registeredHandler(t reflect.Type) (*typeHandler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	h, ok := exactTypeHandlers[t]
+	return h, ok
+}
+
+// sliceExactHandler returns the handler registered for slices of elem.
+func sliceExactHandler(elem reflect.Type) (*typeHandler, bool) {
+	handlersMu.RLock()
+	defer handlersMu.RUnlock()
+	h, ok := sliceExactTypeHandlers[elem]
+	return h, ok
+}
+
+// ensureHandlerRegistered registers the scalar and slice handlers for t on
+// first use if it implements pflag.Value or encoding.TextUnmarshaler, so
+// that the registries can be consulted for t afterwards.
+func ensureHandlerRegistered(t reflect.Type) {
+	if _, ok := registeredHandler(t); !ok {
+		registerInterfaceType(t)
+	}
+}
+
+// registerInterfaceType registers scalar and slice handlers for t if t (or
+// *t) implements pflag.Value or encoding.TextUnmarshaler. pflag.Value wins
+// when both are implemented. Pointer and interface types are never
+// registered; pointer fields are unwrapped before lookup.
+func registerInterfaceType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		// No scalar handler for *T, but registering T also covers []*T
+		registerInterfaceType(t.Elem())
+		return false
+	}
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	pt := reflect.PointerTo(t)
+
+	var parse func(string) (reflect.Value, error)
+	switch {
+	case pt.Implements(pflagValueType):
+		parse = func(s string) (reflect.Value, error) {
+			ptr := reflect.New(t)
+			if err := ptr.Interface().(pflag.Value).Set(s); err != nil {
+				return reflect.Value{}, err
+			}
+			return ptr, nil
+		}
+	case pt.Implements(textUnmarshalerType):
+		parse = func(s string) (reflect.Value, error) {
+			ptr := reflect.New(t)
+			if err := ptr.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+				return reflect.Value{}, err
+			}
+			return ptr, nil
+		}
+	default:
+		return false
+	}
+
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	if _, ok := exactTypeHandlers[t]; ok {
+		// Registered by another command being built concurrently
+		return true
+	}
+	setStringBacked(t, parse, formatterFor(t))
+	return true
+}
+
+// registerStringBacked registers handlers for t, []t and []*t that store the
+// value as a string (slice) flag in cobra and convert it with parse. parse
+// returns a *t, which pointer fields and []*t elements store as-is.
+func registerStringBacked(t reflect.Type, parse func(string) (reflect.Value, error), format func(reflect.Value) string) {
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	setStringBacked(t, parse, format)
+}
+
+// setStringBacked is registerStringBacked for callers holding handlersMu.
+func setStringBacked(t reflect.Type, parse func(string) (reflect.Value, error), format func(reflect.Value) string) {
+	exactTypeHandlers[t] = stringBackedHandler(t, parse, format)
+	sliceExactTypeHandlers[t] = stringBackedSliceHandler(t, parse, format)
+	sliceExactTypeHandlers[reflect.PointerTo(t)] = stringBackedSliceHandler(reflect.PointerTo(t), parse, format)
+}
+
+// formatterFor returns how values of t are rendered as defaults:
+// MarshalText, then String, then %v.
+func formatterFor(t reflect.Type) func(reflect.Value) string {
+	pt := reflect.PointerTo(t)
+	return func(v reflect.Value) string {
+		// Work on an addressable copy so pointer-receiver methods are reachable
+		ptr := reflect.New(t)
+		ptr.Elem().Set(v)
+		switch {
+		case pt.Implements(textMarshalerType):
+			if b, err := ptr.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+				return string(b)
+			}
+		case pt.Implements(stringerType):
+			return ptr.Interface().(fmt.Stringer).String()
+		}
+		return fmt.Sprintf("%v", v.Interface())
+	}
+}
+
+// stringBackedHandler stores t as a string flag in cobra and converts it with
+// parse during validation — the same shape RegisterType produces.
+func stringBackedHandler(t reflect.Type, parse func(string) (reflect.Value, error), format func(reflect.Value) string) *typeHandler {
+	return &typeHandler{
+		baseType: t,
+		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
+			def := ""
+			if defaultVal != nil {
+				v := reflect.ValueOf(defaultVal)
+				if v.Kind() == reflect.Pointer && !v.IsNil() {
+					def = format(v.Elem())
+				}
+			}
+			return cmd.Flags().StringP(name, short, def, descr)
+		},
+		parse: func(name, strVal string) (any, error) {
+			ptr, err := parse(strVal)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for param %s: %w", name, err)
+			}
+			return ptr.Interface(), nil
+		},
+		convert: func(name string, val any) (any, error) {
+			if strPtr, ok := val.(*string); ok {
+				if *strPtr == "" {
+					return reflect.New(t).Interface(), nil
+				}
+				ptr, err := parse(*strPtr)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for param '%s': %w", name, err)
+				}
+				return ptr.Interface(), nil
+			}
+			return val, nil // already converted
+		},
+	}
+}
+
+// stringBackedSliceHandler is the []elemType counterpart of
+// stringBackedHandler, stored as a string slice flag (comma-separated and/or
+// repeated). elemType is either the value type or a pointer to it.
+func stringBackedSliceHandler(elemType reflect.Type, parse func(string) (reflect.Value, error), format func(reflect.Value) string) *typeHandler {
+	sliceType := reflect.SliceOf(elemType)
+	elemIsPtr := elemType.Kind() == reflect.Pointer
+	parseAll := func(strs []string) (reflect.Value, int, error) {
+		result := reflect.MakeSlice(sliceType, len(strs), len(strs))
+		for i, s := range strs {
+			ptr, err := parse(strings.TrimSpace(s))
+			if err != nil {
+				return reflect.Value{}, i, err
+			}
+			if elemIsPtr {
+				result.Index(i).Set(ptr)
+			} else {
+				result.Index(i).Set(ptr.Elem())
+			}
+		}
+		ptr := reflect.New(sliceType)
+		ptr.Elem().Set(result)
+		return ptr, 0, nil
+	}
+	return &typeHandler{
+		baseType: sliceType,
+		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
+			var def []string
+			if defaultVal != nil {
+				defVal := reflect.ValueOf(defaultVal).Elem()
+				if defVal.Kind() == reflect.Slice && defVal.Type().Elem() == elemType {
+					def = make([]string, defVal.Len())
+					for i := range def {
+						elem := defVal.Index(i)
+						if elemIsPtr {
+							if elem.IsNil() {
+								continue
+							}
+							elem = elem.Elem()
+						}
+						def[i] = format(elem)
+					}
+				}
+			}
+			return cmd.Flags().StringSliceP(name, short, def, descr)
+		},
+		parse: func(name, strVal string) (any, error) {
+			strVal = strings.TrimSpace(strVal)
+			if strings.HasPrefix(strVal, "[") && strings.HasSuffix(strVal, "]") {
+				strVal = strVal[1 : len(strVal)-1]
+			}
+			var strs []string
+			if strVal != "" {
+				strs = strings.Split(strVal, ",")
+			}
+			ptr, _, err := parseAll(strs)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for param %s: %w", name, err)
+			}
+			return ptr.Interface(), nil
+		},
+		convert: func(name string, val any) (any, error) {
+			if strSlice, ok := val.(*[]string); ok && strSlice != nil {
+				ptr, i, err := parseAll(*strSlice)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for param '%s' at index %d: %s", name, i, err.Error())
+				}
+				return ptr.Interface(), nil
+			}
+			return val, nil
+		},
+	}
+}
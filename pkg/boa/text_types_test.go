@@ -0,0 +1,196 @@
+package boa
+
+import (
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// testColor implements pflag.Value (and nothing else).
+type testColor struct{ name string }
+
+func (c *testColor) Set(s string) error {
+	switch s {
+	case "red", "green", "blue":
+		c.name = s
+		return nil
+	}
+	return fmt.Errorf("unknown color %q", s)
+}
+func (c *testColor) String() string { return c.name }
+func (c *testColor) Type() string   { return "color" }
+
+func TestTextTypes_Scalars(t *testing.T) {
+	type Params struct {
+		Level slog.Level  `default:"warn"`
+		Addr  netip.Addr  `optional:"true"`
+		Gw    *netip.Addr `optional:"true"`
+		Color testColor   `default:"red"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--addr", "10.0.0.1", "--gw", "::1", "--color", "blue"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Level != slog.LevelWarn {
+		t.Errorf("expected default level WARN, got %v", got.Level)
+	}
+	if got.Addr != netip.MustParseAddr("10.0.0.1") {
+		t.Errorf("unexpected addr: %v", got.Addr)
+	}
+	if got.Gw == nil || *got.Gw != netip.MustParseAddr("::1") {
+		t.Errorf("unexpected gw: %v", got.Gw)
+	}
+	if got.Color.name != "blue" {
+		t.Errorf("expected color blue, got %q", got.Color.name)
+	}
+}
+
+func TestTextTypes_SlicesAndMaps(t *testing.T) {
+	type Params struct {
+		Addrs  []netip.Addr          `optional:"true"`
+		Levels map[string]slog.Level `optional:"true"`
+		Colors []testColor           `optional:"true"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{
+		"--addrs", "10.0.0.1,10.0.0.2", "--addrs", "::1",
+		"--levels", "db=debug,http=error",
+		"--colors", "red,green",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantAddrs := []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("::1")}
+	if !reflect.DeepEqual(got.Addrs, wantAddrs) {
+		t.Errorf("expected %v, got %v", wantAddrs, got.Addrs)
+	}
+	if !reflect.DeepEqual(got.Levels, map[string]slog.Level{"db": slog.LevelDebug, "http": slog.LevelError}) {
+		t.Errorf("unexpected levels: %v", got.Levels)
+	}
+	if len(got.Colors) != 2 || got.Colors[0].name != "red" || got.Colors[1].name != "green" {
+		t.Errorf("unexpected colors: %v", got.Colors)
+	}
+}
+
+func TestTextTypes_EnvVar(t *testing.T) {
+	type Params struct {
+		Level slog.Level   `env:"LOG_LEVEL" optional:"true"`
+		Addrs []netip.Addr `env:"ADDRS" optional:"true"`
+	}
+
+	t.Setenv("LOG_LEVEL", "error")
+	t.Setenv("ADDRS", "1.1.1.1,8.8.8.8")
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Level != slog.LevelError || len(got.Addrs) != 2 || got.Addrs[1] != netip.MustParseAddr("8.8.8.8") {
+		t.Errorf("unexpected values: %+v", got)
+	}
+}
+
+func TestTextTypes_InvalidValue(t *testing.T) {
+	type Params struct {
+		Addr  netip.Addr `optional:"true"`
+		Color testColor  `optional:"true"`
+	}
+
+	for _, args := range [][]string{{"--addr", "nope"}, {"--color", "pink"}} {
+		err := (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(args)
+		if err == nil || !IsUserInputError(err) {
+			t.Errorf("expected user input error for %v, got %v", args, err)
+		}
+	}
+}
+
+func TestTextTypes_HelpShowsFormattedDefaults(t *testing.T) {
+	type Params struct {
+		Level slog.Level   `default:"debug" descr:"log level"`
+		Addrs []netip.Addr `default:"[10.0.0.1,10.0.0.2]" descr:"addresses"`
+		Color testColor    `default:"green" descr:"color"`
+	}
+
+	usage := captureUsage(t, CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	})
+	for _, want := range []string{`(default "DEBUG")`, "[10.0.0.1,10.0.0.2]", `(default "green")`} {
+		if !strings.Contains(usage, want) {
+			t.Errorf("expected %s in help:\n%s", want, usage)
+		}
+	}
+}
+
+func TestTextTypes_NotTreatedAsSubstruct(t *testing.T) {
+	if !isSupportedType(reflect.TypeOf(netip.Addr{})) {
+		t.Error("expected netip.Addr to be a supported scalar type")
+	}
+	if isStructElemType(reflect.TypeOf(netip.Prefix{})) {
+		t.Error("expected netip.Prefix not to be treated as a struct element")
+	}
+}
+
+// raceText is only used by TestTextTypes_ConcurrentBuilds, so that its
+// handlers are registered while commands are built concurrently.
+type raceText struct{ s string }
+
+func (r *raceText) UnmarshalText(b []byte) error { r.s = string(b); return nil }
+
+type raceEnum int
+
+func TestTextTypes_ConcurrentBuilds(t *testing.T) {
+	type Params struct {
+		One  raceText            `optional:"true"`
+		Many []raceText          `optional:"true"`
+		ByID map[string]raceText `optional:"true"`
+		Mode raceEnum            `optional:"true"`
+	}
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i == 0 {
+				RegisterEnum(map[string]raceEnum{"a": 0, "b": 1})
+			}
+			var got Params
+			err := (CmdT[Params]{
+				Use:     "test",
+				RunFunc: func(p *Params, cmd *cobra.Command, args []string) { got = *p },
+			}).RunArgsE([]string{"--one", "x", "--many", "y"})
+			if err != nil || got.One.s != "x" || len(got.Many) != 1 {
+				t.Errorf("unexpected result %+v: %v", got, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,302 @@
+package boa
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultTimeLayouts are tried in order when a time.Time param has no
+// `layout` tag.
+var defaultTimeLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	"2006-01-02",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+// timeFormat controls how a time.Time param is parsed and rendered. The zero
+// value is the default: any of defaultTimeLayouts, UTC unless the string
+// carries an offset, rendered as RFC3339. Both forms also accept relative
+// expressions like "now-1h" (see parseNowOffset).
+type timeFormat struct {
+	layout string
+	loc    *time.Location
+}
+
+func (tf timeFormat) isDefault() bool { return tf.layout == "" && tf.loc == nil }
+
+func (tf timeFormat) parse(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if offset, ok, err := parseNowOffset(s); ok {
+		if err != nil {
+			return time.Time{}, err
+		}
+		// Round(0) drops the monotonic reading, so the value compares and
+		// prints like a parsed wall-clock time.
+		return tf.in(time.Now().Round(0).Add(offset)), nil
+	}
+	loc := tf.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	if tf.layout != "" {
+		t, err := time.ParseInLocation(tf.layout, s, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unable to parse time %q (expected layout %s)", s, tf.layout)
+		}
+		return t, nil
+	}
+	for _, layout := range defaultTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse time: %s", s)
+}
+
+func (tf timeFormat) format(t time.Time) string {
+	layout := tf.layout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	return tf.in(t).Format(layout)
+}
+
+func (tf timeFormat) in(t time.Time) time.Time {
+	if tf.loc != nil {
+		return t.In(tf.loc)
+	}
+	return t
+}
+
+// parseTimeString parses a time string with the default timeFormat.
+func parseTimeString(s string) (time.Time, error) {
+	return timeFormat{}.parse(s)
+}
+
+// dayWeekUnit matches the d/w units parseNowOffset adds on top of
+// time.ParseDuration's.
+var dayWeekUnit = regexp.MustCompile(`([0-9]*\.?[0-9]+)([dw])`)
+
+// parseNowOffset recognizes "now", "now+30d", "now-1h30m" and the like.
+// ok reports whether s is a now-expression at all; err whether it is a
+// valid one. Offsets use time.ParseDuration units plus d (24h) and w (7d).
+func parseNowOffset(s string) (offset time.Duration, ok bool, err error) {
+	rest, found := strings.CutPrefix(strings.ToLower(strings.TrimSpace(s)), "now")
+	if !found {
+		return 0, false, nil
+	}
+	rest = strings.ReplaceAll(rest, " ", "")
+	if rest == "" {
+		return 0, true, nil
+	}
+	if rest[0] != '+' && rest[0] != '-' {
+		return 0, true, fmt.Errorf("invalid relative time %q (expected e.g. now-1h or now+30d)", s)
+	}
+	expanded := dayWeekUnit.ReplaceAllStringFunc(rest[1:], func(m string) string {
+		parts := dayWeekUnit.FindStringSubmatch(m)
+		n, _ := strconv.ParseFloat(parts[1], 64)
+		if parts[2] == "w" {
+			n *= 7
+		}
+		return strconv.FormatFloat(n*24, 'f', -1, 64) + "h"
+	})
+	d, perr := time.ParseDuration(expanded)
+	if perr != nil {
+		return 0, true, fmt.Errorf("invalid relative time %q (expected e.g. now-1h or now+30d)", s)
+	}
+	if rest[0] == '-' {
+		d = -d
+	}
+	return d, true, nil
+}
+
+// timeHandler is the handler for time.Time params using tf. The default
+// instance is registered for time.Time; params with a `layout` or `tz` tag
+// get their own (see timeParamHandler).
+func timeHandler(tf timeFormat) *typeHandler {
+	return &typeHandler{
+		baseType: timeType,
+		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
+			def := ""
+			if defaultVal != nil {
+				def = tf.format(reflect.ValueOf(defaultVal).Elem().Interface().(time.Time))
+			}
+			return cmd.Flags().StringP(name, short, def, descr)
+		},
+		parse: func(name, strVal string) (any, error) {
+			v, err := tf.parse(strVal)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for param %s: %s", name, err.Error())
+			}
+			return &v, nil
+		},
+		convert: func(name string, val any) (any, error) {
+			if strPtr, ok := val.(*string); ok {
+				v, err := tf.parse(*strPtr)
+				if err != nil {
+					return nil, fmt.Errorf("invalid value for param '%s': %s", name, err.Error())
+				}
+				return &v, nil
+			}
+			return val, nil // already a *time.Time (e.g., from struct literal)
+		},
+	}
+}
+
+// timeSliceHandler is the []time.Time counterpart of timeHandler, stored as
+// []string and converted later.
+func timeSliceHandler(tf timeFormat) *typeHandler {
+	return &typeHandler{
+		baseType: reflect.SliceOf(timeType),
+		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
+			var def []string
+			if defaultVal != nil {
+				defVal := reflect.ValueOf(defaultVal).Elem()
+				if defVal.Kind() == reflect.Slice && defVal.Type().Elem() == timeType {
+					times := defVal.Interface().([]time.Time)
+					def = make([]string, len(times))
+					for i, t := range times {
+						def[i] = tf.format(t)
+					}
+				}
+			}
+			return cmd.Flags().StringSliceP(name, short, def, descr)
+		},
+		parse: func(name, strVal string) (any, error) {
+			return parseSliceWith(strVal, tf.parse)
+		},
+		convert: func(name string, val any) (any, error) {
+			if strSlice, ok := val.(*[]string); ok && strSlice != nil {
+				times := make([]time.Time, len(*strSlice))
+				for i, s := range *strSlice {
+					t, err := tf.parse(s)
+					if err != nil {
+						return nil, fmt.Errorf("invalid value for param '%s' at index %d: %s", name, i, err.Error())
+					}
+					times[i] = t
+				}
+				return &times, nil
+			}
+			return val, nil
+		},
+	}
+}
+
+// timeParamHandler returns a handler honouring f's time layout / zone, or nil
+// when f isn't a time.Time or []time.Time param or uses the defaults.
+func timeParamHandler(f Param) *typeHandler {
+	pm, ok := f.(*paramMeta)
+	if !ok || pm.timeFormat().isDefault() {
+		return nil
+	}
+	switch {
+	case pm.fieldType == timeType:
+		return timeHandler(pm.timeFormat())
+	case pm.fieldType.Kind() == reflect.Slice && pm.fieldType.Elem() == timeType:
+		return timeSliceHandler(pm.timeFormat())
+	}
+	return nil
+}
+
+// paramHandler is lookupHandler for a specific param.
+func paramHandler(f Param) *typeHandler {
+	if f.GetKind() != reflect.Slice {
+		if h := timeParamHandler(f); h != nil {
+			return h
+		}
+	}
+	h, _ := lookupHandler(f.GetType())
+	return h
+}
+
+// paramSliceHandler is lookupSliceHandler for a specific slice param.
+func paramSliceHandler(f Param) *typeHandler {
+	if h := timeParamHandler(f); h != nil {
+		return h
+	}
+	return lookupSliceHandler(f.GetType().Elem())
+}
+
+// parseParamPtr is parsePtr for a specific param.
+func parseParamPtr(f Param, strVal string) (any, error) {
+	if h := timeParamHandler(f); h != nil {
+		return h.parse(f.GetName(), strVal)
+	}
+	return parsePtr(f.GetName(), f.GetType(), f.GetKind(), strVal)
+}
+
+// --- Bounds ---
+
+// timeBoundValue is a min/max bound on a time.Time field: either a fixed
+// instant or an offset from the current time, resolved at validation so
+// `max:"now+30d"` keeps meaning "30 days from now" in long-lived processes.
+type timeBoundValue struct {
+	at       time.Time
+	offset   time.Duration
+	relative bool
+	expr     string // the relative expression, for error messages
+}
+
+func (b *timeBoundValue) resolve(now time.Time) time.Time {
+	if b.relative {
+		return now.Add(b.offset)
+	}
+	return b.at
+}
+
+// describe renders the bound for an error message, keeping the relative
+// expression visible: "2026-01-01T00:00:00Z (now+30d)".
+func (b *timeBoundValue) describe(tf timeFormat, now time.Time) string {
+	s := tf.format(b.resolve(now))
+	if b.relative {
+		s += " (" + b.expr + ")"
+	}
+	return s
+}
+
+// parseTimeBound parses a min/max tag on a time.Time field: a time in tf's
+// layout, or a now-expression.
+func parseTimeBound(s string, tf timeFormat) (*timeBoundValue, error) {
+	if offset, ok, err := parseNowOffset(s); ok {
+		if err != nil {
+			return nil, err
+		}
+		return &timeBoundValue{offset: offset, relative: true, expr: strings.TrimSpace(s)}, nil
+	}
+	t, err := tf.parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &timeBoundValue{at: t}, nil
+}
+
+// parseDurationBound parses a min/max tag on a time.Duration field. Plain
+// integers are nanoseconds, as they always were; anything else goes
+// through time.ParseDuration.
+func parseDurationBound(s string) (*int64, error) {
+	if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return &v, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return nil, fmt.Errorf("expected duration (e.g. 1s, 24h), got %q", s)
+	}
+	v := int64(d)
+	return &v, nil
+}
+
+// lookupTimeZoneTag reads the `tz` tag (alias `timezone`).
+func lookupTimeZoneTag(tags reflect.StructTag) (string, bool) {
+	if tz, ok := tags.Lookup("tz"); ok {
+		return tz, true
+	}
+	return tags.Lookup("timezone")
+}
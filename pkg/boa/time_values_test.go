@@ -0,0 +1,240 @@
+package boa
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestParseNowOffset(t *testing.T) {
+	cases := map[string]time.Duration{
+		"now":        0,
+		"now+1h":     time.Hour,
+		"now-30m":    -30 * time.Minute,
+		"now+1d":     24 * time.Hour,
+		"now-1w2h":   -(7*24 + 2) * time.Hour,
+		"NOW + 1.5d": 36 * time.Hour,
+	}
+	for in, want := range cases {
+		got, ok, err := parseNowOffset(in)
+		if !ok || err != nil || got != want {
+			t.Errorf("parseNowOffset(%q) = %v, %v, %v; want %v", in, got, ok, err, want)
+		}
+	}
+	for _, bad := range []string{"now*1h", "now+1x", "now+"} {
+		if _, ok, err := parseNowOffset(bad); !ok || err == nil {
+			t.Errorf("expected error for %q, got ok=%v err=%v", bad, ok, err)
+		}
+	}
+	if _, ok, _ := parseNowOffset("2024-01-01"); ok {
+		t.Error("plain dates are not now-expressions")
+	}
+}
+
+func TestTimeFormat_NowHasNoMonotonicReading(t *testing.T) {
+	got, err := timeFormat{}.parse("now-1h")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != got.Round(0) {
+		t.Errorf("expected no monotonic clock reading, got %s", got)
+	}
+}
+
+func TestDurationBounds(t *testing.T) {
+	type Params struct {
+		Timeout time.Duration `optional:"true" min:"1s" max:"24h"`
+		Legacy  time.Duration `optional:"true" max:"1000000000"`
+	}
+	run := func(args ...string) error {
+		return (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(args)
+	}
+
+	if err := run("--timeout", "90m", "--legacy", "1s"); err != nil {
+		t.Errorf("expected values within bounds to pass, got %v", err)
+	}
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--timeout", "500ms"}, "value 500ms is below min 1s"},
+		{[]string{"--timeout", "25h"}, "value 25h0m0s exceeds max 24h0m0s"},
+		{[]string{"--legacy", "2s"}, "value 2s exceeds max 1s"},
+	}
+	for _, tc := range cases {
+		if err := run(tc.args...); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: expected error containing %q, got %v", tc.args, tc.want, err)
+		}
+	}
+
+	type Bad struct {
+		Timeout time.Duration `min:"soon"`
+	}
+	_, err := (CmdT[Bad]{Use: "test"}).ToCobraE()
+	if err == nil || !strings.Contains(err.Error(), "expected duration") {
+		t.Errorf("expected invalid duration bound error, got %v", err)
+	}
+}
+
+func TestTimeBounds(t *testing.T) {
+	type Params struct {
+		Since time.Time `optional:"true" min:"2020-01-01" max:"now+30d"`
+	}
+	run := func(args ...string) error {
+		return (CmdT[Params]{
+			Use:     "test",
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+		}).RunArgsE(args)
+	}
+
+	if err := run("--since", "2024-05-06"); err != nil {
+		t.Errorf("expected value within bounds to pass, got %v", err)
+	}
+	err := run("--since", "2019-12-31")
+	if err == nil || !strings.Contains(err.Error(), "value 2019-12-31T00:00:00Z is before min 2020-01-01T00:00:00Z") {
+		t.Errorf("expected min error, got %v", err)
+	}
+	err = run("--since", "now+60d")
+	if err == nil || !strings.Contains(err.Error(), "is after max") || !strings.Contains(err.Error(), "(now+30d)") {
+		t.Errorf("expected relative max error, got %v", err)
+	}
+}
+
+func TestTimeBounds_Programmatic(t *testing.T) {
+	type Params struct {
+		At time.Time `optional:"true"`
+	}
+	limit := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	var seen any
+	err := (CmdT[Params]{
+		Use: "test",
+		InitFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command) error {
+			GetParamT(ctx, &p.At).SetMaxT(limit)
+			ctx.GetParam(&p.At).SetMin("now-1h")
+			seen = ctx.GetParam(&p.At).GetMax()
+			return nil
+		},
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--at", "2031-01-01"})
+	if err == nil || !strings.Contains(err.Error(), "is after max 2030-01-01T00:00:00Z") {
+		t.Errorf("expected max error, got %v", err)
+	}
+	if got, ok := seen.(*time.Time); !ok || !got.Equal(limit) {
+		t.Errorf("expected GetMax to return *time.Time %v, got %v", limit, seen)
+	}
+}
+
+func TestTimeValues_Relative(t *testing.T) {
+	type Params struct {
+		Since time.Time `optional:"true"`
+	}
+	var got time.Time
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Since
+		},
+	}).RunArgsE([]string{"--since", "now-1h"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d := time.Since(got); d < time.Hour || d > time.Hour+time.Minute {
+		t.Errorf("expected about an hour ago, got %v (%v ago)", got, d)
+	}
+}
+
+func TestTimeLayoutAndZoneTags(t *testing.T) {
+	type Params struct {
+		Day     time.Time   `layout:"02/01/2006" default:"24/12/2024" descr:"day"`
+		Meeting time.Time   `layout:"2006-01-02 15:04" tz:"Europe/Stockholm" optional:"true"`
+		Dates   []time.Time `layout:"2006-01-02" optional:"true"`
+		Local   *time.Time  `timezone:"America/New_York" optional:"true"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--meeting", "2024-06-01 09:30", "--dates", "2024-01-01,2024-02-01", "--local", "2024-06-01T12:00:00"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC); !got.Day.Equal(want) {
+		t.Errorf("expected day %v, got %v", want, got.Day)
+	}
+	if got.Meeting.Location().String() != "Europe/Stockholm" || got.Meeting.Hour() != 9 || got.Meeting.UTC().Hour() != 7 {
+		t.Errorf("expected 09:30 Stockholm time, got %v", got.Meeting)
+	}
+	if len(got.Dates) != 2 || got.Dates[1].Month() != time.February {
+		t.Errorf("unexpected dates: %v", got.Dates)
+	}
+	if got.Local == nil || got.Local.Location().String() != "America/New_York" {
+		t.Errorf("expected New York time, got %v", got.Local)
+	}
+
+	err = (CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	}).RunArgsE([]string{"--day", "2024-12-24"})
+	if err == nil || !strings.Contains(err.Error(), "expected layout 02/01/2006") {
+		t.Errorf("expected layout error, got %v", err)
+	}
+
+	usage := captureUsage(t, CmdT[Params]{
+		Use:     "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {},
+	})
+	if !strings.Contains(usage, `(default "24/12/2024")`) {
+		t.Errorf("expected default rendered in layout:\n%s", usage)
+	}
+}
+
+func TestTimeLayout_Programmatic(t *testing.T) {
+	type Params struct {
+		Day time.Time `optional:"true" env:"DAY"`
+	}
+	t.Setenv("DAY", "2024.03.05")
+
+	var got time.Time
+	err := (CmdT[Params]{
+		Use: "test",
+		InitFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command) error {
+			GetParamT(ctx, &p.Day).SetTimeLayout("2006.01.02")
+			return nil
+		},
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = p.Day
+		},
+	}).RunArgsE([]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Month() != time.March || got.Day() != 5 {
+		t.Errorf("expected 2024-03-05, got %v", got)
+	}
+}
+
+func TestTimeLayoutTags_Invalid(t *testing.T) {
+	type BadZone struct {
+		At time.Time `tz:"Mars/Olympus_Mons" optional:"true"`
+	}
+	_, err := (CmdT[BadZone]{Use: "test"}).ToCobraE()
+	if err == nil || !strings.Contains(err.Error(), `unknown time zone "Mars/Olympus_Mons"`) {
+		t.Errorf("expected unknown time zone error, got %v", err)
+	}
+
+	type NotTime struct {
+		Name string `layout:"2006" optional:"true"`
+	}
+	_, err = (CmdT[NotTime]{Use: "test"}).ToCobraE()
+	if err == nil || !strings.Contains(err.Error(), "only time.Time fields take a layout") {
+		t.Errorf("expected layout-on-string error, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package boa
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// TransformFunc normalizes a string value before validation. Use with
+// RegisterTransform to make it available to the `transform` tag.
+type TransformFunc func(string) (string, error)
+
+// transforms is the registry behind the `transform` tag.
+var transforms = map[string]TransformFunc{
+	"trim":        func(s string) (string, error) { return strings.TrimSpace(s), nil },
+	"lower":       func(s string) (string, error) { return strings.ToLower(s), nil },
+	"upper":       func(s string) (string, error) { return strings.ToUpper(s), nil },
+	"expand-home": func(s string) (string, error) { return expandHome(processEnv{}, s) },
+	"expand-env":  func(s string) (string, error) { return expandEnv(processEnv{}, s), nil },
+	"abs-path": func(s string) (string, error) {
+		if s == "" {
+			return s, nil
+		}
+		return filepath.Abs(s)
+	},
+	"clean-path": func(s string) (string, error) {
+		if s == "" {
+			return s, nil
+		}
+		return filepath.Clean(s), nil
+	},
+}
+
+// RegisterTransform makes fn available as `transform:"<name>"` and through
+// Param.SetTransforms. Registering an existing name replaces it, including
+// the built-ins: trim, lower, upper, expand-home, expand-env, abs-path and
+// clean-path.
+//
+// Example:
+//
+//	boa.RegisterTransform("strip-scheme", func(s string) (string, error) {
+//	    return strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://"), nil
+//	})
+func RegisterTransform(name string, fn TransformFunc) {
+	transforms[name] = fn
+	delete(envTransforms, name)
+}
+
+// envTransforms are the built-ins reading env vars. They take precedence
+// over their entries in transforms, which read the process environment, so
+// that they see the command's (see CmdT.LookupEnv).
+var envTransforms = map[string]func(env envSource, s string) (string, error){
+	"expand-home": expandHome,
+	"expand-env":  func(env envSource, s string) (string, error) { return expandEnv(env, s), nil },
+}
+
+func expandHome(env envSource, s string) (string, error) {
+	if s != "~" && !strings.HasPrefix(s, "~/") && !strings.HasPrefix(s, `~\`) {
+		return s, nil
+	}
+	home, err := homeDir(env)
+	if err != nil {
+		return "", err
+	}
+	return home + s[1:], nil
+}
+
+func expandEnv(env envSource, s string) string {
+	return os.Expand(s, func(key string) string {
+		val, _ := env.lookupEnv(key)
+		return val
+	})
+}
+
+// checkTransforms reports the first name in names that isn't registered.
+func checkTransforms(names []string) error {
+	for _, name := range names {
+		if _, ok := transforms[name]; !ok {
+			known := make([]string, 0, len(transforms))
+			for k := range transforms {
+				known = append(known, k)
+			}
+			slices.Sort(known)
+			return fmt.Errorf("unknown transform %q (known: %s)", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// supportsTransforms reports whether t (a param's value type) is a string
+// or a slice of strings — the only shapes transforms apply to.
+func supportsTransforms(t reflect.Type) bool {
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String)
+}
+
+// applyTransforms runs the param's transforms over its current value,
+// element-wise for slices. The value is rewritten in place: for values
+// injected from the struct (config files, struct literals) the mirror points
+// at the field itself, and CLI / env values are copied to the field by the
+// next syncMirrors.
+func applyTransforms(env envSource, pm *paramMeta) error {
+	if len(pm.transforms) == 0 {
+		return nil
+	}
+	ptr := pm.valuePtr
+	if ptr == nil {
+		// Default-only value: materialize it so the result sticks
+		ptr = pm.defaultValuePtr()
+		if ptr == nil {
+			return nil
+		}
+		pm.setValuePtr(ptr)
+	}
+	v := reflect.ValueOf(ptr).Elem()
+	switch {
+	case v.Kind() == reflect.String:
+		s, err := runTransforms(env, pm.transforms, v.String())
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		if v.IsNil() {
+			return nil
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s, err := runTransforms(env, pm.transforms, v.Index(i).String())
+			if err != nil {
+				return fmt.Errorf("index %d: %w", i, err)
+			}
+			out.Index(i).SetString(s)
+		}
+		v.Set(out)
+	}
+	return nil
+}
+
+func runTransforms(env envSource, names []string, s string) (string, error) {
+	for _, name := range names {
+		var err error
+		if fn, ok := envTransforms[name]; ok {
+			s, err = fn(env, s)
+		} else {
+			s, err = transforms[name](s)
+		}
+		if err != nil {
+			return "", fmt.Errorf("transform %s: %w", name, err)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,159 @@
+package boa
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestTransforms_RunBeforeValidation(t *testing.T) {
+	type Params struct {
+		Host string   `transform:"trim,lower" alts:"a.example,b.example"`
+		Tag  string   `transform:"upper" pattern:"^[A-Z]+$" optional:"true"`
+		Name *string  `transform:"trim" optional:"true"`
+		Envs []string `transform:"trim,lower" alts:"dev,prod" optional:"true"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--host", "  A.Example ", "--tag", "abc", "--name", " bob ", "--envs", " Dev,PROD"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Host != "a.example" || got.Tag != "ABC" || got.Name == nil || *got.Name != "bob" {
+		t.Errorf("unexpected values: %+v (name %v)", got, got.Name)
+	}
+	if !reflect.DeepEqual(got.Envs, []string{"dev", "prod"}) {
+		t.Errorf("expected element-wise transforms, got %v", got.Envs)
+	}
+}
+
+func TestTransforms_AllSources(t *testing.T) {
+	type Params struct {
+		ConfigFile string `configfile:"true" optional:"true"`
+		FromEnv    string `env:"FROM_ENV" transform:"lower" optional:"true"`
+		FromConfig string `transform:"lower" optional:"true"`
+		FromDef    string `transform:"lower" default:"DEFAULT"`
+	}
+	t.Setenv("FROM_ENV", "ENV")
+	path := writeTestConfigFile(t, `{"FromConfig":"CONFIG"}`)
+
+	var got Params
+	var mirrored string
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+			mirrored = *ctx.GetParam(&p.FromConfig).(*paramMeta).valuePtrF().(*string)
+		},
+	}).RunArgsE([]string{"--config-file", path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.FromEnv != "env" || got.FromConfig != "config" || got.FromDef != "default" {
+		t.Errorf("expected every source transformed, got %+v", got)
+	}
+	if mirrored != "config" {
+		t.Errorf("expected mirror to hold transformed value, got %q", mirrored)
+	}
+}
+
+func TestTransforms_Paths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+	t.Setenv("DATA_DIR", "/srv/data")
+
+	type Params struct {
+		Home  string `transform:"expand-home" optional:"true"`
+		Env   string `transform:"expand-env,clean-path" optional:"true"`
+		Abs   string `transform:"abs-path" optional:"true"`
+		Plain string `transform:"expand-home" optional:"true"`
+	}
+
+	var got Params
+	err := (CmdT[Params]{
+		Use: "test",
+		RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+			got = *p
+		},
+	}).RunArgsE([]string{"--home", "~/cfg", "--env", "${DATA_DIR}/./x/../y", "--abs", "rel/dir", "--plain", "a~b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Home != home+"/cfg" {
+		t.Errorf("expected %s/cfg, got %s", home, got.Home)
+	}
+	if got.Env != filepath.Clean("/srv/data/y") {
+		t.Errorf("unexpected expand-env result: %s", got.Env)
+	}
+	wd, _ := os.Getwd()
+	if got.Abs != filepath.Join(wd, "rel", "dir") {
+		t.Errorf("unexpected abs-path result: %s", got.Abs)
+	}
+	if got.Plain != "a~b" {
+		t.Errorf("expand-home should only touch a leading ~, got %s", got.Plain)
+	}
+}
+
+func TestTransforms_CustomAndProgrammatic(t *testing.T) {
+	RegisterTransform("test-strip-scheme", func(s string) (string, error) {
+		if strings.HasPrefix(s, "ftp://") {
+			return "", errors.New("ftp is not supported")
+		}
+		return strings.TrimPrefix(s, "https://"), nil
+	})
+
+	type Params struct {
+		Host string `optional:"true"`
+	}
+	run := func(args ...string) (string, error) {
+		var host string
+		err := (CmdT[Params]{
+			Use: "test",
+			InitFuncCtx: func(ctx *HookContext, p *Params, cmd *cobra.Command) error {
+				GetParamT(ctx, &p.Host).SetTransforms([]string{"test-strip-scheme", "lower"})
+				return nil
+			},
+			RunFunc: func(p *Params, cmd *cobra.Command, args []string) {
+				host = p.Host
+			},
+		}).RunArgsE(args)
+		return host, err
+	}
+
+	if host, err := run("--host", "https://Example.COM"); err != nil || host != "example.com" {
+		t.Errorf("expected example.com, got %q, %v", host, err)
+	}
+	_, err := run("--host", "ftp://x")
+	if err == nil || !IsUserInputError(err) || !strings.Contains(err.Error(), "transform test-strip-scheme: ftp is not supported") {
+		t.Errorf("expected transform error, got %v", err)
+	}
+}
+
+func TestTransforms_InvalidTags(t *testing.T) {
+	type Unknown struct {
+		Host string `transform:"trim,shout" optional:"true"`
+	}
+	_, err := (CmdT[Unknown]{Use: "test"}).ToCobraE()
+	if err == nil || !strings.Contains(err.Error(), `unknown transform "shout"`) {
+		t.Errorf("expected unknown transform error, got %v", err)
+	}
+
+	type NotString struct {
+		Port int `transform:"trim" optional:"true"`
+	}
+	_, err = (CmdT[NotString]{Use: "test"}).ToCobraE()
+	if err == nil || !strings.Contains(err.Error(), "transforms only apply to strings") {
+		t.Errorf("expected non-string error, got %v", err)
+	}
+}
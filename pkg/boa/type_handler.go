@@ -9,6 +9,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -30,6 +31,22 @@ type typeHandler struct {
 	// nil means no conversion needed.
 	convert func(name string, val any) (any, error)
 
+	// readEnv overrides the default single-variable env lookup for composite
+	// types that spread one value over several env vars (e.g. indexed
+	// SERVERS_0_HOST for slices of structs). given records the fields each
+	// element had a var for, and is nil when nothing was read. nil means the
+	// plain env var is parsed with parse.
+	readEnv func(name, env string, src envSource) (val any, given elemFields, err error)
+
+	// validateElems runs per-element checks on composite values after
+	// conversion. fillDefaults is true when the value didn't come from the
+	// CLI or env (e.g. config-file elements), whose fields haven't had the
+	// element struct's defaults applied yet. given holds the fields the
+	// value's source set (nil when unknown), so explicit zero values are
+	// neither replaced by defaults nor reported as missing. nil means no
+	// element checks.
+	validateElems func(name string, val any, fillDefaults bool, given elemFields) error
+
 	// baseType is the canonical Go type, used by normalizeType().
 	// e.g., for time.Duration this is reflect.TypeOf(time.Duration(0))
 	baseType reflect.Type
@@ -38,6 +55,13 @@ type typeHandler struct {
 // typeHandlerRegistry maps reflect.Type → handler for special types (time.Time, net.IP, etc.)
 // and reflect.Kind → handler for basic types (string, int, bool, etc.)
 var (
+	// handlersMu guards the type-keyed registries: exactTypeHandlers,
+	// sliceExactTypeHandlers, mapTypeHandlers, configTextTypes and
+	// enumTypes. They are written by RegisterType / RegisterEnum and
+	// lazily while commands are built, which may happen concurrently. The
+	// kind-keyed registries are only written by init.
+	handlersMu sync.RWMutex
+
 	exactTypeHandlers = map[reflect.Type]*typeHandler{}
 	kindHandlers      = map[reflect.Kind]*typeHandler{}
 
@@ -52,6 +76,8 @@ var (
 
 func init() {
 	registerBuiltinTypes()
+	registerStdlibTypes()
+	registerQuantityTypes()
 }
 
 // TypeDef defines how a custom type is parsed from and formatted to strings.
@@ -81,6 +107,8 @@ func RegisterType[T any](def TypeDef[T]) {
 		formatFn = func(v T) string { return fmt.Sprintf("%v", v) }
 	}
 
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
 	exactTypeHandlers[t] = &typeHandler{
 		baseType: t,
 		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
@@ -396,34 +424,7 @@ func registerBuiltinTypes() {
 		},
 	}
 
-	exactTypeHandlers[timeType] = &typeHandler{
-		baseType: timeType,
-		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
-			def := ""
-			if defaultVal != nil {
-				t := reflect.ValueOf(defaultVal).Elem().Interface().(time.Time)
-				def = t.Format(time.RFC3339)
-			}
-			return cmd.Flags().StringP(name, short, def, descr)
-		},
-		parse: func(name, strVal string) (any, error) {
-			v, err := parseTimeString(strVal)
-			if err != nil {
-				return nil, fmt.Errorf("invalid value for param %s: %s", name, err.Error())
-			}
-			return &v, nil
-		},
-		convert: func(name string, val any) (any, error) {
-			if strPtr, ok := val.(*string); ok {
-				v, err := parseTimeString(*strPtr)
-				if err != nil {
-					return nil, fmt.Errorf("invalid value for param '%s': %s", name, err.Error())
-				}
-				return &v, nil
-			}
-			return val, nil // already a *time.Time (e.g., from struct literal)
-		},
-	}
+	exactTypeHandlers[timeType] = timeHandler(timeFormat{})
 
 	exactTypeHandlers[ipType] = &typeHandler{
 		baseType: ipType,
@@ -521,42 +522,7 @@ func registerBuiltinTypes() {
 	}
 
 	// []time.Time — stored as []string, converted later
-	sliceExactTypeHandlers[timeType] = &typeHandler{
-		baseType: reflect.SliceOf(timeType),
-		bindFlag: func(cmd *cobra.Command, name, short, descr string, defaultVal any) any {
-			var def []string
-			if defaultVal != nil {
-				defVal := reflect.ValueOf(defaultVal).Elem()
-				if defVal.Kind() == reflect.Slice && defVal.Type().Elem() == timeType {
-					times := defVal.Interface().([]time.Time)
-					def = make([]string, len(times))
-					for i, t := range times {
-						def[i] = t.Format(time.RFC3339)
-					}
-				}
-			}
-			return cmd.Flags().StringSliceP(name, short, def, descr)
-		},
-		parse: func(name, strVal string) (any, error) {
-			return parseSliceWith(strVal, func(s string) (time.Time, error) {
-				return parseTimeString(s)
-			})
-		},
-		convert: func(name string, val any) (any, error) {
-			if strSlice, ok := val.(*[]string); ok && strSlice != nil {
-				times := make([]time.Time, len(*strSlice))
-				for i, s := range *strSlice {
-					t, err := parseTimeString(s)
-					if err != nil {
-						return nil, fmt.Errorf("invalid value for param '%s' at index %d: %s", name, i, err.Error())
-					}
-					times[i] = t
-				}
-				return &times, nil
-			}
-			return val, nil
-		},
-	}
+	sliceExactTypeHandlers[timeType] = timeSliceHandler(timeFormat{})
 
 	// []*url.URL — stored as []string, converted later
 	sliceExactTypeHandlers[urlPtrType] = &typeHandler{
@@ -724,8 +690,9 @@ func registerBuiltinTypes() {
 // lookupHandler finds the appropriate handler for a type.
 // Returns the handler and whether the type is a slice.
 func lookupHandler(t reflect.Type) (*typeHandler, bool) {
-	// Exact type match first (special types like time.Duration, time.Time, net.IP, *url.URL)
-	if h, ok := exactTypeHandlers[t]; ok {
+	// Exact type match first (special types like time.Duration, time.Time, net.IP, *url.URL,
+	// plus pflag.Value / encoding.TextUnmarshaler types registered on first use)
+	if h, ok := exactHandler(t); ok {
 		return h, false
 	}
 	// Kind-based match for basic types
@@ -738,13 +705,18 @@ func lookupHandler(t reflect.Type) (*typeHandler, bool) {
 // lookupSliceHandler finds the handler for a slice type based on its element type.
 func lookupSliceHandler(elemType reflect.Type) *typeHandler {
 	// Exact element type match first
-	if h, ok := sliceExactTypeHandlers[elemType]; ok {
+	ensureHandlerRegistered(elemType)
+	if h, ok := sliceExactHandler(elemType); ok {
 		return h
 	}
 	// Kind-based match
 	if h, ok := sliceKindHandlers[elemType.Kind()]; ok {
 		return h
 	}
+	// Plain structs: repeated key=value flags and indexed env vars
+	if isStructElemType(elemType) {
+		return structSliceHandler(elemType)
+	}
 	return nil
 }
 
@@ -802,10 +774,18 @@ func lookupMapHandler(t reflect.Type) *typeHandler {
 	}
 
 	// Check cache first
-	if h, ok := mapTypeHandlers[t]; ok {
+	handlersMu.RLock()
+	h, ok := mapTypeHandlers[t]
+	handlersMu.RUnlock()
+	if ok {
 		return h
 	}
 
+	// Plain struct values: per-entry key=value flags and env vars
+	if isStructElemType(t.Elem()) {
+		return structMapHandler(t)
+	}
+
 	valType := normalizeType(t.Elem())
 
 	// Find the scalar handler for the value type
@@ -817,7 +797,7 @@ func lookupMapHandler(t reflect.Type) *typeHandler {
 	parseFn := buildMapParse(t, valType, valHandler)
 
 	// Build a composed handler
-	h := &typeHandler{
+	h = &typeHandler{
 		baseType: t,
 		bindFlag: buildMapBindFlag(t, valType),
 		parse:    parseFn,
@@ -840,7 +820,12 @@ func lookupMapHandler(t reflect.Type) *typeHandler {
 		}
 	}
 
-	// Cache it
+	// Cache it, unless another command got there first
+	handlersMu.Lock()
+	defer handlersMu.Unlock()
+	if cached, ok := mapTypeHandlers[t]; ok {
+		return cached
+	}
 	mapTypeHandlers[t] = h
 	return h
 }
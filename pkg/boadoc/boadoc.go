@@ -0,0 +1,231 @@
+// Package boadoc generates reference documentation for boa command trees:
+// Markdown pages and man pages (roff), one per command.
+//
+// Unlike cobra/doc it documents what boa knows beyond flags: env var names,
+// config-file keys (including config-only fields), the config search paths
+// recorded by boaviper.AutoConfig, value precedence, and validation rules
+// such as required, alternatives, min/max and patterns.
+//
+// Usage:
+//
+//	cmd := boa.CmdT[Params]{Use: "myapp", ...}.ToCobra()
+//	if err := boadoc.GenMarkdownTree(cmd, "./docs/cli"); err != nil { ... }
+//	if err := boadoc.GenManTree(cmd, boadoc.ManHeader{Section: "1"}, "./man"); err != nil { ... }
+//
+// Output only depends on the command tree (ManHeader.Date is left out
+// unless set), so generated files can be checked in and golden-tested.
+package boadoc
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// precedence describes which source wins when a value is given in several.
+const precedence = "command-line flags, then environment variables, then the config file, then defaults"
+
+// GenMarkdownTree writes a Markdown page for cmd and each of its available
+// subcommands into dir, named after the command path ("app_sub.md").
+func GenMarkdownTree(cmd *cobra.Command, dir string) error {
+	return genTree(cmd, dir, markdownFileName, Markdown)
+}
+
+// GenManTree writes a man page for cmd and each of its available
+// subcommands into dir, named after the command path and section
+// ("app-sub.1"). Title is derived per command when header.Title is empty.
+func GenManTree(cmd *cobra.Command, header ManHeader, dir string) error {
+	header = header.withDefaults()
+	fileName := func(path string) string { return manFileName(path, header.Section) }
+	return genTree(cmd, dir, fileName, func(w io.Writer, c *cobra.Command) error {
+		return Man(w, c, header)
+	})
+}
+
+func genTree(cmd *cobra.Command, dir string, fileName func(path string) string, render func(io.Writer, *cobra.Command) error) error {
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() {
+			continue
+		}
+		if err := genTree(sub, dir, fileName, render); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(dir, fileName(cmd.CommandPath()))
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := render(f, cmd); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func plain(s string) string { return s }
+
+func markdownFileName(path string) string {
+	return strings.ReplaceAll(path, " ", "_") + ".md"
+}
+
+func manFileName(path, section string) string {
+	return strings.ReplaceAll(path, " ", "-") + "." + section
+}
+
+// page is what both renderers document for one command.
+type page struct {
+	boa.CommandInfo
+	parent      *boa.CommandInfo
+	options     []option
+	positionals []boa.ParamInfo
+	envVars     []envVar
+	configKeys  []boa.ParamInfo
+	configFlag  string
+	rules       []rule
+}
+
+type option struct {
+	short, long, typ, descr string
+}
+
+type envVar struct {
+	name, descr string
+}
+
+type rule struct {
+	subject string
+	param   boa.ParamInfo
+}
+
+func newPage(cmd *cobra.Command) page {
+	p := page{CommandInfo: boa.Describe(cmd)}
+	if cmd.HasParent() {
+		parent := cmd.Parent()
+		p.parent = &boa.CommandInfo{Name: parent.Name(), Path: parent.CommandPath(), Short: parent.Short}
+	}
+
+	documented := map[string]bool{}
+	for _, param := range p.Params {
+		switch {
+		case param.Positional:
+			p.positionals = append(p.positionals, param)
+		case !param.NoFlag:
+			documented[param.Name] = true
+			p.options = append(p.options, option{
+				short: param.Short,
+				long:  param.Name,
+				typ:   param.Type,
+				descr: describe(param),
+			})
+		}
+		if param.ConfigFile && !param.NoFlag {
+			p.configFlag = "--" + param.Name
+		}
+		if param.Env != "" {
+			descr := describe(param)
+			if param.NoFlag {
+				descr = strings.TrimSpace(descr + " (no flag)")
+			}
+			p.envVars = append(p.envVars, envVar{param.Env, descr})
+		}
+		if param.ConfigKey != "" {
+			p.configKeys = append(p.configKeys, param)
+		}
+		if len(validationChecks(param, plain)) > 0 {
+			p.rules = append(p.rules, rule{subject: paramLabel(param), param: param})
+		}
+	}
+	if p.Confirm && p.ConfirmEnv != "" {
+		p.envVars = append(p.envVars, envVar{p.ConfirmEnv, "skip the confirmation prompt"})
+	}
+
+	// Flags added outside boa's params, such as --yes or flags from a
+	// PostCreate hook
+	cmd.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if documented[f.Name] || f.Hidden || f.Name == "help" {
+			return
+		}
+		p.options = append(p.options, option{short: f.Shorthand, long: f.Name, typ: f.Value.Type(), descr: f.Usage})
+	})
+	return p
+}
+
+// description is the Long text, falling back to Short.
+func (p page) description() string {
+	if p.Long != "" {
+		return p.Long
+	}
+	return p.Short
+}
+
+// describe is the param's description followed by its default. Secret
+// defaults are not shown.
+func describe(param boa.ParamInfo) string {
+	descr := param.Description
+	if param.HasDefault && !param.Secret && param.Default != "" {
+		descr = strings.TrimSpace(fmt.Sprintf("%s (default %q)", descr, param.Default))
+	}
+	return descr
+}
+
+// paramLabel names a param the way a user would set it: its flag, or its
+// env var / config key when it has no flag.
+func paramLabel(param boa.ParamInfo) string {
+	switch {
+	case param.Positional:
+		return "<" + param.Name + ">"
+	case !param.NoFlag:
+		return "--" + param.Name
+	case param.Env != "":
+		return param.Env
+	case param.ConfigKey != "":
+		return param.ConfigKey
+	}
+	return param.Name
+}
+
+// validationChecks lists the rules a value of param must satisfy, in the
+// order boa checks them. code formats literal text such as patterns.
+func validationChecks(param boa.ParamInfo, code func(string) string) []string {
+	var checks []string
+	switch {
+	case param.Required && param.Conditional:
+		checks = append(checks, "required when enabled")
+	case param.Required:
+		checks = append(checks, "required")
+	}
+	if len(param.Transforms) > 0 {
+		checks = append(checks, "normalized with "+strings.Join(param.Transforms, ", ")+" before validation")
+	}
+	if len(param.Alternatives) > 0 {
+		if param.StrictAlts {
+			checks = append(checks, "must be one of: "+strings.Join(param.Alternatives, ", "))
+		} else {
+			checks = append(checks, "suggested values: "+strings.Join(param.Alternatives, ", "))
+		}
+	}
+	subject := ""
+	if param.LengthBounds {
+		subject = "length "
+	}
+	switch {
+	case param.Min != "" && param.Max != "":
+		checks = append(checks, fmt.Sprintf("%smust be between %s and %s", subject, param.Min, param.Max))
+	case param.Min != "":
+		checks = append(checks, fmt.Sprintf("%smust be at least %s", subject, param.Min))
+	case param.Max != "":
+		checks = append(checks, fmt.Sprintf("%smust be at most %s", subject, param.Max))
+	}
+	if param.Pattern != "" {
+		checks = append(checks, "must match "+code(param.Pattern))
+	}
+	return checks
+}
@@ -0,0 +1,146 @@
+package boadoc
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/GiGurra/boa/pkg/boatest"
+	"github.com/GiGurra/boa/pkg/boaviper"
+	"github.com/spf13/cobra"
+)
+
+type serveParams struct {
+	ConfigFile string   `configfile:"true" optional:"true" descr:"config file path"`
+	Host       string   `descr:"listen host" env:"MYAPP_HOST" default:"localhost" example:"0.0.0.0"`
+	Port       int      `descr:"listen port" short:"p" env:"MYAPP_PORT" min:"1" max:"65535" default:"8080"`
+	Mode       string   `descr:"run mode" alts:"dev,prod" default:"dev"`
+	Name       string   `descr:"instance name" pattern:"^[a-z][a-z0-9-]*$" max:"32" transform:"trim,lower" optional:"true"`
+	Token      string   `descr:"api token" env:"MYAPP_TOKEN" boa:"noflag" secret:"true" default:"s3cret"`
+	Limits     []string `descr:"rate limits" boa:"configonly" optional:"true"`
+	Dirs       []string `positional:"true" descr:"directories to serve" optional:"true"`
+}
+
+type dropParams struct {
+	Table string `descr:"table to drop" positional:"true"`
+}
+
+func docTree() *cobra.Command {
+	root := (boa.CmdT[boa.NoParams]{
+		Use:   "myapp",
+		Short: "An example app",
+		Long:  "myapp serves files.\n\n.Dots and -dashes survive roff.",
+		SubCmds: boa.SubCmds(
+			boa.CmdT[serveParams]{
+				Use:      "serve",
+				Short:    "Serve directories",
+				Aliases:  []string{"s"},
+				Example:  "  myapp serve --port 9000 ./public",
+				InitFunc: boaviper.AutoConfig[serveParams]("myapp"),
+				RunFunc:  func(*serveParams, *cobra.Command, []string) {},
+			},
+			boa.CmdT[dropParams]{
+				Use:     "drop",
+				Short:   "Drop a table",
+				Confirm: "Really drop?",
+				RunFunc: func(*dropParams, *cobra.Command, []string) {},
+			},
+		),
+	}).ToCobra()
+	root.AddCommand(&cobra.Command{Use: "internal", Hidden: true, Run: func(*cobra.Command, []string) {}})
+	return root
+}
+
+// checkGolden compares every file generated into dir with its golden file
+// testdata/<golden>/<name>.golden, through boatest.AssertGolden, so
+// BOATEST_UPDATE=1 rewrites them like any other golden file.
+func checkGolden(t *testing.T, dir, golden string) {
+	t.Helper()
+	goldenDir := filepath.Join("testdata", golden)
+	if os.Getenv("BOATEST_UPDATE") != "" {
+		// Drop golden files of pages that are no longer generated.
+		if err := os.RemoveAll(goldenDir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gotFiles, _ := filepath.Glob(filepath.Join(dir, "*"))
+	var gotNames []string
+	for _, f := range gotFiles {
+		name := filepath.Base(f)
+		gotNames = append(gotNames, name)
+		got, _ := os.ReadFile(f)
+		boatest.AssertGolden(t, filepath.Join(golden, name), string(got))
+	}
+
+	wantFiles, _ := filepath.Glob(filepath.Join(goldenDir, "*.golden"))
+	var wantNames []string
+	for _, f := range wantFiles {
+		wantNames = append(wantNames, strings.TrimSuffix(filepath.Base(f), ".golden"))
+	}
+	if !reflect.DeepEqual(gotNames, wantNames) {
+		t.Errorf("expected files %v, got %v", wantNames, gotNames)
+	}
+}
+
+func TestGenMarkdownTree(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenMarkdownTree(docTree(), dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkGolden(t, dir, "markdown")
+}
+
+func TestGenManTree(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenManTree(docTree(), ManHeader{Source: "myapp 1.0", Manual: "MyApp Manual"}, dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkGolden(t, dir, "man")
+}
+
+func TestOutputIsDeterministic(t *testing.T) {
+	render := func() string {
+		var buf bytes.Buffer
+		cmd, _, _ := docTree().Find([]string{"serve"})
+		if err := Markdown(&buf, cmd); err != nil {
+			t.Fatal(err)
+		}
+		if err := Man(&buf, cmd, ManHeader{}); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+	if first, second := render(), render(); first != second {
+		t.Errorf("expected identical output for identical trees")
+	}
+}
+
+func TestRoffLine(t *testing.T) {
+	tests := map[string]string{
+		"plain":      "plain",
+		".TH inject": `\&.TH inject`,
+		"'quoted":    `\&'quoted`,
+		`a\b -c`:     `a\eb \-c`,
+	}
+	for in, want := range tests {
+		if got := roffLine(in); got != want {
+			t.Errorf("roffLine(%q): expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestMan_DateOnlyWhenSet(t *testing.T) {
+	cmd := &cobra.Command{Use: "plain", Short: "plain cobra"}
+	var buf bytes.Buffer
+	if err := Man(&buf, cmd, ManHeader{Date: "Jan 2026"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), `.TH "PLAIN" "1" "Jan 2026" "" ""`+"\n") {
+		t.Errorf("unexpected title line:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,187 @@
+package boadoc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ManHeader fills the man page title line (.TH).
+type ManHeader struct {
+	// Title defaults to the command path in upper case, words joined by
+	// "-", e.g. "APP-SERVE".
+	Title string
+	// Section defaults to "1".
+	Section string
+	// Date, Source and Manual are left empty unless set; there is no
+	// default date so output stays reproducible.
+	Date   string
+	Source string
+	Manual string
+}
+
+func (h ManHeader) withDefaults() ManHeader {
+	if h.Section == "" {
+		h.Section = "1"
+	}
+	return h
+}
+
+// Man writes the man page (roff) for cmd.
+func Man(w io.Writer, cmd *cobra.Command, header ManHeader) error {
+	header = header.withDefaults()
+	p := newPage(cmd)
+	b := bufio.NewWriter(w)
+
+	title := header.Title
+	if title == "" {
+		title = strings.ToUpper(manName(p.Path))
+	}
+	fmt.Fprintf(b, ".TH %s %s %s %s %s\n", roffQuote(title), roffQuote(header.Section),
+		roffQuote(header.Date), roffQuote(header.Source), roffQuote(header.Manual))
+
+	b.WriteString(".SH NAME\n")
+	if p.Short != "" {
+		fmt.Fprintf(b, "%s \\- %s\n", roffEscape(manName(p.Path)), roffEscape(p.Short))
+	} else {
+		fmt.Fprintf(b, "%s\n", roffEscape(manName(p.Path)))
+	}
+
+	b.WriteString(".SH SYNOPSIS\n")
+	usage, rest, _ := strings.Cut(p.Use, p.Path)
+	fmt.Fprintf(b, "%s\\fB%s\\fP%s\n", roffEscape(usage), roffEscape(p.Path), roffEscape(rest))
+
+	if descr := strings.TrimSpace(p.description()); descr != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		writeRoffText(b, descr)
+	}
+	if len(p.Aliases) > 0 {
+		fmt.Fprintf(b, ".PP\nAliases: %s\n", roffEscape(strings.Join(p.Aliases, ", ")))
+	}
+
+	if len(p.positionals) > 0 {
+		b.WriteString(".SH ARGUMENTS\n")
+		for _, param := range p.positionals {
+			fmt.Fprintf(b, ".TP\n\\fI%s\\fP %s\n", roffEscape(param.Name), roffEscape(param.Type))
+			writeRoffText(b, describe(param))
+		}
+	}
+
+	if len(p.options) > 0 {
+		b.WriteString(".SH OPTIONS\n")
+		for _, opt := range p.options {
+			flag := "\\fB" + roffEscape("--"+opt.long) + "\\fP"
+			if opt.short != "" {
+				flag = "\\fB" + roffEscape("-"+opt.short) + "\\fP, " + flag
+			}
+			fmt.Fprintf(b, ".TP\n%s \\fI%s\\fP\n", flag, roffEscape(opt.typ))
+			writeRoffText(b, opt.descr)
+		}
+	}
+
+	if p.Example != "" {
+		b.WriteString(".SH EXAMPLES\n.PP\n.RS\n.nf\n")
+		writeRoffText(b, p.Example)
+		b.WriteString(".fi\n.RE\n")
+	}
+
+	if len(p.envVars) > 0 {
+		b.WriteString(".SH ENVIRONMENT\n")
+		for _, env := range p.envVars {
+			fmt.Fprintf(b, ".TP\n\\fB%s\\fP\n", roffEscape(env.name))
+			writeRoffText(b, env.descr)
+		}
+	}
+
+	if p.HasConfigFile || len(p.ConfigSearchPaths) > 0 {
+		b.WriteString(".SH FILES\n")
+		if p.configFlag != "" {
+			fmt.Fprintf(b, "A config file can be given with \\fB%s\\fP.", roffEscape(p.configFlag))
+			if len(p.ConfigSearchPaths) > 0 {
+				b.WriteString(" Otherwise the first of these that exists is used:")
+			}
+			b.WriteString("\n")
+		} else if len(p.ConfigSearchPaths) > 0 {
+			b.WriteString("The first of these config files that exists is used:\n")
+		}
+		for _, path := range p.ConfigSearchPaths {
+			fmt.Fprintf(b, ".TP\n\\fI%s\\fP\n", roffEscape(path))
+		}
+		if len(p.configKeys) > 0 {
+			b.WriteString(".PP\nConfig file keys:\n")
+			for _, param := range p.configKeys {
+				fmt.Fprintf(b, ".TP\n\\fB%s\\fP \\fI%s\\fP\n", roffEscape(param.ConfigKey), roffEscape(param.Type))
+				writeRoffText(b, describe(param))
+			}
+		}
+		fmt.Fprintf(b, ".PP\nValues are taken from %s.\n", roffEscape(precedence))
+	}
+
+	if len(p.rules) > 0 {
+		b.WriteString(".SH VALIDATION\n")
+		for _, r := range p.rules {
+			fmt.Fprintf(b, ".TP\n\\fB%s\\fP\n", roffEscape(r.subject))
+			writeRoffText(b, strings.Join(validationChecks(r.param, plain), "; "))
+		}
+	}
+
+	if p.parent != nil || len(p.Commands) > 0 {
+		var refs []string
+		if p.parent != nil {
+			refs = append(refs, manRef(p.parent.Path, header.Section))
+		}
+		for _, sub := range p.Commands {
+			refs = append(refs, manRef(sub.Path, header.Section))
+		}
+		fmt.Fprintf(b, ".SH SEE ALSO\n%s\n", strings.Join(refs, ", "))
+	}
+
+	return b.Flush()
+}
+
+// manName is the man page name of a command path: "app serve" -> "app-serve".
+func manName(path string) string {
+	return strings.ReplaceAll(path, " ", "-")
+}
+
+func manRef(path, section string) string {
+	return fmt.Sprintf("\\fB%s\\fP(%s)", roffEscape(manName(path)), section)
+}
+
+// writeRoffText writes text line by line, escaped, skipping empty text.
+func writeRoffText(b *bufio.Writer, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			b.WriteString(".sp\n")
+			continue
+		}
+		b.WriteString(roffLine(line))
+		b.WriteString("\n")
+	}
+}
+
+// roffLine escapes a line of text so it is not taken as a roff request.
+func roffLine(line string) string {
+	line = roffEscape(line)
+	if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+		line = `\&` + line
+	}
+	return line
+}
+
+// roffEscape escapes backslashes and hyphens, which roff would otherwise
+// interpret or render as typographic hyphens.
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	return strings.ReplaceAll(s, "-", `\-`)
+}
+
+func roffQuote(s string) string {
+	return `"` + strings.ReplaceAll(roffEscape(s), `"`, `\(dq`) + `"`
+}
@@ -0,0 +1,132 @@
+package boadoc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Markdown writes the Markdown reference page for cmd. Links to the parent
+// and subcommands use the file names of GenMarkdownTree.
+func Markdown(w io.Writer, cmd *cobra.Command) error {
+	p := newPage(cmd)
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "# %s\n\n", p.Path)
+	if p.Short != "" {
+		fmt.Fprintf(b, "%s\n\n", p.Short)
+	}
+
+	b.WriteString("## Synopsis\n\n")
+	if p.Long != "" {
+		fmt.Fprintf(b, "%s\n\n", strings.TrimSpace(p.Long))
+	}
+	fmt.Fprintf(b, "```\n%s\n```\n\n", p.Use)
+	if len(p.Aliases) > 0 {
+		fmt.Fprintf(b, "Aliases: %s\n\n", strings.Join(p.Aliases, ", "))
+	}
+
+	if len(p.positionals) > 0 {
+		b.WriteString("## Arguments\n\n| Argument | Type | Description |\n| --- | --- | --- |\n")
+		for _, param := range p.positionals {
+			fmt.Fprintf(b, "| `%s` | %s | %s |\n", paramLabel(param), mdCell(param.Type), mdCell(describe(param)))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(p.options) > 0 {
+		b.WriteString("## Options\n\n| Flag | Type | Description |\n| --- | --- | --- |\n")
+		for _, opt := range p.options {
+			flag := "`--" + opt.long + "`"
+			if opt.short != "" {
+				flag = "`-" + opt.short + "`, " + flag
+			}
+			fmt.Fprintf(b, "| %s | %s | %s |\n", flag, mdCell(opt.typ), mdCell(opt.descr))
+		}
+		b.WriteString("\n")
+	}
+
+	if p.Example != "" {
+		fmt.Fprintf(b, "## Examples\n\n```\n%s\n```\n\n", p.Example)
+	}
+
+	if len(p.envVars) > 0 {
+		b.WriteString("## Environment\n\n| Variable | Description |\n| --- | --- |\n")
+		for _, env := range p.envVars {
+			fmt.Fprintf(b, "| `%s` | %s |\n", env.name, mdCell(env.descr))
+		}
+		b.WriteString("\n")
+	}
+
+	if p.HasConfigFile || len(p.ConfigSearchPaths) > 0 {
+		b.WriteString("## Files\n\n")
+		if p.configFlag != "" {
+			fmt.Fprintf(b, "A config file can be given with `%s`.", p.configFlag)
+			if len(p.ConfigSearchPaths) > 0 {
+				b.WriteString(" Otherwise the first of these that exists is used:")
+			}
+			b.WriteString("\n\n")
+		} else if len(p.ConfigSearchPaths) > 0 {
+			b.WriteString("The first of these config files that exists is used:\n\n")
+		}
+		for _, path := range p.ConfigSearchPaths {
+			fmt.Fprintf(b, "- `%s`\n", path)
+		}
+		if len(p.ConfigSearchPaths) > 0 {
+			b.WriteString("\n")
+		}
+		if len(p.configKeys) > 0 {
+			b.WriteString("| Key | Type | Description |\n| --- | --- | --- |\n")
+			for _, param := range p.configKeys {
+				fmt.Fprintf(b, "| `%s` | %s | %s |\n", param.ConfigKey, mdCell(param.Type), mdCell(describe(param)))
+			}
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "Values are taken from %s.\n\n", precedence)
+	}
+
+	if len(p.rules) > 0 {
+		b.WriteString("## Validation\n\n")
+		for _, r := range p.rules {
+			fmt.Fprintf(b, "- `%s`: %s\n", r.subject, strings.Join(validationChecks(r.param, mdCode), "; "))
+		}
+		b.WriteString("\n")
+	}
+
+	if p.parent != nil || len(p.Commands) > 0 {
+		b.WriteString("## See Also\n\n")
+		if p.parent != nil {
+			fmt.Fprintf(b, "- [%s](%s)%s\n", p.parent.Path, markdownFileName(p.parent.Path), mdSuffix(p.parent.Short))
+		}
+		for _, sub := range p.Commands {
+			fmt.Fprintf(b, "- [%s](%s)%s\n", sub.Path, markdownFileName(sub.Path), mdSuffix(sub.Short))
+		}
+	}
+
+	return b.Flush()
+}
+
+// mdCell makes s safe inside a table cell.
+func mdCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return mdInline(s)
+}
+
+// mdInline keeps s on one line.
+func mdInline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func mdCode(s string) string {
+	return "`" + s + "`"
+}
+
+func mdSuffix(short string) string {
+	if short == "" {
+		return ""
+	}
+	return " - " + mdInline(short)
+}
@@ -0,0 +1,236 @@
+// Package boamcp serves a boa command tree as an MCP (Model Context
+// Protocol) server over stdio, so AI agents can call the CLI's commands as
+// tools without a wrapper.
+//
+// Every runnable leaf command becomes a tool. Its input schema is derived
+// from the params struct: JSON types, enums from strict `alts`, bounds,
+// patterns, descriptions and required params. A tool call is turned into
+// command-line arguments and run through the normal boa pipeline (defaults,
+// env, config files, validation, hooks), and the command's stdout, stderr
+// and error become the tool result. Commands should print to
+// cmd.OutOrStdout(); see Server.CaptureStdio for those using fmt.Println.
+//
+// Usage:
+//
+//	func newRoot() *cobra.Command {
+//	    return boa.CmdT[boa.NoParams]{
+//	        Use:     "myapp",
+//	        SubCmds: boa.SubCmds(deployCmd(), statusCmd()),
+//	    }.ToCobra()
+//	}
+//
+//	func main() {
+//	    if len(os.Args) > 1 && os.Args[1] == "mcp" {
+//	        if err := boamcp.ServeStdio(newRoot); err != nil {
+//	            log.Fatal(err)
+//	        }
+//	        return
+//	    }
+//	    newRoot().Execute()
+//	}
+//
+// The tree is built by a function rather than passed in because cobra
+// commands keep parsed flag values: each tool call runs on a fresh tree.
+package boamcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// ProtocolVersion is the newest MCP protocol version the server speaks.
+// Clients asking for an older supported version get that one instead.
+const ProtocolVersion = "2025-06-18"
+
+var supportedVersions = []string{"2024-11-05", "2025-03-26", ProtocolVersion}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// Server serves the commands built by NewRoot as MCP tools.
+type Server struct {
+	// NewRoot builds the command tree. It is called to list the tools and
+	// again for every tool call.
+	NewRoot func() *cobra.Command
+	// Name and Version identify the server to clients. Name defaults to
+	// the root command's name.
+	Name    string
+	Version string
+	// CaptureStdio also redirects the process-wide os.Stdin, os.Stdout and
+	// os.Stderr while a tool runs. It is a fallback for commands printing
+	// with fmt.Print instead of cmd.OutOrStdout(), whose output would
+	// otherwise go to the real stdout, and it affects every goroutine in
+	// the process.
+	CaptureStdio bool
+}
+
+// ServeStdio serves the commands built by newRoot on stdin/stdout until
+// stdin is closed.
+func ServeStdio(newRoot func() *cobra.Command) error {
+	return (&Server{NewRoot: newRoot}).Serve(context.Background(), os.Stdin, os.Stdout)
+}
+
+// Serve reads newline-delimited JSON-RPC messages from in and writes the
+// responses to out, until in is exhausted or ctx is done. Requests are
+// handled one at a time. It fails up front if two commands map to the
+// same tool name.
+//
+// A tool's result is what the command writes to cmd.OutOrStdout() and
+// cmd.ErrOrStderr(). Commands printing with fmt.Println write to the real
+// stdout instead, which corrupts out when that is stdout too; set
+// CaptureStdio for those.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	if s.NewRoot == nil {
+		return errors.New("boamcp: Server.NewRoot is nil")
+	}
+	if _, err := leaves(s.NewRoot()); err != nil {
+		return err
+	}
+	r := bufio.NewReader(in)
+	enc := json.NewEncoder(out)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handle(ctx, line); resp != nil {
+				if werr := enc.Encode(resp); werr != nil {
+					return fmt.Errorf("boamcp: failed to write response: %w", werr)
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("boamcp: failed to read request: %w", err)
+		}
+	}
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// handle answers one message, or returns nil for notifications.
+func (s *Server) handle(ctx context.Context, line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{codeParseError, "parse error: " + err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = json.RawMessage("null")
+		}
+		return &response{JSONRPC: "2.0", ID: id, Error: &rpcError{codeInvalidRequest, "invalid request"}}
+	}
+
+	result, err := s.dispatch(ctx, req)
+	if req.ID == nil {
+		// Notifications get no response, not even errors
+		return nil
+	}
+	resp := &response{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		var rerr *rpcError
+		if !errors.As(err, &rerr) {
+			rerr = &rpcError{codeInvalidParams, err.Error()}
+		}
+		resp.Error = rerr
+		return resp
+	}
+	resp.Result = result
+	return resp
+}
+
+func (s *Server) dispatch(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		tools, err := listTools(s.NewRoot())
+		if err != nil {
+			return nil, &rpcError{codeInternalError, err.Error()}
+		}
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string                     `json:"name"`
+			Arguments map[string]json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{codeInvalidParams, "invalid tools/call params: " + err.Error()}
+		}
+		return s.callTool(ctx, params.Name, params.Arguments)
+	}
+	if strings.HasPrefix(req.Method, "notifications/") {
+		return nil, nil
+	}
+	return nil, &rpcError{codeMethodNotFound, "method not found: " + req.Method}
+}
+
+func (s *Server) initialize(raw json.RawMessage) (any, error) {
+	var params struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, &rpcError{codeInvalidParams, "invalid initialize params: " + err.Error()}
+		}
+	}
+	version := ProtocolVersion
+	if slices.Contains(supportedVersions, params.ProtocolVersion) {
+		version = params.ProtocolVersion
+	}
+
+	name := s.Name
+	if name == "" {
+		name = s.NewRoot().Name()
+	}
+	serverInfo := map[string]string{"name": name}
+	if s.Version != "" {
+		serverInfo["version"] = s.Version
+	}
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities":    map[string]any{"tools": map[string]any{}},
+		"serverInfo":      serverInfo,
+	}, nil
+}
@@ -0,0 +1,327 @@
+package boamcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/spf13/cobra"
+)
+
+type greetParams struct {
+	Name    string            `descr:"who to greet" pattern:"^[A-Za-z]+$"`
+	Times   int               `descr:"repetitions" default:"1" min:"1" max:"3"`
+	Style   string            `descr:"greeting style" alts:"plain,loud" default:"plain"`
+	Tags    []string          `descr:"tags" optional:"true"`
+	Labels  map[string]string `descr:"labels" optional:"true"`
+	Wait    time.Duration     `descr:"delay" optional:"true"`
+	Token   string            `env:"GREET_TOKEN" boa:"noflag" optional:"true"`
+	Targets []string          `positional:"true" descr:"extra targets" optional:"true"`
+}
+
+type dropParams struct {
+	Table string `positional:"true" descr:"table to drop"`
+}
+
+func newTestRoot() *cobra.Command {
+	root := (boa.CmdT[boa.NoParams]{
+		Use:   "app",
+		Short: "test app",
+		SubCmds: boa.SubCmds(
+			boa.CmdT[greetParams]{
+				Use:   "greet",
+				Short: "Greet someone",
+				RunFunc: func(p *greetParams, cmd *cobra.Command, args []string) {
+					for i := 0; i < p.Times; i++ {
+						greeting := "hello " + p.Name
+						if p.Style == "loud" {
+							greeting = strings.ToUpper(greeting)
+						}
+						_, _ = fmt.Fprintln(cmd.OutOrStdout(), greeting)
+					}
+					cmd.Printf("tags=%q labels=%v targets=%q\n", p.Tags, p.Labels, p.Targets)
+				},
+			},
+			boa.CmdT[boa.NoParams]{
+				Use:   "db",
+				Short: "Database commands",
+				SubCmds: boa.SubCmds(boa.CmdT[dropParams]{
+					Use:     "drop",
+					Short:   "Drop a table",
+					Confirm: "Really?",
+					RunFuncE: func(p *dropParams, cmd *cobra.Command, args []string) error {
+						_, _ = fmt.Fprintln(cmd.OutOrStdout(), "dropped", p.Table)
+						return nil
+					},
+				}),
+			},
+		),
+	}).ToCobra()
+	root.AddCommand(&cobra.Command{Use: "secret", Hidden: true, Run: func(*cobra.Command, []string) {}})
+	return root
+}
+
+// client talks to a Server over in-memory pipes.
+type client struct {
+	t      *testing.T
+	in     *io.PipeWriter
+	out    *bufio.Reader
+	nextID int
+	done   chan error
+}
+
+func startServer(t *testing.T) *client {
+	t.Helper()
+	return startServerWith(t, &Server{NewRoot: newTestRoot, Version: "1.0.0"})
+}
+
+func startServerWith(t *testing.T, s *Server) *client {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{t: t, in: inW, out: bufio.NewReader(outR), done: make(chan error, 1)}
+	go func() {
+		err := s.Serve(context.Background(), inR, outW)
+		_ = outW.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() {
+		_ = inW.Close()
+		if err := <-c.done; err != nil {
+			t.Errorf("Serve returned %v", err)
+		}
+	})
+	return c
+}
+
+func (c *client) send(line string) {
+	c.t.Helper()
+	if _, err := io.WriteString(c.in, line+"\n"); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) receive() map[string]any {
+	c.t.Helper()
+	line, err := c.out.ReadBytes('\n')
+	if err != nil {
+		c.t.Fatalf("failed to read response: %v", err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(line, &msg); err != nil {
+		c.t.Fatalf("invalid response %q: %v", line, err)
+	}
+	return msg
+}
+
+// call sends a request and returns its result, failing on errors.
+func (c *client) call(method string, params any) map[string]any {
+	c.t.Helper()
+	c.nextID++
+	raw, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params})
+	c.send(string(raw))
+	msg := c.receive()
+	if msg["id"] != float64(c.nextID) {
+		c.t.Fatalf("expected response to id %d, got %v", c.nextID, msg)
+	}
+	if msg["error"] != nil {
+		c.t.Fatalf("%s failed: %v", method, msg["error"])
+	}
+	return msg["result"].(map[string]any)
+}
+
+func (c *client) callTool(name string, args map[string]any) (text string, isError bool) {
+	c.t.Helper()
+	result := c.call("tools/call", map[string]any{"name": name, "arguments": args})
+	var parts []string
+	for _, block := range result["content"].([]any) {
+		parts = append(parts, block.(map[string]any)["text"].(string))
+	}
+	return strings.Join(parts, ""), result["isError"].(bool)
+}
+
+func TestInitialize(t *testing.T) {
+	c := startServer(t)
+	result := c.call("initialize", map[string]any{"protocolVersion": "2025-03-26", "capabilities": map[string]any{}})
+	if result["protocolVersion"] != "2025-03-26" {
+		t.Errorf("expected the client's supported version, got %v", result["protocolVersion"])
+	}
+	info := result["serverInfo"].(map[string]any)
+	if info["name"] != "app" || info["version"] != "1.0.0" {
+		t.Errorf("unexpected server info %v", info)
+	}
+	if _, ok := result["capabilities"].(map[string]any)["tools"]; !ok {
+		t.Errorf("expected tools capability, got %v", result["capabilities"])
+	}
+
+	result = c.call("initialize", map[string]any{"protocolVersion": "1999-01-01"})
+	if result["protocolVersion"] != ProtocolVersion {
+		t.Errorf("expected latest version for unknown client version, got %v", result["protocolVersion"])
+	}
+
+	// Notifications get no response: the next response is the ping's
+	c.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	c.call("ping", nil)
+}
+
+func TestListTools(t *testing.T) {
+	c := startServer(t)
+	tools := c.call("tools/list", nil)["tools"].([]any)
+
+	byName := map[string]map[string]any{}
+	var names []string
+	for _, tl := range tools {
+		m := tl.(map[string]any)
+		names = append(names, m["name"].(string))
+		byName[m["name"].(string)] = m
+	}
+	if !reflect.DeepEqual(names, []string{"db_drop", "greet"}) {
+		t.Fatalf("expected leaf tools only, got %v", names)
+	}
+
+	greet := byName["greet"]
+	if greet["description"] != "Greet someone" {
+		t.Errorf("unexpected description %v", greet["description"])
+	}
+	schema := greet["inputSchema"].(map[string]any)
+	if !reflect.DeepEqual(schema["required"], []any{"name"}) {
+		t.Errorf("expected only name to be required, got %v", schema["required"])
+	}
+	props := schema["properties"].(map[string]any)
+	if _, ok := props["token"]; ok {
+		t.Error("noflag params can't be passed and should not be listed")
+	}
+	want := map[string]map[string]any{
+		"name":    {"type": "string", "description": "who to greet", "pattern": "^[A-Za-z]+$"},
+		"times":   {"type": "integer", "description": "repetitions", "default": float64(1), "minimum": float64(1), "maximum": float64(3)},
+		"style":   {"type": "string", "description": "greeting style", "default": "plain", "enum": []any{"plain", "loud"}},
+		"tags":    {"type": "array", "description": "tags", "items": map[string]any{"type": "string"}},
+		"labels":  {"type": "object", "description": "labels", "additionalProperties": map[string]any{"type": "string"}},
+		"wait":    {"type": "string", "description": "delay"},
+		"targets": {"type": "array", "description": "extra targets", "items": map[string]any{"type": "string"}},
+	}
+	for name, w := range want {
+		if !reflect.DeepEqual(props[name], any(w)) {
+			t.Errorf("property %s: expected %v, got %v", name, w, props[name])
+		}
+	}
+
+	drop := byName["db_drop"]["inputSchema"].(map[string]any)
+	if _, ok := drop["properties"].(map[string]any)["yes"]; !ok {
+		t.Errorf("expected a yes property for a confirm command, got %v", drop)
+	}
+}
+
+func TestCallTool(t *testing.T) {
+	c := startServer(t)
+	text, isError := c.callTool("greet", map[string]any{
+		"name":    "bob",
+		"times":   2,
+		"style":   "loud",
+		"tags":    []string{"a,b", "c"},
+		"labels":  map[string]string{"env": "prod", "team": "x"},
+		"targets": []string{"-dash", "x"},
+	})
+	if isError {
+		t.Fatalf("unexpected error result: %s", text)
+	}
+	want := "HELLO BOB\nHELLO BOB\n" + `tags=["a,b" "c"] labels=map[env:prod team:x] targets=["-dash" "x"]` + "\n"
+	if text != want {
+		t.Errorf("expected output\n%q\ngot\n%q", want, text)
+	}
+
+	// Each call starts from a fresh tree and the defaults
+	text, isError = c.callTool("greet", map[string]any{"name": "amy"})
+	if isError || !strings.HasPrefix(text, "hello amy\ntags=[]") {
+		t.Errorf("expected defaults on a fresh call, got %q (error %v)", text, isError)
+	}
+}
+
+func TestCallTool_Errors(t *testing.T) {
+	c := startServer(t)
+
+	text, isError := c.callTool("greet", map[string]any{"name": "bob", "times": 7})
+	if !isError || !strings.Contains(text, "times") {
+		t.Errorf("expected a validation error for times, got %q", text)
+	}
+	text, isError = c.callTool("greet", map[string]any{})
+	if !isError || !strings.Contains(text, "name") {
+		t.Errorf("expected a missing name error, got %q", text)
+	}
+	text, isError = c.callTool("greet", map[string]any{"name": "bob", "nope": 1})
+	if !isError || !strings.Contains(text, `unknown argument "nope"`) {
+		t.Errorf("expected an unknown argument error, got %q", text)
+	}
+
+	text, isError = c.callTool("db_drop", map[string]any{"table": "users"})
+	if !isError || strings.Contains(text, "dropped") {
+		t.Errorf("expected the confirm gate to refuse without yes, got %q", text)
+	}
+	text, isError = c.callTool("db_drop", map[string]any{"table": "users", "yes": true})
+	if isError || text != "dropped users\n" {
+		t.Errorf("expected drop with yes to run, got %q (error %v)", text, isError)
+	}
+}
+
+func TestProtocolErrors(t *testing.T) {
+	c := startServer(t)
+
+	c.send(`{not json`)
+	if msg := c.receive(); msg["error"].(map[string]any)["code"] != float64(codeParseError) {
+		t.Errorf("expected parse error, got %v", msg)
+	}
+	c.send(`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`)
+	if msg := c.receive(); msg["error"].(map[string]any)["code"] != float64(codeMethodNotFound) {
+		t.Errorf("expected method not found, got %v", msg)
+	}
+	c.send(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"secret"}}`)
+	if msg := c.receive(); msg["error"].(map[string]any)["code"] != float64(codeInvalidParams) {
+		t.Errorf("expected unknown tool error for a hidden command, got %v", msg)
+	}
+}
+
+func TestCallTool_CaptureStdio(t *testing.T) {
+	newRoot := func() *cobra.Command {
+		return boa.CmdT[boa.NoParams]{
+			Use: "app",
+			SubCmds: boa.SubCmds(boa.CmdT[boa.NoParams]{
+				Use: "hello",
+				RunFunc: func(_ *boa.NoParams, cmd *cobra.Command, _ []string) {
+					fmt.Println("from fmt")
+					_, _ = fmt.Fprintln(cmd.OutOrStdout(), "from cmd")
+				},
+			}),
+		}.ToCobra()
+	}
+	c := startServerWith(t, &Server{NewRoot: newRoot, CaptureStdio: true})
+	text, isError := c.callTool("hello", nil)
+	if isError || text != "from fmt\nfrom cmd\n" {
+		t.Errorf("expected fmt and cmd output captured, got %q (error %v)", text, isError)
+	}
+}
+
+func TestServe_DuplicateToolNames(t *testing.T) {
+	newRoot := func() *cobra.Command {
+		return boa.CmdT[boa.NoParams]{
+			Use: "app",
+			SubCmds: boa.SubCmds(
+				boa.CmdT[boa.NoParams]{Use: "db_drop", RunFunc: func(*boa.NoParams, *cobra.Command, []string) {}},
+				boa.CmdT[boa.NoParams]{
+					Use:     "db",
+					SubCmds: boa.SubCmds(boa.CmdT[boa.NoParams]{Use: "drop", RunFunc: func(*boa.NoParams, *cobra.Command, []string) {}}),
+				},
+			),
+		}.ToCobra()
+	}
+	err := (&Server{NewRoot: newRoot}).Serve(context.Background(), strings.NewReader(""), io.Discard)
+	if err == nil || !strings.Contains(err.Error(), `tool name "db_drop"`) {
+		t.Errorf("expected a duplicate tool name error, got %v", err)
+	}
+}
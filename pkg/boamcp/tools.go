@@ -0,0 +1,600 @@
+package boamcp
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema"`
+}
+
+// leaf is a runnable command without available subcommands: one tool.
+type leaf struct {
+	name  string
+	words []string // subcommand path below the root
+	info  boa.CommandInfo
+}
+
+// leaves returns the tools of root. Tool names join the command path with
+// "_", which command names may contain too, so two commands mapping to the
+// same name (e.g. "db_drop" and "db drop") are an error.
+func leaves(root *cobra.Command) ([]leaf, error) {
+	var out []leaf
+	var walk func(info boa.CommandInfo, words []string)
+	walk = func(info boa.CommandInfo, words []string) {
+		if len(info.Commands) == 0 {
+			if info.Runnable {
+				name := info.Name
+				if len(words) > 0 {
+					name = strings.Join(words, "_")
+				}
+				out = append(out, leaf{name: name, words: words, info: info})
+			}
+			return
+		}
+		for _, sub := range info.Commands {
+			walk(sub, append(slices.Clone(words), sub.Name))
+		}
+	}
+	walk(boa.Describe(root), nil)
+
+	seen := map[string]leaf{}
+	for _, l := range out {
+		if prev, ok := seen[l.name]; ok {
+			return nil, fmt.Errorf("boamcp: commands %q and %q both map to tool name %q",
+				strings.Join(prev.words, " "), strings.Join(l.words, " "), l.name)
+		}
+		seen[l.name] = l
+	}
+	return out, nil
+}
+
+func listTools(root *cobra.Command) ([]tool, error) {
+	ls, err := leaves(root)
+	if err != nil {
+		return nil, err
+	}
+	tools := []tool{}
+	for _, l := range ls {
+		tools = append(tools, tool{
+			Name:        l.name,
+			Description: toolDescription(l.info),
+			InputSchema: inputSchema(l.info),
+		})
+	}
+	return tools, nil
+}
+
+func toolDescription(info boa.CommandInfo) string {
+	return strings.TrimSpace(strings.Join([]string{info.Short, info.Long}, "\n\n"))
+}
+
+// inputSchema is the JSON schema of a tool's arguments: one property per
+// param that can be given on the command line, plus "yes" for commands
+// that ask for confirmation.
+func inputSchema(info boa.CommandInfo) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	for _, p := range info.Params {
+		if p.NoFlag && !p.Positional && !p.RestArgs {
+			continue
+		}
+		props[p.Name] = paramSchema(p)
+		if p.Required && !p.Conditional {
+			required = append(required, p.Name)
+		}
+	}
+	if info.Confirm {
+		props[info.ConfirmFlag] = map[string]any{
+			"type":        "boolean",
+			"description": "Confirm the action. Without it the command refuses to run.",
+		}
+	}
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	pflagValueType      = reflect.TypeOf((*pflag.Value)(nil)).Elem()
+)
+
+// isText reports types given as a single string on the command line,
+// whatever their Go kind.
+func isText(t reflect.Type) bool {
+	if t == durationType || t == timeType {
+		return true
+	}
+	pt := reflect.PointerTo(t)
+	return pt.Implements(textUnmarshalerType) || pt.Implements(pflagValueType)
+}
+
+// isComposite reports types boa parses from JSON on the command line.
+func isComposite(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isText(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.Struct, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// typeSchema is the JSON schema of values of t.
+func typeSchema(t reflect.Type) map[string]any {
+	if t == nil {
+		return map[string]any{"type": "string"}
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if isText(t) {
+		return map[string]any{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return map[string]any{"type": "object"}
+	}
+	return map[string]any{"type": "string"}
+}
+
+func paramSchema(p boa.ParamInfo) map[string]any {
+	schema := typeSchema(p.ValueType)
+	typ := schema["type"]
+
+	// Scalar rules (enum, pattern) apply to the items of slices
+	scalar := schema
+	if items, ok := schema["items"].(map[string]any); ok {
+		scalar = items
+	}
+
+	var notes []string
+	if len(p.Alternatives) > 0 {
+		var alts []any
+		for _, alt := range p.Alternatives {
+			alts = append(alts, typedValue(scalar["type"], alt))
+		}
+		if p.StrictAlts {
+			scalar["enum"] = alts
+		} else {
+			scalar["examples"] = alts
+		}
+	}
+	if p.Pattern != "" && scalar["type"] == "string" {
+		scalar["pattern"] = p.Pattern
+	}
+
+	if p.Min != "" || p.Max != "" {
+		if !setBounds(schema, typ, p) {
+			if p.Min != "" {
+				notes = append(notes, "min "+p.Min)
+			}
+			if p.Max != "" {
+				notes = append(notes, "max "+p.Max)
+			}
+		}
+	}
+
+	descr := p.Description
+	if p.RestArgs {
+		descr = strings.TrimSpace(descr + " (passed after --)")
+	}
+	if len(notes) > 0 {
+		descr = strings.TrimSpace(fmt.Sprintf("%s (%s)", descr, strings.Join(notes, ", ")))
+	}
+	if descr != "" {
+		schema["description"] = descr
+	}
+	if p.HasDefault && !p.Secret && !isComposite(p.ValueType) {
+		schema["default"] = typedValue(typ, p.Default)
+	}
+	if p.Example != "" && typ != "array" {
+		schema["examples"] = append(anySlice(schema["examples"]), typedValue(typ, p.Example))
+	}
+	return schema
+}
+
+func anySlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+// setBounds maps min/max to JSON schema keywords, reporting false when the
+// bounds can't be expressed there (e.g. durations or times).
+func setBounds(schema map[string]any, typ any, p boa.ParamInfo) bool {
+	var minKey, maxKey string
+	switch {
+	case p.LengthBounds && typ == "string":
+		minKey, maxKey = "minLength", "maxLength"
+	case p.LengthBounds && typ == "array":
+		minKey, maxKey = "minItems", "maxItems"
+	case p.LengthBounds && typ == "object":
+		minKey, maxKey = "minProperties", "maxProperties"
+	case !p.LengthBounds && (typ == "integer" || typ == "number"):
+		minKey, maxKey = "minimum", "maximum"
+	default:
+		return false
+	}
+	bounds := map[string]float64{}
+	for key, text := range map[string]string{minKey: p.Min, maxKey: p.Max} {
+		if text == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return false
+		}
+		bounds[key] = v
+	}
+	for key, v := range bounds {
+		schema[key] = v
+	}
+	return true
+}
+
+// typedValue converts text from a struct tag to the JSON type of the
+// schema, keeping it as a string when it doesn't parse.
+func typedValue(typ any, text string) any {
+	switch typ {
+	case "boolean":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b
+		}
+	case "integer":
+		if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f
+		}
+	}
+	return text
+}
+
+// buildArgs turns tool arguments into command-line arguments for l:
+// subcommand words, flags, then positionals and rest args.
+func buildArgs(l leaf, in map[string]json.RawMessage) ([]string, error) {
+	byName := map[string]boa.ParamInfo{}
+	var positionals []boa.ParamInfo
+	var rest *boa.ParamInfo
+	for _, p := range l.info.Params {
+		switch {
+		case p.RestArgs:
+			rest = &p
+		case p.Positional:
+			positionals = append(positionals, p)
+		case p.NoFlag:
+			continue
+		}
+		byName[p.Name] = p
+	}
+
+	names := make([]string, 0, len(in))
+	for name := range in {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := slices.Clone(l.words)
+	for _, name := range names {
+		raw := in[name]
+		if l.info.Confirm && name == l.info.ConfirmFlag {
+			var yes bool
+			if err := json.Unmarshal(raw, &yes); err != nil {
+				return nil, fmt.Errorf("argument %q: expected a boolean", name)
+			}
+			if yes {
+				args = append(args, "--"+name)
+			}
+			continue
+		}
+		p, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown argument %q", name)
+		}
+		if p.Positional || p.RestArgs {
+			continue
+		}
+		values, err := flagValues(p, raw)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", name, err)
+		}
+		for _, v := range values {
+			args = append(args, "--"+name+"="+v)
+		}
+	}
+
+	// Without rest args, "--" keeps positionals starting with "-" from
+	// being taken as flags
+	if rest == nil {
+		args = append(args, "--")
+	}
+	var missing string
+	for _, p := range positionals {
+		raw, ok := in[p.Name]
+		if !ok || string(raw) == "null" {
+			if missing == "" {
+				missing = p.Name
+			}
+			continue
+		}
+		if missing != "" {
+			return nil, fmt.Errorf("argument %q requires %q, which comes before it", p.Name, missing)
+		}
+		values, err := argValues(raw)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", p.Name, err)
+		}
+		args = append(args, values...)
+	}
+	if rest != nil {
+		values, err := argValues(in[rest.Name])
+		if err != nil {
+			return nil, fmt.Errorf("argument %q: %w", rest.Name, err)
+		}
+		if len(values) > 0 {
+			args = append(append(args, "--"), values...)
+		}
+	}
+	return args, nil
+}
+
+// flagValues renders a tool argument as flag values; slices become one
+// flag per element.
+func flagValues(p boa.ParamInfo, raw json.RawMessage) ([]string, error) {
+	v, err := decode(raw)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	t := p.ValueType
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || isText(t) {
+		return []string{text(v)}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		items, ok := v.([]any)
+		if !ok || isComposite(t.Elem()) {
+			return []string{text(v)}, nil
+		}
+		var out []string
+		for _, item := range items {
+			s := text(item)
+			if t.Elem().Kind() == reflect.String {
+				s = csvQuote(s)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	case reflect.Map:
+		entries, ok := v.(map[string]any)
+		if !ok || isComposite(t.Elem()) {
+			return []string{text(v)}, nil
+		}
+		keys := make([]string, 0, len(entries))
+		for k := range entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var pairs []string
+		for _, k := range keys {
+			pairs = append(pairs, k+"="+text(entries[k]))
+		}
+		return []string{strings.Join(pairs, ",")}, nil
+	}
+	return []string{text(v)}, nil
+}
+
+// argValues renders a positional tool argument; arrays become one arg per
+// element.
+func argValues(raw json.RawMessage) ([]string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	v, err := decode(raw)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return []string{text(v)}, nil
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		out = append(out, text(item))
+	}
+	return out, nil
+}
+
+func decode(raw json.RawMessage) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return v, nil
+}
+
+// text renders a decoded JSON value the way it is written on the command
+// line: scalars as is, objects and arrays as JSON.
+func text(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// csvQuote quotes a string slice element for pflag's CSV parsing.
+func csvQuote(s string) string {
+	if !strings.ContainsAny(s, ",\"\n\r") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func (s *Server) callTool(ctx context.Context, name string, in map[string]json.RawMessage) (any, error) {
+	root := s.NewRoot()
+	ls, err := leaves(root)
+	if err != nil {
+		return nil, &rpcError{codeInternalError, err.Error()}
+	}
+	for _, l := range ls {
+		if l.name != name {
+			continue
+		}
+		args, err := buildArgs(l, in)
+		if err != nil {
+			return toolResult("", "", err), nil
+		}
+		stdout, stderr, err := runCaptured(ctx, root, args, s.CaptureStdio)
+		return toolResult(stdout, stderr, err), nil
+	}
+	return nil, &rpcError{codeInvalidParams, "unknown tool: " + name}
+}
+
+func toolResult(stdout, stderr string, err error) map[string]any {
+	content := []map[string]string{}
+	if stdout != "" {
+		content = append(content, map[string]string{"type": "text", "text": stdout})
+	}
+	if stderr != "" {
+		content = append(content, map[string]string{"type": "text", "text": "stderr:\n" + stderr})
+	}
+	if err != nil {
+		content = append(content, map[string]string{"type": "text", "text": "error: " + err.Error()})
+	}
+	return map[string]any{"content": content, "isError": err != nil}
+}
+
+// stdioMu serializes the swaps of the process-wide os.Std* done for
+// Server.CaptureStdio.
+var stdioMu sync.Mutex
+
+// runCaptured executes root with args, capturing what the command writes
+// to its output and error streams. Its input is empty, so it never
+// prompts. With captureStdio, os.Stdin, os.Stdout and os.Stderr are also
+// swapped while the command runs.
+func runCaptured(ctx context.Context, root *cobra.Command, args []string, captureStdio bool) (stdout, stderr string, err error) {
+	if captureStdio {
+		return runCapturedStdio(ctx, root, args)
+	}
+	var outBuf, errBuf bytes.Buffer
+	root.SetIn(strings.NewReader(""))
+	root.SetOut(&outBuf)
+	root.SetErr(&errBuf)
+	err = execute(ctx, root, args)
+	return outBuf.String(), errBuf.String(), err
+}
+
+// runCapturedStdio is runCaptured for commands printing with fmt.Print:
+// the command's streams and the process-wide os.Std* point at pipes while
+// it runs.
+func runCapturedStdio(ctx context.Context, root *cobra.Command, args []string) (stdout, stderr string, err error) {
+	stdioMu.Lock()
+	defer stdioMu.Unlock()
+
+	outR, outW, err := os.Pipe()
+	if err != nil {
+		return "", "", err
+	}
+	errR, errW, err := os.Pipe()
+	if err != nil {
+		_ = outR.Close()
+		_ = outW.Close()
+		return "", "", err
+	}
+	inR, inW, err := os.Pipe()
+	if err != nil {
+		_ = outR.Close()
+		_ = outW.Close()
+		_ = errR.Close()
+		_ = errW.Close()
+		return "", "", err
+	}
+	_ = inW.Close()
+
+	var outBuf, errBuf bytes.Buffer
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); _, _ = io.Copy(&outBuf, outR) }()
+	go func() { defer wg.Done(); _, _ = io.Copy(&errBuf, errR) }()
+
+	origIn, origOut, origErr := os.Stdin, os.Stdout, os.Stderr
+	os.Stdin, os.Stdout, os.Stderr = inR, outW, errW
+	root.SetIn(inR)
+	root.SetOut(outW)
+	root.SetErr(errW)
+	err = execute(ctx, root, args)
+	os.Stdin, os.Stdout, os.Stderr = origIn, origOut, origErr
+
+	_ = outW.Close()
+	_ = errW.Close()
+	wg.Wait()
+	_ = outR.Close()
+	_ = errR.Close()
+	_ = inR.Close()
+	return outBuf.String(), errBuf.String(), err
+}
+
+// execute runs root with args, turning a panic into an error.
+func execute(ctx context.Context, root *cobra.Command, args []string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("command panicked: %v", r)
+		}
+	}()
+	root.SetArgs(args)
+	return root.ExecuteContext(ctx)
+}
@@ -0,0 +1,177 @@
+// Package boatest runs boa commands for tests: args, env vars, files and
+// stdin are given per run, and stdout, stderr, the exit code and the
+// resolved params are captured, without going through os.Args, the process
+// environment, the real filesystem, os.Stdin or os.Exit.
+//
+// Usage:
+//
+//	res := boatest.Run(boa.CmdT[Params]{Use: "app", RunFunc: run},
+//	    boatest.Args("--name", "x"),
+//	    boatest.Env(map[string]string{"APP_PORT": "8080"}),
+//	    boatest.Files(map[string]string{"/etc/app.json": `{"debug": true}`}),
+//	    boatest.Stdin("y\n"),
+//	)
+//	if res.ExitCode != 0 { t.Fatal(res.Stderr) }
+//	if res.Params.Port != 8080 { ... }
+//
+// Output written through cmd.OutOrStdout() / cmd.ErrOrStderr() is
+// captured; fmt.Println and friends write to the real stdout.
+package boatest
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/spf13/cobra"
+)
+
+// Option configures a Run.
+type Option func(*config)
+
+type config struct {
+	args  []string
+	env   map[string]string
+	files fstest.MapFS
+	stdin io.Reader
+}
+
+// Args sets the command line arguments, without the program name.
+func Args(args ...string) Option {
+	return func(c *config) {
+		c.args = append(c.args, args...)
+	}
+}
+
+// Env sets environment variables. Only these are visible to the command,
+// its hooks (through HookContext.LookupEnv or boa.LookupEnv) and helpers
+// like boaviper.AutoConfig.
+func Env(env map[string]string) Option {
+	return func(c *config) {
+		for k, v := range env {
+			c.env[k] = v
+		}
+	}
+}
+
+// Files adds files by path to contents. Only these are visible to the
+// command, as its CmdT.FS: config files, argfiles and boaviper.AutoConfig
+// all read from them. Absolute and relative paths both work, e.g.
+// "/etc/app.json" or "config.yaml".
+func Files(files map[string]string) Option {
+	return func(c *config) {
+		for name, data := range files {
+			name = strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+			c.files[name] = &fstest.MapFile{Data: []byte(data), Mode: 0o644}
+		}
+	}
+}
+
+// Stdin sets what prompts and confirmations read, as if typed into a
+// terminal. Without it, stdin is empty and isn't interactive.
+func Stdin(s string) Option {
+	return func(c *config) {
+		// Buffered once, so that prompts and the confirmation, which wrap
+		// stdin in a bufio.Reader each, share it instead of the first one
+		// reading it all.
+		c.stdin = boa.InteractiveInput(bufio.NewReader(strings.NewReader(s)))
+	}
+}
+
+// Result is the outcome of a Run.
+type Result[P any] struct {
+	Stdout string
+	Stderr string
+	// ExitCode is what boa's Run would exit with, see boa.ExitCodeFor.
+	ExitCode int
+	// Err is the error, if the command failed. Errors from RunFuncE are
+	// returned here rather than panicking like Run does.
+	Err error
+	// Params are the root command's params as resolved for the run.
+	Params *P
+	// Cmd is the command that was executed, e.g. a subcommand.
+	Cmd *cobra.Command
+}
+
+// Run builds cmd and executes it with the given options, printing errors
+// the way boa.Execute does. cmd is used as given apart from RawArgs,
+// LookupEnv, Environ and FS, which Args, Env and Files replace; Params is
+// allocated if nil.
+func Run[P any](cmd boa.CmdT[P], opts ...Option) *Result[P] {
+	c := &config{args: []string{}, env: map[string]string{}, files: fstest.MapFS{}, stdin: strings.NewReader("")}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if cmd.Params == nil {
+		cmd.Params = new(P)
+	}
+	cmd.RawArgs = c.args
+	cmd.LookupEnv = func(key string) (string, bool) {
+		v, ok := c.env[key]
+		return v, ok
+	}
+	cmd.Environ = func() []string {
+		environ := make([]string, 0, len(c.env))
+		for k, v := range c.env {
+			environ = append(environ, k+"="+v)
+		}
+		slices.Sort(environ)
+		return environ
+	}
+	cmd.FS = c.files
+
+	res := &Result[P]{Params: cmd.Params}
+	cobraCmd, err := cmd.ToCobraE()
+	if err != nil {
+		res.Err = err
+		res.ExitCode = boa.ExitCode(err)
+		return res
+	}
+	var stdout, stderr bytes.Buffer
+	cobraCmd.SetIn(c.stdin)
+	cobraCmd.SetOut(&stdout)
+	cobraCmd.SetErr(&stderr)
+
+	res.Cmd, res.Err = boa.ExecuteC(cobraCmd)
+	res.Stdout = stdout.String()
+	res.Stderr = stderr.String()
+	res.ExitCode = boa.ExitCodeFor(res.Cmd, res.Err)
+	return res
+}
+
+// Help returns the help output of cmd, or of the subcommand at path
+// (e.g. "db", "migrate").
+func Help[P any](cmd boa.CmdT[P], path ...string) string {
+	return Run(cmd, Args(append(path, "--help")...)).Stdout
+}
+
+// AssertGolden compares got with the golden file testdata/<name>.golden,
+// failing t with both if they differ. Run the tests with BOATEST_UPDATE=1
+// to write got to the file instead.
+func AssertGolden(t testing.TB, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if os.Getenv("BOATEST_UPDATE") != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with BOATEST_UPDATE=1 to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s doesn't match %s\n--- got ---\n%s\n--- want ---\n%s", name, path, got, want)
+	}
+}
@@ -0,0 +1,185 @@
+package boatest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/GiGurra/boa/pkg/boa"
+	"github.com/spf13/cobra"
+)
+
+type serveParams struct {
+	Config string `configfile:"true" optional:"true"`
+	Host   string `env:"APP_HOST" default:"localhost"`
+	Port   int    `env:"APP_PORT" min:"1" default:"80"`
+	Debug  bool   `optional:"true"`
+}
+
+func serveCmd() boa.CmdT[serveParams] {
+	return boa.CmdT[serveParams]{
+		Use:   "serve",
+		Short: "Serve things",
+		RunFunc: func(p *serveParams, cmd *cobra.Command, _ []string) {
+			fmt.Fprintf(cmd.OutOrStdout(), "serving on %s:%d\n", p.Host, p.Port)
+		},
+	}
+}
+
+func TestRun_Args(t *testing.T) {
+	t.Parallel()
+	res := Run(serveCmd(), Args("--host", "example.com", "--port", "9000", "--debug"))
+	if res.Err != nil || res.ExitCode != 0 {
+		t.Fatalf("unexpected failure %v (exit %d): %s", res.Err, res.ExitCode, res.Stderr)
+	}
+	if res.Stdout != "serving on example.com:9000\n" || res.Stderr != "" {
+		t.Errorf("unexpected output %q / %q", res.Stdout, res.Stderr)
+	}
+	if !res.Params.Debug || res.Params.Host != "example.com" {
+		t.Errorf("unexpected params %+v", res.Params)
+	}
+}
+
+func TestRun_Sources(t *testing.T) {
+	t.Parallel()
+	res := Run(serveCmd(),
+		Args("--config", "/etc/app.json", "--port", "9000"),
+		Env(map[string]string{"APP_HOST": "example.com", "APP_PORT": "1234"}),
+		Files(map[string]string{"/etc/app.json": `{"Debug": true}`}),
+	)
+	if res.Err != nil || res.ExitCode != 0 {
+		t.Fatalf("unexpected failure %v (exit %d): %s", res.Err, res.ExitCode, res.Stderr)
+	}
+	if res.Stdout != "serving on example.com:9000\n" || !res.Params.Debug {
+		t.Errorf("unexpected output %q, params %+v", res.Stdout, res.Params)
+	}
+}
+
+func TestRun_Isolated(t *testing.T) {
+	t.Setenv("APP_HOST", "from-process")
+	res := Run(serveCmd(), Env(map[string]string{"APP_PORT": "1234"}))
+	if res.Params.Host != "localhost" || res.Params.Port != 1234 {
+		t.Errorf("expected only Env to be visible, got %+v", res.Params)
+	}
+
+	res = Run(serveCmd(), Args("--config", "boatest.go"))
+	if res.ExitCode != boa.ExitConfig || !strings.Contains(res.Stderr, "Error: configfile config: failed to read config file boatest.go") {
+		t.Errorf("expected real files to be invisible, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+}
+
+func TestRun_ArgFiles(t *testing.T) {
+	t.Parallel()
+	cmd := serveCmd()
+	cmd.ArgFiles = true
+	res := Run(cmd, Args("@/ci/serve.args"), Files(map[string]string{"/ci/serve.args": "--port 7000"}))
+	if res.Stdout != "serving on localhost:7000\n" {
+		t.Errorf("expected the argfile to be read from Files, got %q (%s)", res.Stdout, res.Stderr)
+	}
+}
+
+func TestRun_Parallel(t *testing.T) {
+	for i := range 20 {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			t.Parallel()
+			port := 1000 + i
+			res := Run(serveCmd(),
+				Args("--config", "app.json"),
+				Env(map[string]string{"APP_PORT": fmt.Sprint(port)}),
+				Files(map[string]string{"app.json": fmt.Sprintf(`{"Host": "h%d"}`, i)}),
+			)
+			if want := fmt.Sprintf("serving on h%d:%d\n", i, port); res.Stdout != want {
+				t.Errorf("expected %q, got %q (%s)", want, res.Stdout, res.Stderr)
+			}
+		})
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	t.Parallel()
+	res := Run(serveCmd(), Env(map[string]string{"APP_PORT": "0"}))
+	if res.ExitCode != boa.ExitValidation || !strings.HasSuffix(res.Stderr, "Error: invalid value for param 'port': value 0 is below min 1\n") {
+		t.Errorf("expected a validation error, got exit %d: %s", res.ExitCode, res.Stderr)
+	}
+
+	errGone := errors.New("gone")
+	sub := Run(boa.CmdT[boa.NoParams]{
+		Use: "app",
+		SubCmds: boa.SubCmds(boa.CmdT[boa.NoParams]{
+			Use:      "get",
+			RunFuncE: func(*boa.NoParams, *cobra.Command, []string) error { return errGone },
+		}),
+		ExitCodeMapper: func(err error) int {
+			if errors.Is(err, errGone) {
+				return boa.ExitNotFound
+			}
+			return 0
+		},
+	}, Args("get"))
+	if !errors.Is(sub.Err, errGone) || sub.ExitCode != boa.ExitNotFound || sub.Cmd.Name() != "get" {
+		t.Errorf("expected the run error, got %v (exit %d)", sub.Err, sub.ExitCode)
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	t.Parallel()
+	type params struct {
+		Name string `prompt:"Your name"`
+	}
+	cmd := boa.CmdT[params]{
+		Use:     "greet",
+		Confirm: "Really greet?",
+		RunFunc: func(p *params, cmd *cobra.Command, _ []string) {
+			fmt.Fprintf(cmd.OutOrStdout(), "hello %s\n", p.Name)
+		},
+	}
+
+	res := Run(cmd, Stdin("bob\ny\n"))
+	if res.Err != nil || res.Stdout != "hello bob\n" {
+		t.Errorf("expected answers from stdin, got %v: %q", res.Err, res.Stdout)
+	}
+
+	res = Run(cmd, Args("--name", "bob"))
+	if !errors.Is(res.Err, boa.ErrNotConfirmed) || res.ExitCode != boa.ExitFailure {
+		t.Errorf("expected stdin not to be interactive, got %v (exit %d)", res.Err, res.ExitCode)
+	}
+
+	res = Run(cmd, Args("--name", "bob", "--yes"))
+	if res.Err != nil || res.Stdout != "hello bob\n" {
+		t.Errorf("expected --yes to skip the confirmation, got %v: %q", res.Err, res.Stdout)
+	}
+
+	res = Run(cmd, Args("--name", "bob"), Env(map[string]string{"YES": "true"}))
+	if res.Err != nil {
+		t.Errorf("expected the confirm env var to be read from Env, got %v", res.Err)
+	}
+}
+
+func TestRun_Reload(t *testing.T) {
+	t.Parallel()
+	var reloaded *serveParams
+	res := Run(boa.CmdT[serveParams]{
+		Use: "serve",
+		RunFuncCtxE: func(ctx *boa.HookContext, _ *serveParams, _ *cobra.Command, _ []string) error {
+			var err error
+			reloaded, err = boa.Reload[serveParams](ctx)
+			return err
+		},
+	}, Args("--config", "app.json"), Env(map[string]string{"APP_PORT": "7"}), Files(map[string]string{"app.json": `{"Host": "h"}`}))
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if reloaded.Port != 7 || reloaded.Host != "h" {
+		t.Errorf("expected the reload to see the run's args, env and files, got %+v", reloaded)
+	}
+}
+
+func TestHelp_Golden(t *testing.T) {
+	t.Parallel()
+	root := boa.CmdT[boa.NoParams]{
+		Use:     "app",
+		SubCmds: boa.SubCmds(serveCmd()),
+	}
+	AssertGolden(t, "serve_help", Help(root, "serve"))
+}
@@ -21,17 +21,25 @@
 //   - $HOME/.config/myapp/config.json
 //   - /etc/myapp/config.json
 //
+// HOME is read with boa.LookupEnv, so it follows CmdT.LookupEnv, and the
+// files are searched for in the command's CmdT.FS, if it has one.
+//
 // The first file found is used. All registered config format extensions
-// (via boa.RegisterConfigFormat) are tried at each path.
+// (via boa.RegisterConfigFormat) are tried at each path. The candidates are
+// recorded on the command so generated docs (see the boadoc package) can
+// list them, with the home directory left as "~" so the docs don't depend
+// on the machine they're generated on.
 package boaviper
 
 import (
+	"io/fs"
 	"log/slog"
-	"os"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strings"
 
+	"github.com/GiGurra/boa/internal/fspath"
 	"github.com/GiGurra/boa/pkg/boa"
 	"github.com/spf13/cobra"
 )
@@ -42,14 +50,48 @@ import (
 //  2. XDG config: $HOME/.config/<appName>/config.<ext>
 //  3. System config: /etc/<appName>/config.<ext>
 func DefaultSearchPaths(appName string) []string {
-	paths := []string{"."}
-	if home, err := os.UserHomeDir(); err == nil {
-		paths = append(paths, filepath.Join(home, ".config", appName))
+	var paths []string
+	for _, dir := range defaultSearchDirs(appName) {
+		if expanded, ok := expandHome(nil, dir); ok {
+			paths = append(paths, expanded)
+		}
 	}
-	paths = append(paths, filepath.Join("/etc", appName))
 	return paths
 }
 
+// defaultSearchDirs is DefaultSearchPaths with the home directory left as
+// "~", expanded only when searching.
+func defaultSearchDirs(appName string) []string {
+	return []string{".", filepath.Join("~", ".config", appName), filepath.Join("/etc", appName)}
+}
+
+// expandHome replaces a leading "~" in p with the home directory of cmd
+// (see homeDir). ok is false if p needs it but it is unknown.
+func expandHome(cmd *cobra.Command, p string) (expanded string, ok bool) {
+	if p != "~" && !strings.HasPrefix(p, "~/") && !strings.HasPrefix(p, "~"+string(filepath.Separator)) {
+		return p, true
+	}
+	home := homeDir(cmd)
+	if home == "" {
+		return "", false
+	}
+	return filepath.Join(home, p[1:]), true
+}
+
+// homeDir is os.UserHomeDir reading the env var the way cmd does, "" if
+// unknown.
+func homeDir(cmd *cobra.Command) string {
+	homeVar := "HOME"
+	switch runtime.GOOS {
+	case "windows":
+		homeVar = "USERPROFILE"
+	case "plan9":
+		homeVar = "home"
+	}
+	home, _ := boa.LookupEnv(cmd, homeVar)
+	return home
+}
+
 // FindConfig searches for a config file in the given paths (or default paths
 // if none provided). Tries all registered config format extensions at each path.
 //
@@ -57,15 +99,49 @@ func DefaultSearchPaths(appName string) []string {
 //   - <path>/<appName>.<ext> (in the root search path, e.g., ./myapp.json)
 //   - <path>/config.<ext> (in named directories, e.g., ~/.config/myapp/config.json)
 //
+// A leading "~" in a search path is the home directory.
+//
 // Returns the path to the first file found, or empty string if none found.
 func FindConfig(appName string, searchPaths ...string) string {
+	return findConfig(nil, appName, nil, searchPaths...)
+}
+
+// FindConfigFS is FindConfig searching fsys, e.g. an embed.FS or a
+// fstest.MapFS. The candidate paths are the same, resolved against the root
+// of fsys the way boa.LoadConfigFileFS resolves them, and the one found is
+// returned as such, ready for a configfile field of a command with the same
+// CmdT.FS. A nil fsys is the OS filesystem.
+func FindConfigFS(fsys fs.FS, appName string, searchPaths ...string) string {
+	return findConfig(fsys, appName, nil, searchPaths...)
+}
+
+func findConfig(fsys fs.FS, appName string, cmd *cobra.Command, searchPaths ...string) string {
+	candidates := candidatePaths(appName, searchPaths...)
+	for _, candidate := range candidates {
+		path, ok := expandHome(cmd, candidate)
+		if !ok {
+			continue
+		}
+		if _, err := fspath.Stat(fsys, path); err == nil {
+			slog.Debug("boaviper: found config file", "path", path)
+			return path
+		}
+	}
+
+	slog.Debug("boaviper: no config file found", "app", appName, "tried", candidates)
+	return ""
+}
+
+// candidatePaths lists the files FindConfig tries, in priority order, with
+// a leading "~" not yet expanded.
+func candidatePaths(appName string, searchPaths ...string) []string {
 	if len(searchPaths) == 0 {
-		searchPaths = DefaultSearchPaths(appName)
+		searchPaths = defaultSearchDirs(appName)
 	}
 
 	exts := boa.ConfigFormatExtensions()
 
-	var tried []string
+	var candidates []string
 
 	for i, dir := range searchPaths {
 		// First search path: try <appName>.<ext> (e.g., ./myapp.json)
@@ -78,29 +154,18 @@ func FindConfig(appName string, searchPaths ...string) string {
 		}
 
 		for _, ext := range exts {
-			candidate := filepath.Join(dir, baseName+ext)
-			if _, err := os.Stat(candidate); err == nil {
-				slog.Debug("boaviper: found config file", "path", candidate)
-				return candidate
-			}
-			tried = append(tried, candidate)
+			candidates = append(candidates, filepath.Join(dir, baseName+ext))
 		}
 
 		// Also try <appName>.<ext> in all directories (not just first)
 		if i > 0 {
 			for _, ext := range exts {
-				candidate := filepath.Join(dir, appName+ext)
-				if _, err := os.Stat(candidate); err == nil {
-					slog.Debug("boaviper: found config file", "path", candidate)
-					return candidate
-				}
-				tried = append(tried, candidate)
+				candidates = append(candidates, filepath.Join(dir, appName+ext))
 			}
 		}
 	}
 
-	slog.Debug("boaviper: no config file found", "app", appName, "tried", tried)
-	return ""
+	return candidates
 }
 
 // AutoConfig returns an InitFunc that automatically discovers and sets the
@@ -108,6 +173,7 @@ func FindConfig(appName string, searchPaths ...string) string {
 //
 // It finds the first field tagged with configfile:"true" in the params struct,
 // and if its value is empty (not set by CLI), sets it to the discovered path.
+// With CmdT.FS set, it searches that instead of the OS filesystem.
 //
 // Usage:
 //
@@ -126,6 +192,12 @@ func FindConfig(appName string, searchPaths ...string) string {
 //	}
 func AutoConfig[T any](appName string, searchPaths ...string) func(params *T, cmd *cobra.Command) error {
 	return func(params *T, cmd *cobra.Command) error {
+		// Record where we look, for generated docs (see boa.Describe)
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[boa.ConfigSearchPathsAnnotation] = strings.Join(candidatePaths(appName, searchPaths...), "\n")
+
 		// Find the configfile field and set it if empty
 		v := reflect.ValueOf(params).Elem()
 		t := v.Type()
@@ -135,7 +207,7 @@ func AutoConfig[T any](appName string, searchPaths ...string) func(params *T, cm
 			if tag := field.Tag.Get("configfile"); tag == "true" {
 				fieldVal := v.Field(i)
 				if fieldVal.Kind() == reflect.String && fieldVal.String() == "" {
-					path := FindConfig(appName, searchPaths...)
+					path := findConfig(boa.FileSystem(cmd), appName, cmd, searchPaths...)
 					if path != "" {
 						fieldVal.SetString(path)
 					}
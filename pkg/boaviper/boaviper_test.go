@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/GiGurra/boa/pkg/boa"
 	"github.com/spf13/cobra"
@@ -163,6 +167,42 @@ func TestAutoConfig_NoConfigFileFound(t *testing.T) {
 	}
 }
 
+func TestAutoConfig_RecordsSearchPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	other := filepath.Join(tmpDir, "etc")
+
+	type Params struct {
+		ConfigFile string `configfile:"true" optional:"true"`
+	}
+
+	cmd := (boa.CmdT[Params]{
+		Use:      "myapp",
+		InitFunc: AutoConfig[Params]("myapp", tmpDir, other),
+		RunFunc:  func(p *Params, cmd *cobra.Command, args []string) {},
+	}).ToCobra()
+
+	want := []string{
+		filepath.Join(tmpDir, "myapp.json"),
+		filepath.Join(other, "config.json"),
+		filepath.Join(other, "myapp.json"),
+	}
+	got := boa.Describe(cmd).ConfigSearchPaths
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected search paths %v, got %v", want, got)
+	}
+
+	t.Setenv("HOME", tmpDir)
+	cmd = (boa.CmdT[Params]{
+		Use:      "myapp",
+		InitFunc: AutoConfig[Params]("myapp"),
+		RunFunc:  func(p *Params, cmd *cobra.Command, args []string) {},
+	}).ToCobra()
+	got = boa.Describe(cmd).ConfigSearchPaths
+	if home := filepath.Join("~", ".config", "myapp", "config.json"); !slices.Contains(got, home) || strings.Contains(strings.Join(got, "\n"), tmpDir) {
+		t.Errorf("expected the home directory recorded as ~, got %v", got)
+	}
+}
+
 func TestSetEnvPrefix(t *testing.T) {
 	type Params struct {
 		Port int `descr:"port" default:"8080"`
@@ -190,3 +230,64 @@ func TestSetEnvPrefix(t *testing.T) {
 		t.Errorf("expected port=3000 (from MYAPP_PORT env), got %d", gotPort)
 	}
 }
+
+func TestAutoConfig_LookupEnv(t *testing.T) {
+	type Params struct {
+		ConfigFile string `configfile:"true" optional:"true"`
+		Port       int    `optional:"true"`
+	}
+	home := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(home, ".config", "myapp"), 0755)
+	_ = os.WriteFile(filepath.Join(home, ".config", "myapp", "config.json"), []byte(`{"Port": 7070}`), 0644)
+	env := map[string]string{"HOME": home}
+
+	params := &Params{}
+	err := boa.CmdT[Params]{
+		Use:    "myapp",
+		Params: params,
+		LookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+		InitFunc: AutoConfig[Params]("myapp"),
+		RunFunc:  func(*Params, *cobra.Command, []string) {},
+	}.RunArgsE([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Port != 7070 {
+		t.Errorf("expected the config under the LookupEnv HOME to load, got port %d", params.Port)
+	}
+}
+
+func TestFindConfigFS(t *testing.T) {
+	fsys := fstest.MapFS{"etc/myapp/config.json": {Data: []byte(`{}`)}}
+	if got := FindConfigFS(fsys, "myapp"); got != filepath.Join("/etc", "myapp", "config.json") {
+		t.Errorf("expected /etc/myapp/config.json, got %q", got)
+	}
+	if got := FindConfigFS(fsys, "other"); got != "" {
+		t.Errorf("expected no config, got %q", got)
+	}
+}
+
+func TestAutoConfig_FS(t *testing.T) {
+	type Params struct {
+		ConfigFile string `configfile:"true" optional:"true"`
+		Port       int    `optional:"true"`
+	}
+	params := &Params{}
+	err := boa.CmdT[Params]{
+		Use:       "myapp",
+		Params:    params,
+		LookupEnv: func(key string) (string, bool) { return "", false },
+		FS:        fstest.MapFS{"myapp.json": {Data: []byte(`{"Port": 6060}`)}},
+		InitFunc:  AutoConfig[Params]("myapp"),
+		RunFunc:   func(*Params, *cobra.Command, []string) {},
+	}.RunArgsE([]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Port != 6060 || params.ConfigFile != "myapp.json" {
+		t.Errorf("expected the config to be found and loaded from the FS, got %+v", params)
+	}
+}